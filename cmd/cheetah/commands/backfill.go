@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/backfill"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// dateLayout is the layout of the date flags of the backfill command, in addition to RFC3339.
+const dateLayout = "2006-01-02"
+
+var (
+	flagBackfillHostId    string
+	flagBackfillStartDate string
+	flagBackfillEndDate   string
+	flagBackfillOutput    string
+)
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Upload the files of a host within a time range once",
+	Long: "Upload the files of a host within a time range once. Only markers whose Hedera consensus timestamp " +
+		"(parsed from the file name) or modification time is within [start-date, end-date) are uploaded, and objects " +
+		"are stored under the host ID in every storage. A summary of the uploaded, skipped and failed files is printed " +
+		"when all pipelines have scanned the root directories once.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ok, err := runBackfill(cmd, cmd.OutOrStdout())
+		if err != nil {
+			logx.As().Error().Err(err).Msg("Failed to backfill")
+			os.Exit(1)
+		}
+
+		if !ok {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	backfillCmd.Flags().StringVarP(&flagBackfillHostId, "host-id", "", "", "host or node ID the objects are stored under (e.g. 0.0.3)")
+	backfillCmd.Flags().StringVarP(&flagBackfillStartDate, "start-date", "", "", "upload markers at or after the date (YYYY-MM-DD in UTC or RFC3339)")
+	backfillCmd.Flags().StringVarP(&flagBackfillEndDate, "end-date", "", "", "upload markers before the date (YYYY-MM-DD in UTC or RFC3339)")
+	backfillCmd.Flags().StringVarP(&flagBackfillOutput, "output", "o", outputTable, "output format (table or json)")
+
+	_ = backfillCmd.MarkFlagRequired("host-id")
+	_ = backfillCmd.MarkFlagRequired("start-date")
+	_ = backfillCmd.MarkFlagRequired("end-date")
+}
+
+// runBackfill runs the enabled pipelines once for the markers within the time range and prints the summary.
+// It returns false if any marker failed to be uploaded or a pipeline stopped because of an error.
+func runBackfill(cmd *cobra.Command, w io.Writer) (bool, error) {
+	if flagBackfillOutput != outputTable && flagBackfillOutput != outputJSON {
+		return false, fmt.Errorf("invalid output format '%s', expected %s or %s", flagBackfillOutput, outputTable, outputJSON)
+	}
+
+	if err := backfill.ValidateHostId(flagBackfillHostId); err != nil {
+		return false, err
+	}
+
+	start, err := parseDateFlag(flagBackfillStartDate)
+	if err != nil {
+		return false, fmt.Errorf("invalid --start-date: %w", err)
+	}
+
+	end, err := parseDateFlag(flagBackfillEndDate)
+	if err != nil {
+		return false, fmt.Errorf("invalid --end-date: %w", err)
+	}
+
+	window := backfill.Window{Start: start, End: end}
+	if err := window.Validate(); err != nil {
+		return false, err
+	}
+
+	logx.As().Info().
+		Str("host_id", flagBackfillHostId).
+		Time("start", start).
+		Time("end", end).
+		Msg("Starting backfill")
+
+	summary := backfill.NewSummary(flagBackfillHostId, window)
+	pipelineErr := runPipelines(cmd.Context(), pipelineOptions{
+		poll:      false,
+		recorders: []core.Recorder{summary},
+		filters:   []scanner.Filter{summary.Filter},
+		configure: func(pc *config.PipelineConfig) *config.PipelineConfig {
+			return config.Namespace(pc, flagBackfillHostId)
+		},
+	})
+	if pipelineErr != nil {
+		logx.As().Error().Err(pipelineErr).Msg("Backfill stopped because of error")
+	}
+
+	report := summary.Report()
+	logx.As().Info().
+		Str("host_id", report.HostId).
+		Int("markers", report.Markers).
+		Int("uploaded", len(report.Uploaded)).
+		Int("skipped", len(report.Skipped)).
+		Int("failed", len(report.Failed)).
+		Msg("Backfill completed")
+
+	if flagBackfillOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return false, err
+		}
+	} else if err := printBackfillTable(w, report); err != nil {
+		return false, err
+	}
+
+	return pipelineErr == nil && len(report.Failed) == 0, nil
+}
+
+// parseDateFlag parses a date flag either as a date in UTC (YYYY-MM-DD) or as RFC3339.
+func parseDateFlag(value string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339 time, got '%s'", value)
+	}
+
+	return t, nil
+}
+
+// printBackfillTable prints a summary row followed by one row per skipped or failed file.
+// Uploaded files are only counted; they can be inspected with the history command if the journal is enabled.
+func printBackfillTable(w io.Writer, report backfill.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "HOST_ID\tSTART\tEND\tMARKERS\tUPLOADED\tSKIPPED\tFAILED")
+	_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n", report.HostId, report.Start.Format(time.RFC3339),
+		report.End.Format(time.RFC3339), report.Markers, len(report.Uploaded), len(report.Skipped), len(report.Failed))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Skipped) == 0 && len(report.Failed) == 0 {
+		return nil
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\nSTATUS\tSTORAGE\tMARKER\tSRC\tREASON")
+	for _, f := range report.Skipped {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", "skipped", orDash(f.Storage), f.Marker, orDash(f.Src), f.Reason)
+	}
+	for _, f := range report.Failed {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", "failed", orDash(f.Storage), f.Marker, orDash(f.Src), oneLine(f.Error))
+	}
+
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
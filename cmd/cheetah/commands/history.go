@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats of the history command.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	flagHistoryPath    string
+	flagHistoryTraceId string
+	flagHistoryStorage string
+	flagHistorySince   string
+	flagHistoryUntil   string
+	flagHistoryOutcome string
+	flagHistoryOutput  string
+	flagHistoryLimit   int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the upload history from the upload journal",
+	Long: "Show the upload history from the upload journal. Entries can be filtered by marker or file path glob, " +
+		"trace ID, storage, time range and outcome.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHistory(cmd.OutOrStdout()); err != nil {
+			logx.As().Error().Err(err).Msg("Failed to show upload history")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&flagHistoryPath, "path", "p", "", "glob pattern of the marker or file path (e.g. '*.rcd.gz')")
+	historyCmd.Flags().StringVarP(&flagHistoryTraceId, "trace-id", "t", "", "trace ID of the marker")
+	historyCmd.Flags().StringVarP(&flagHistoryStorage, "storage", "s", "", "storage type (e.g. S3, GCS, LocalDir)")
+	historyCmd.Flags().StringVarP(&flagHistorySince, "since", "", "", "show entries at or after the time (RFC3339 or a duration such as 24h)")
+	historyCmd.Flags().StringVarP(&flagHistoryUntil, "until", "", "", "show entries before the time (RFC3339 or a duration such as 1h)")
+	historyCmd.Flags().StringVarP(&flagHistoryOutcome, "outcome", "", "", "outcome of the marker (success or failed)")
+	historyCmd.Flags().StringVarP(&flagHistoryOutput, "output", "o", outputTable, "output format (table or json)")
+	historyCmd.Flags().IntVarP(&flagHistoryLimit, "limit", "n", 0, "maximum number of latest entries to show (0 shows all)")
+}
+
+func runHistory(w io.Writer) error {
+	journalConf := config.Get().Journal
+	if journalConf == nil || journalConf.Directory == "" {
+		return fmt.Errorf("upload journal directory is not configured")
+	}
+
+	if flagHistoryOutput != outputTable && flagHistoryOutput != outputJSON {
+		return fmt.Errorf("invalid output format '%s', expected %s or %s", flagHistoryOutput, outputTable, outputJSON)
+	}
+
+	now := time.Now()
+	since, err := parseTimeFlag(flagHistorySince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	until, err := parseTimeFlag(flagHistoryUntil, now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := journal.Query(journalConf.Directory, journalConf.FileName, journal.Filter{
+		Path:    flagHistoryPath,
+		TraceId: flagHistoryTraceId,
+		Storage: flagHistoryStorage,
+		Since:   since,
+		Until:   until,
+		Outcome: flagHistoryOutcome,
+		Limit:   flagHistoryLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if flagHistoryOutput == outputJSON {
+		return printHistoryJSON(w, entries)
+	}
+
+	return printHistoryTable(w, entries)
+}
+
+// parseTimeFlag parses a time flag either as RFC3339 or as a duration relative to now (e.g. 24h means 24 hours ago).
+// An empty value returns the zero time.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 time or duration, got '%s'", value)
+	}
+
+	return now.Add(-d), nil
+}
+
+func printHistoryJSON(w io.Writer, entries []journal.Entry) error {
+	if entries == nil {
+		entries = []journal.Entry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// printHistoryTable prints one row per uploaded file and one row per storage failure.
+// Entries without any upload or failure are printed as a single row for the marker.
+func printHistoryTable(w io.Writer, entries []journal.Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIMESTAMP\tPIPELINE\tTRACE_ID\tOUTCOME\tSTORAGE\tSRC\tDEST\tCHECKSUM\tSIZE\tERROR")
+
+	for _, e := range entries {
+		ts := e.Timestamp.Format(time.RFC3339)
+		for _, u := range e.Uploads {
+			checksum := u.Checksum
+			if u.ChecksumType != "" {
+				checksum = fmt.Sprintf("%s:%s", u.ChecksumType, u.Checksum)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				ts, e.Pipeline, e.TraceId, e.Outcome, u.Storage, u.Src, u.Dest, checksum, u.Size, "")
+		}
+
+		for _, f := range e.Failures {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				ts, e.Pipeline, e.TraceId, e.Outcome, f.Storage, e.Marker, "-", "-", "-", oneLine(f.Error))
+		}
+
+		if len(e.Uploads) == 0 && len(e.Failures) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				ts, e.Pipeline, e.TraceId, e.Outcome, "-", e.Marker, "-", "-", "-", oneLine(e.Error))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/migrate"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	flagMigrateFrom        string
+	flagMigrateTo          string
+	flagMigratePrefix      string
+	flagMigrateKeyTemplate string
+	flagMigrateCheckpoint  string
+	flagMigrateConcurrency int
+	flagMigrateOutput      string
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy objects between two configured storages",
+	Long: "Copy objects between two configured storages, e.g. from MinIO to GCS. Storages are referenced as " +
+		"<pipeline>:<storage type>. Objects are copied server-side when both storages are buckets on the same endpoint, " +
+		"and downloaded and uploaded otherwise. Keys under the storage prefixes are kept unless --key-template is set. " +
+		"Checksums are verified after each copy, objects already in the destination are skipped and the progress can be " +
+		"saved to a checkpoint file to resume an interrupted migration.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ok, err := runMigrate(cmd, cmd.OutOrStdout())
+		if err != nil {
+			logx.As().Error().Err(err).Msg("Failed to migrate objects")
+			os.Exit(1)
+		}
+
+		if !ok {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.Flags().StringVarP(&flagMigrateFrom, "from", "", "", "source storage as <pipeline>:<storage type> (e.g. record-stream-uploader:S3)")
+	migrateCmd.Flags().StringVarP(&flagMigrateTo, "to", "", "", "destination storage as <pipeline>:<storage type> (e.g. record-stream-uploader:GCS)")
+	migrateCmd.Flags().StringVarP(&flagMigratePrefix, "prefix", "p", "", "copy only the objects whose key under the source prefix starts with it")
+	migrateCmd.Flags().StringVarP(&flagMigrateKeyTemplate, "key-template", "", "", "template of the destination key under the destination prefix (variables: key, dir, fileName, nodeId, year, month, day, ...)")
+	migrateCmd.Flags().StringVarP(&flagMigrateCheckpoint, "checkpoint", "", "", "file to save the progress to and resume from")
+	migrateCmd.Flags().IntVarP(&flagMigrateConcurrency, "concurrency", "", migrate.DefaultConcurrency, "number of objects copied in parallel")
+	migrateCmd.Flags().StringVarP(&flagMigrateOutput, "output", "o", outputTable, "output format (table or json)")
+
+	_ = migrateCmd.MarkFlagRequired("from")
+	_ = migrateCmd.MarkFlagRequired("to")
+}
+
+// runMigrate copies the objects between the storages and prints the report.
+// It returns false if any object failed to be copied.
+func runMigrate(cmd *cobra.Command, w io.Writer) (bool, error) {
+	if flagMigrateOutput != outputTable && flagMigrateOutput != outputJSON {
+		return false, fmt.Errorf("invalid output format '%s', expected %s or %s", flagMigrateOutput, outputTable, outputJSON)
+	}
+
+	if flagMigrateConcurrency <= 0 {
+		return false, fmt.Errorf("invalid --concurrency %d, expected a positive number", flagMigrateConcurrency)
+	}
+
+	srcPipeline, src, err := storageRef(cmd.Context(), flagMigrateFrom, "", 0)
+	if err != nil {
+		return false, fmt.Errorf("invalid --from: %w", err)
+	}
+
+	// downloads are staged in a work directory used as the root directory of the destination storage, so that they
+	// are uploaded with the destination keys
+	workDir, err := os.MkdirTemp("", "cheetah-migrate-")
+	if err != nil {
+		return false, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			logx.As().Warn().Err(err).Str("dir", workDir).Msg("Failed to remove work directory")
+		}
+	}()
+
+	destPipeline, dest, err := storageRef(cmd.Context(), flagMigrateTo, workDir, 1)
+	if err != nil {
+		return false, fmt.Errorf("invalid --to: %w", err)
+	}
+
+	report, err := migrate.Run(cmd.Context(), src, dest, migrate.Options{
+		SrcPathPrefix:  storagePathPrefix(srcPipeline, src.Type()),
+		DestPathPrefix: storagePathPrefix(destPipeline, dest.Type()),
+		Prefix:         flagMigratePrefix,
+		KeyTemplate:    flagMigrateKeyTemplate,
+		WorkDir:        workDir,
+		Concurrency:    flagMigrateConcurrency,
+		CheckpointFile: flagMigrateCheckpoint,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if flagMigrateOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return false, err
+		}
+	} else if err := printMigrateTable(w, report); err != nil {
+		return false, err
+	}
+
+	return len(report.Failed) == 0, nil
+}
+
+// storageRef creates the storage referenced as <pipeline>:<storage type>. If rootDir is set, it replaces the root
+// directory of the pipeline for the storage; i makes the handler ID unique.
+func storageRef(ctx context.Context, ref string, rootDir string, i int) (*config.PipelineConfig, core.Storage, error) {
+	sep := strings.LastIndex(ref, ":")
+	if sep <= 0 || sep == len(ref)-1 {
+		return nil, nil, fmt.Errorf("expected <pipeline>:<storage type>, got '%s'", ref)
+	}
+
+	pipeline, err := selectPipeline(ref[:sep])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rootDir != "" {
+		pc := *pipeline
+		scanner := *pipeline.Scanner
+		scanner.Directory = rootDir
+		pc.Scanner = &scanner
+		pipeline = &pc
+	}
+
+	s, err := openStorage(ctx, pipeline, ref[sep+1:], i)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pipeline '%s': %w", pipeline.Name, err)
+	}
+
+	return pipeline, s, nil
+}
+
+// printMigrateTable prints a summary row followed by one row per failed object.
+func printMigrateTable(w io.Writer, report *migrate.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SOURCE\tDESTINATION\tLISTED\tCOPIED\tSKIPPED\tFAILED\tRESUMED")
+	_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%t\n", report.Source, report.Destination, report.Listed,
+		len(report.Copied), len(report.Skipped), len(report.Failed), report.Resumed)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Failed) == 0 {
+		return nil
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\nSRC_KEY\tDEST_KEY\tERROR")
+	for _, o := range report.Failed {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", o.SrcKey, orDash(o.DestKey), oneLine(o.Error))
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/restore"
+	"golang.hedera.com/solo-cheetah/internal/storage"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	flagRestorePipeline    string
+	flagRestoreStorage     string
+	flagRestoreDest        string
+	flagRestorePrefix      string
+	flagRestoreSince       string
+	flagRestoreUntil       string
+	flagRestoreConcurrency int
+	flagRestoreOverwrite   bool
+	flagRestoreOutput      string
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Download stream files from a storage into a local directory",
+	Long: "Download stream files from a storage of a pipeline into a local directory, recreating the directory layout " +
+		"the files had under the pipeline's root directory. Objects can be filtered by key prefix and modification time. " +
+		"Checksums are verified after each download and files already restored with the same checksum are skipped.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ok, err := runRestore(cmd, cmd.OutOrStdout())
+		if err != nil {
+			logx.As().Error().Err(err).Msg("Failed to restore files")
+			os.Exit(1)
+		}
+
+		if !ok {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	restoreCmd.Flags().StringVarP(&flagRestorePipeline, "pipeline", "", "", "name of the pipeline whose storage is restored (default the only enabled pipeline)")
+	restoreCmd.Flags().StringVarP(&flagRestoreStorage, "storage", "s", "", "storage type to restore from (e.g. S3, GCS, LocalDir; default the first enabled storage)")
+	restoreCmd.Flags().StringVarP(&flagRestoreDest, "dest", "d", "", "local directory where the files are restored")
+	restoreCmd.Flags().StringVarP(&flagRestorePrefix, "prefix", "p", "", "restore only the objects whose key under the bucket prefix starts with it (e.g. 'record0.0.3/')")
+	restoreCmd.Flags().StringVarP(&flagRestoreSince, "since", "", "", "restore objects modified at or after the time (RFC3339 or a duration such as 24h)")
+	restoreCmd.Flags().StringVarP(&flagRestoreUntil, "until", "", "", "restore objects modified before the time (RFC3339 or a duration such as 1h)")
+	restoreCmd.Flags().IntVarP(&flagRestoreConcurrency, "concurrency", "", restore.DefaultConcurrency, "number of parallel downloads")
+	restoreCmd.Flags().BoolVarP(&flagRestoreOverwrite, "overwrite", "", false, "replace local files that exist with a different checksum")
+	restoreCmd.Flags().StringVarP(&flagRestoreOutput, "output", "o", outputTable, "output format (table or json)")
+
+	_ = restoreCmd.MarkFlagRequired("dest")
+}
+
+// runRestore restores the objects of the selected storage and prints the report.
+// It returns false if any object failed to be restored.
+func runRestore(cmd *cobra.Command, w io.Writer) (bool, error) {
+	if flagRestoreOutput != outputTable && flagRestoreOutput != outputJSON {
+		return false, fmt.Errorf("invalid output format '%s', expected %s or %s", flagRestoreOutput, outputTable, outputJSON)
+	}
+
+	if flagRestoreConcurrency <= 0 {
+		return false, fmt.Errorf("invalid --concurrency %d, expected a positive number", flagRestoreConcurrency)
+	}
+
+	now := time.Now()
+	since, err := parseTimeFlag(flagRestoreSince, now)
+	if err != nil {
+		return false, fmt.Errorf("invalid --since: %w", err)
+	}
+
+	until, err := parseTimeFlag(flagRestoreUntil, now)
+	if err != nil {
+		return false, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	pipeline, err := selectPipeline(flagRestorePipeline)
+	if err != nil {
+		return false, err
+	}
+
+	s, err := openStorage(cmd.Context(), pipeline, flagRestoreStorage, 0)
+	if err != nil {
+		return false, fmt.Errorf("pipeline '%s': %w", pipeline.Name, err)
+	}
+
+	report, err := restore.Run(cmd.Context(), s, restore.Options{
+		DestDir:     flagRestoreDest,
+		PathPrefix:  storagePathPrefix(pipeline, s.Type()),
+		Prefix:      flagRestorePrefix,
+		Since:       since,
+		Until:       until,
+		Concurrency: flagRestoreConcurrency,
+		Overwrite:   flagRestoreOverwrite,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if flagRestoreOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return false, err
+		}
+	} else if err := printRestoreTable(w, report); err != nil {
+		return false, err
+	}
+
+	return len(report.Failed) == 0, nil
+}
+
+// selectPipeline returns the pipeline with the name, or the only enabled pipeline if the name is empty.
+func selectPipeline(name string) (*config.PipelineConfig, error) {
+	var enabled []*config.PipelineConfig
+	for _, pipeline := range config.Get().Pipelines {
+		if name != "" && pipeline.Name == name {
+			return pipeline, nil
+		}
+		if pipeline.Enabled {
+			enabled = append(enabled, pipeline)
+		}
+	}
+
+	if name != "" {
+		return nil, fmt.Errorf("pipeline '%s' not found", name)
+	}
+
+	if len(enabled) != 1 {
+		return nil, fmt.Errorf("found %d enabled pipelines, select one with --pipeline", len(enabled))
+	}
+
+	return enabled[0], nil
+}
+
+// openStorage opens the storage of the type, or the first enabled storage if the type is empty. Only the selected
+// storage is opened, and its bucket or directory is never created.
+func openStorage(ctx context.Context, pc *config.PipelineConfig, storageType string, i int) (core.Storage, error) {
+	sc := pc.Processor.Storage
+	enabled := map[string]bool{
+		storage.TypeLocalDir: sc.LocalDir.Enabled,
+		storage.TypeS3:       sc.S3.Enabled,
+		storage.TypeGCS:      sc.GCS.Enabled,
+	}
+
+	if storageType == "" {
+		for _, t := range []string{storage.TypeLocalDir, storage.TypeS3, storage.TypeGCS} {
+			if enabled[t] {
+				storageType = t
+				break
+			}
+		}
+		if storageType == "" {
+			return nil, fmt.Errorf("no storage enabled")
+		}
+	}
+
+	storages, err := openStorages(ctx, pc, storageType, i)
+	if err != nil {
+		return nil, err
+	}
+	if len(storages) == 0 {
+		return nil, fmt.Errorf("storage %s is not enabled", storageType)
+	}
+
+	return storages[0], nil
+}
+
+// openStorages opens the enabled storages of the pipeline, or only the one of the type if set. Unlike prepareStorages,
+// it checks each bucket or directory once with the context and never creates it, so that reading commands neither
+// change the storages nor wait for them to come up.
+func openStorages(ctx context.Context, pc *config.PipelineConfig, storageType string, i int) ([]core.Storage, error) {
+	selected := func(t string, enabled bool) bool {
+		return enabled && (storageType == "" || strings.EqualFold(t, storageType))
+	}
+
+	var storages []core.Storage
+	sc := pc.Processor.Storage
+	if selected(storage.TypeLocalDir, sc.LocalDir.Enabled) {
+		localDir, err := storage.OpenLocalDir(fmt.Sprintf("dir-%d-%s", i, pc.Name),
+			*sc.LocalDir, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open LocalDir storage: %w", err)
+		}
+		storages = append(storages, localDir)
+	}
+
+	if selected(storage.TypeS3, sc.S3.Enabled) {
+		s3, err := storage.OpenS3(ctx, fmt.Sprintf("s3-%d-%s", i, pc.Name),
+			*sc.S3, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open S3 storage: %w", err)
+		}
+		storages = append(storages, s3)
+	}
+
+	if selected(storage.TypeGCS, sc.GCS.Enabled) {
+		gcs, err := storage.OpenGCSWithS3(ctx, fmt.Sprintf("gcs-%d-%s", i, pc.Name),
+			*sc.GCS, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GCS storage: %w", err)
+		}
+		storages = append(storages, gcs)
+	}
+
+	return storages, nil
+}
+
+// storagePathPrefix returns the path prefix configured for the storage type in the pipeline.
+func storagePathPrefix(pc *config.PipelineConfig, storageType string) string {
+	switch storageType {
+	case storage.TypeS3:
+		return pc.Processor.Storage.S3.Prefix
+	case storage.TypeGCS:
+		return pc.Processor.Storage.GCS.Prefix
+	default:
+		return ""
+	}
+}
+
+// printRestoreTable prints a summary row followed by one row per failed object.
+// Restored and skipped objects are only counted to keep the output short for large or resumed restores.
+func printRestoreTable(w io.Writer, report *restore.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STORAGE\tLISTED\tRESTORED\tSKIPPED\tFAILED")
+	_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", report.Storage, report.Listed, len(report.Restored),
+		len(report.Skipped), len(report.Failed))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Failed) == 0 {
+		return nil
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\nKEY\tDEST\tERROR")
+	for _, f := range report.Failed {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Key, f.Dest, oneLine(f.Error))
+	}
+
+	return tw.Flush()
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"golang.hedera.com/solo-cheetah/pkg/sniff"
 	_ "net/http/pprof"
@@ -35,11 +38,14 @@ func init() {
 
 	// make flags mandatory
 	_ = rootCmd.MarkPersistentFlagRequired("config")
-	//_ = rootCmd.MarkPersistentFlagRequired("host-id")
-	//_ = rootCmd.MarkPersistentFlagRequired("start-date")
-	//_ = rootCmd.MarkPersistentFlagRequired("end-date")
 
 	rootCmd.AddCommand(uploadCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(backfillCmd)
+	rootCmd.AddCommand(runOnceCmd)
+	rootCmd.AddCommand(migrateCmd)
 }
 
 func initConfig() {
@@ -61,3 +67,24 @@ func startProfiling(ctx context.Context) error {
 	profilingConf := *config.Get().Profiling
 	return sniff.Start(ctx, profilingConf)
 }
+
+func startMetrics(ctx context.Context) error {
+	metricsConf := *config.Get().Metrics
+	return metrics.Start(ctx, metricsConf)
+}
+
+func startTracing(ctx context.Context) error {
+	tracingConf := *config.Get().Tracing
+	return tracing.Start(ctx, tracingConf)
+}
+
+// openJournal opens the upload journal if it is enabled. It returns nil if the journal is disabled.
+func openJournal() (*journal.Journal, error) {
+	journalConf := *config.Get().Journal
+	if !journalConf.Enabled {
+		logx.As().Debug().Msg("Upload journal is disabled")
+		return nil, nil
+	}
+
+	return journal.New(journalConf)
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/summary"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+)
+
+var flagRunOnceSummaryFile string
+
+var runOnceCmd = &cobra.Command{
+	Use:   "run-once",
+	Short: "Upload the current backlog of files and exit",
+	Long: "Upload the current backlog of files and exit. Each enabled pipeline scans its root directory once and " +
+		"processes every marker found. A JSON summary with the per pipeline counts, bytes, failures and durations is " +
+		"written to stdout or to --summary-file. The command exits with status 2 if any marker failed or a pipeline " +
+		"stopped because of an error, and with status 1 if the pipelines could not be started.",
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := runOnce(cmd)
+		if err != nil {
+			logx.As().Error().Err(err).Msg("Failed to run pipelines")
+		}
+
+		if !report.OK {
+			os.Exit(2)
+		}
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	runOnceCmd.Flags().StringVarP(&flagRunOnceSummaryFile, "summary-file", "", "", "file to write the JSON summary to (default stdout)")
+}
+
+// runOnce runs the enabled pipelines once and writes the summary. The summary is written even if a pipeline fails.
+func runOnce(cmd *cobra.Command) (summary.Report, error) {
+	collector := summary.NewCollector()
+	runErr := runPipelines(cmd.Context(), pipelineOptions{
+		poll:      false,
+		recorders: []core.Recorder{collector},
+		stopped:   collector.PipelineStopped,
+	})
+
+	report := collector.Report()
+	logx.As().Info().
+		Bool("ok", report.OK).
+		Int("pipelines", len(report.Pipelines)).
+		Int64("duration_ms", report.DurationMs).
+		Msg("Run completed")
+
+	if err := writeSummary(cmd.OutOrStdout(), flagRunOnceSummaryFile, report); err != nil {
+		return report, err
+	}
+
+	return report, runErr
+}
+
+// writeSummary writes the summary as JSON to the file, or to w if the file is empty.
+func writeSummary(w io.Writer, file string, report summary.Report) error {
+	if file == "" {
+		return encodeSummary(w, report)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %w", err)
+	}
+
+	if err := encodeSummary(f, report); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close summary file: %w", err)
+	}
+
+	return nil
+}
+
+func encodeSummary(w io.Writer, report summary.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	return nil
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/chain"
 	"golang.hedera.com/solo-cheetah/internal/config"
 	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/gap"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/orphan"
 	"golang.hedera.com/solo-cheetah/internal/processor"
 	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"golang.hedera.com/solo-cheetah/internal/sequencer"
+	"golang.hedera.com/solo-cheetah/internal/signature"
 	"golang.hedera.com/solo-cheetah/internal/storage"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"os"
 	"os/signal"
@@ -32,11 +39,30 @@ var uploadCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		runUpload(cmd.Context())
+		if err := runPipelines(cmd.Context(), pipelineOptions{poll: flagPoll}); err != nil {
+			logx.As().Error().Err(err).Msg("Failed to upload files")
+			os.Exit(1)
+		}
 	},
 }
 
-func runUpload(ctx context.Context) {
+// pipelineOptions customizes how runPipelines runs the enabled pipelines.
+type pipelineOptions struct {
+	// poll keeps scanning for marker files until the process is stopped; otherwise each pipeline scans once.
+	poll bool
+	// recorders are notified of the processed marker files in addition to the upload journal.
+	recorders []core.Recorder
+	// filters select the marker files queued by the scanners.
+	filters []scanner.Filter
+	// configure, if set, returns the configuration to run a pipeline with (e.g. namespaced storages).
+	configure func(pc *config.PipelineConfig) *config.PipelineConfig
+	// stopped, if set, is called when a pipeline stops with its run duration and the error it stopped with.
+	stopped func(pipeline string, duration time.Duration, err error)
+}
+
+// runPipelines runs the enabled pipelines until they stop or the process receives an exit signal.
+// It returns an error if a pipeline could not be started or stopped because of an error.
+func runPipelines(ctx context.Context, opts pipelineOptions) error {
 	ctx, cancelFunc := context.WithCancel(ctx)
 	defer cancelFunc()
 
@@ -50,49 +76,142 @@ func runUpload(ctx context.Context) {
 		logx.As().Fatal().Err(err).Msg("Failed to initialize profiling")
 	}
 
+	err = startMetrics(ctx)
+	if err != nil {
+		logx.As().Fatal().Err(err).Msg("Failed to initialize metrics")
+	}
+
+	err = startTracing(ctx)
+	if err != nil {
+		logx.As().Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	defer tracing.Stop() // flush pending spans before exiting
+
+	recorders := append([]core.Recorder(nil), opts.recorders...)
+	uploadJournal, err := openJournal()
+	if err != nil {
+		logx.As().Fatal().Err(err).Msg("Failed to open upload journal")
+	}
+	if uploadJournal != nil {
+		recorders = append(recorders, uploadJournal)
+		defer func() {
+			if err := uploadJournal.Close(); err != nil {
+				logx.As().Error().Err(err).Msg("Failed to close upload journal")
+			}
+		}()
+	}
+
 	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var pipelineErr error
 	for _, pipeline := range config.Get().Pipelines {
 		if !pipeline.Enabled {
 			logx.As().Warn().Str("pipeline", pipeline.Name).Msg("Pipeline disabled")
 			continue
 		}
 
+		if opts.configure != nil {
+			pipeline = opts.configure(pipeline)
+		}
+
 		logx.As().Info().
 			Str("pipeline", pipeline.Name).
 			Int("total_processors", pipeline.Processor.MaxProcessors).
 			Str("description", pipeline.Description).
 			Str("scanner_directory", pipeline.Scanner.Directory).
+			Str("scanner_mode", pipeline.Scanner.Mode).
 			Str("scanner_pattern", pipeline.Scanner.Pattern).
+			Int("scanner_markers", len(pipeline.Scanner.Markers)).
 			Str("scanner_interval", pipeline.Scanner.Interval).
 			Int("scanner_batch_size", pipeline.Scanner.BatchSize).
+			Str("scanner_ordering", pipeline.Scanner.Ordering).
 			Int("max_processors", pipeline.Processor.MaxProcessors).
 			Str("flush_delay", pipeline.Processor.FlushDelay).
-			Str("matchers", fmt.Sprintf("%s", pipeline.Processor.FileMatcherConfigs)).
+			Str("matchers", fmt.Sprintf("%v", pipeline.Processor.FileMatcherConfigs)).
 			Msg("Starting pipeline")
 
 		// Create scanner
-		sc, err := scanner.NewScanner(fmt.Sprintf("scanner-%s", pipeline.Name),
-			pipeline.Scanner.Directory, pipeline.Scanner.Pattern, pipeline.Scanner.BatchSize)
+		markers, err := prepareMarkers(pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to prepare marker patterns of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		sc, err := prepareScanner(pipeline, markers, opts.filters)
+		if err != nil {
+			return fmt.Errorf("failed to create scanner of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		// Publish marker files in order if enabled; markers are registered as they are found, before the ordered
+		// queue, so that a marker still queued is known to be in flight
+		seq, err := prepareSequencer(pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to prepare ordered commit of pipeline '%s': %w", pipeline.Name, err)
+		}
+		if seq != nil {
+			sc = seq.Track(sc)
+		}
+
+		sc, err = scanner.NewOrderedScanner(sc, pipeline.Name, pipeline.Scanner.Ordering, pipeline.Scanner.QueueSize)
+		if err != nil {
+			return fmt.Errorf("failed to create scanner of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		// Deferred markers are matched again by the next scan, so they can only be deferred while polling
+		shared := processor.Shared{Markers: markers, Sequencer: seq}
+		if opts.poll {
+			shared.Deferrals = processor.NewDeferrals()
+		}
+		if opts.poll && pipeline.OrphanSweep != nil && pipeline.OrphanSweep.Enabled {
+			shared.InFlight = processor.NewInFlight()
+		}
+
+		shared.Chain, err = prepareChainVerifier(pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to prepare chain verification of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		shared.Signatures, err = prepareSignatureVerifier(pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to prepare signature verification of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		pr, err := pipelineRecorders(pipeline, recorders, shared.Chain)
 		if err != nil {
-			logx.As().Error().Err(err).Msg("Failed to create scanner")
-			return
+			return fmt.Errorf("failed to prepare gap detection of pipeline '%s': %w", pipeline.Name, err)
 		}
 
 		// Prepare processors
-		pc, err := prepareProcessors(pipeline)
+		pc, err := prepareProcessors(pipeline, shared, pr)
 		if err != nil {
-			logx.As().Error().Err(err).Str("pipeline", pipeline.Name).Msg("Failed to prepare processor dependencies")
-			return
+			return fmt.Errorf("failed to prepare processor dependencies of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		// Sweep the scanner directory for orphaned files while polling, leaving the files of processed markers alone
+		sweeper, err := prepareOrphanSweeper(pipeline, markers, shared.InFlight, recorders)
+		if err != nil {
+			return fmt.Errorf("failed to prepare orphan sweep of pipeline '%s': %w", pipeline.Name, err)
+		}
+		if sweeper != nil && opts.poll {
+			go sweeper.Run(ctx)
 		}
 
 		// Start pipeline in a separate goroutine
 		wg.Add(1)
 		go func(p *config.PipelineConfig, s core.Scanner, ps []core.Processor) {
 			defer wg.Done()
-			err = startPipeline(ctx, p, s, ps)
+			started := time.Now()
+			err := startPipeline(ctx, p, s, ps, opts.poll)
 			logx.As().Warn().Str("pipeline", p.Name).Msg("Pipeline stopped")
+			if opts.stopped != nil {
+				opts.stopped(p.Name, time.Since(started), err)
+			}
 			if err != nil {
 				logx.As().Error().Stack().Err(err).Msg("Stopping all pipelines because of error ")
+				errMu.Lock()
+				if pipelineErr == nil {
+					pipelineErr = err
+				}
+				errMu.Unlock()
 				cancelFunc() // cancel all pipelines if one fails
 			}
 		}(pipeline, sc, pc)
@@ -118,45 +237,193 @@ func runUpload(ctx context.Context) {
 	}
 
 	time.Sleep(1 * time.Second)
+
+	errMu.Lock()
+	defer errMu.Unlock()
+	return pipelineErr
 }
 
-func prepareProcessors(pc *config.PipelineConfig) ([]core.Processor, error) {
-	// initialize processors
-	var processors []core.Processor
-	for i := 0; i < pc.Processor.MaxProcessors; i++ {
-		var storages []core.Storage
+// prepareMarkers compiles the marker patterns of the pipeline with the file matchers of their marker files, or returns
+// nil in the quiescence mode.
+func prepareMarkers(pc *config.PipelineConfig) (matcher.MarkerPatterns, error) {
+	if pc.Scanner.Mode == scanner.ModeQuiescence {
+		return nil, nil
+	}
 
-		if pc.Processor.Storage.LocalDir.Enabled {
-			localDir, err := storage.NewLocalDir(fmt.Sprintf("dir-%d-%s", i, pc.Name),
-				*pc.Processor.Storage.LocalDir, *pc.Processor.Retry, pc.Scanner.Directory)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create LocalDir storage: %w", err)
-			}
+	return matcher.NewMarkerPatterns(pc.Scanner.Pattern, pc.Scanner.Markers, pc.Processor.FileMatcherConfigs)
+}
 
-			storages = append(storages, localDir)
+// prepareScanner creates the scanner of the pipeline for its mode: marker files matching the marker patterns, or files
+// that stopped changing in the quiescence mode.
+func prepareScanner(pc *config.PipelineConfig, markers matcher.MarkerPatterns, filters []scanner.Filter) (core.Scanner, error) {
+	id := fmt.Sprintf("scanner-%s", pc.Name)
+	switch pc.Scanner.Mode {
+	case "", scanner.ModeMarker:
+		return scanner.NewMarkerScanner(id, pc.Name, pc.Scanner.Directory, markers, pc.Scanner.BatchSize, filters...)
+	case scanner.ModeQuiescence:
+		qc := pc.Scanner.Quiescence
+		if qc == nil {
+			return nil, fmt.Errorf("quiescence mode requires the quiescence configuration")
 		}
 
-		if pc.Processor.Storage.S3.Enabled {
-			s3, err := storage.NewS3(fmt.Sprintf("s3-%d-%s", i, pc.Name),
-				*pc.Processor.Storage.S3, *pc.Processor.Retry, pc.Scanner.Directory)
+		opts := scanner.QuiescenceOptions{Include: qc.Include, Exclude: qc.Exclude, CheckOpen: !qc.SkipOpenCheck}
+		if qc.StablePeriod != "" {
+			var err error
+			opts.StablePeriod, err = time.ParseDuration(qc.StablePeriod)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create S3 storage: %w", err)
+				return nil, fmt.Errorf("failed to parse stablePeriod: %w", err)
 			}
+		}
 
-			storages = append(storages, s3)
+		return scanner.NewQuiescenceScanner(id, pc.Name, pc.Scanner.Directory, opts, pc.Scanner.BatchSize, filters...)
+	default:
+		return nil, fmt.Errorf("invalid scanner mode '%s', expected %s or %s",
+			pc.Scanner.Mode, scanner.ModeMarker, scanner.ModeQuiescence)
+	}
+}
+
+// prepareSequencer creates the sequencer shared by the processors of the pipeline, or nil if ordered commit is
+// disabled.
+func prepareSequencer(pc *config.PipelineConfig) (*sequencer.Sequencer, error) {
+	oc := pc.Processor.OrderedCommit
+	if oc == nil || !oc.Enabled {
+		return nil, nil
+	}
+
+	// markers are published oldest first, other orderings would hold newer markers until the head-of-line timeout
+	if o := pc.Scanner.Ordering; o != "" && o != scanner.OrderingOldest {
+		return nil, fmt.Errorf("ordered commit requires the default or oldest scanner ordering, got ordering %s", o)
+	}
+
+	timeout := sequencer.DefaultHeadOfLineTimeout
+	if oc.HeadOfLineTimeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(oc.HeadOfLineTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse headOfLineTimeout: %w", err)
 		}
+	}
+
+	return sequencer.NewSequencer(pc.Name, timeout), nil
+}
+
+// prepareChainVerifier creates the running hash chain verifier shared by the processors of the pipeline, or nil if
+// chain verification is disabled.
+func prepareChainVerifier(pc *config.PipelineConfig) (*chain.Verifier, error) {
+	cv := pc.Processor.ChainVerification
+	if cv == nil || !cv.Enabled {
+		return nil, nil
+	}
+
+	// the files of a directory must be verified in order, see chain.Verifier
+	if o := pc.Scanner.Ordering; o != "" && o != scanner.OrderingOldest {
+		return nil, fmt.Errorf("chain verification requires the default or oldest scanner ordering, got ordering %s", o)
+	}
+	oc := pc.Processor.OrderedCommit
+	if (oc == nil || !oc.Enabled) && pc.Processor.MaxProcessors > 1 {
+		return nil, fmt.Errorf("chain verification requires ordered commit or a single processor, got %d processors",
+			pc.Processor.MaxProcessors)
+	}
 
-		if pc.Processor.Storage.GCS.Enabled {
-			gcs, err := storage.NewGCSWithS3(fmt.Sprintf("gcs-%d-%s", i, pc.Name),
-				*pc.Processor.Storage.GCS, *pc.Processor.Retry, pc.Scanner.Directory)
+	return chain.NewVerifier(pc.Name, cv.Mode, cv.StateFile)
+}
+
+// prepareSignatureVerifier creates the signature verifier shared by the processors of the pipeline, or nil if signature
+// verification is disabled.
+func prepareSignatureVerifier(pc *config.PipelineConfig) (*signature.Verifier, error) {
+	sv := pc.Processor.SignatureVerification
+	if sv == nil || !sv.Enabled {
+		return nil, nil
+	}
+
+	return signature.NewVerifier(pc.Name, sv.KeyDir, sv.AddressBook)
+}
+
+// pipelineRecorders returns the recorders of the pipeline: the shared recorders followed by the gap detector of the
+// pipeline if it is enabled, and the chain verifier, which advances the chain once markers are uploaded.
+func pipelineRecorders(pc *config.PipelineConfig, recorders []core.Recorder, cv *chain.Verifier) ([]core.Recorder, error) {
+	recorders = append([]core.Recorder(nil), recorders...)
+
+	if gd := pc.GapDetection; gd != nil && gd.Enabled {
+		maxGap := gap.DefaultMaxGap
+		if gd.MaxGap != "" {
+			var err error
+			maxGap, err = time.ParseDuration(gd.MaxGap)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create GCS storage: %w", err)
+				return nil, fmt.Errorf("failed to parse maxGap: %w", err)
 			}
+		}
+		recorders = append(recorders, gap.NewDetector(pc.Name, maxGap))
+	}
+
+	if cv != nil {
+		recorders = append(recorders, cv)
+	}
+
+	return recorders, nil
+}
+
+// prepareOrphanSweeper creates the orphan sweeper of the scanner directory of the pipeline, or nil if the orphan sweep
+// is disabled. Orphaned files are uploaded to the storages of the pipeline under the orphans prefix and recorded by
+// the shared recorders; the gap detector and chain verifier only follow marker files.
+func prepareOrphanSweeper(pc *config.PipelineConfig, markers matcher.MarkerPatterns, inFlight *processor.InFlight,
+	recorders []core.Recorder) (*orphan.Sweeper, error) {
+	oc := pc.OrphanSweep
+	if oc == nil || !oc.Enabled {
+		return nil, nil
+	}
+	if pc.Scanner.Mode == scanner.ModeQuiescence {
+		return nil, fmt.Errorf("orphan sweep requires marker files, it is not supported in the %s mode", scanner.ModeQuiescence)
+	}
+
+	opts := orphan.Options{
+		RootDir:    pc.Scanner.Directory,
+		Markers:    markers,
+		Patterns:   oc.Patterns,
+		Action:     oc.Action,
+		HoldingDir: oc.HoldingDir,
+		BatchSize:  pc.Scanner.BatchSize,
+	}
+	if inFlight != nil {
+		opts.InFlight = inFlight
+	}
+
+	var err error
+	if oc.Interval != "" {
+		opts.Interval, err = time.ParseDuration(oc.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse interval: %w", err)
+		}
+	}
+	if oc.MinAge != "" {
+		opts.MinAge, err = time.ParseDuration(oc.MinAge)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse minAge: %w", err)
+		}
+	}
+
+	if oc.Action == orphan.ActionUpload {
+		// the index after the processors keeps the storage IDs unique
+		opts.Storages, err = prepareStorages(config.Namespace(pc, orphan.Prefix), pc.Processor.MaxProcessors)
+		if err != nil {
+			return nil, err
+		}
+		opts.Recorders = recorders
+	}
+
+	return orphan.NewSweeper(pc.Name, opts)
+}
 
-			storages = append(storages, gcs)
+func prepareProcessors(pc *config.PipelineConfig, shared processor.Shared, recorders []core.Recorder) ([]core.Processor, error) {
+	// initialize processors
+	var processors []core.Processor
+	for i := 0; i < pc.Processor.MaxProcessors; i++ {
+		storages, err := prepareStorages(pc, i)
+		if err != nil {
+			return nil, err
 		}
 
-		p, err := processor.NewProcessor(fmt.Sprintf("processor-%d-%s", i, pc.Name), storages, pc.Processor)
+		p, err := processor.NewProcessor(fmt.Sprintf("processor-%d-%s", i, pc.Name), pc.Name, storages, pc.Processor, shared, recorders...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create processor: %w", err)
 		}
@@ -167,8 +434,45 @@ func prepareProcessors(pc *config.PipelineConfig) ([]core.Processor, error) {
 	return processors, nil
 }
 
+// prepareStorages creates the enabled storage handlers of the pipeline; i is used to make the handler IDs unique.
+func prepareStorages(pc *config.PipelineConfig, i int) ([]core.Storage, error) {
+	var storages []core.Storage
+
+	if pc.Processor.Storage.LocalDir.Enabled {
+		localDir, err := storage.NewLocalDir(fmt.Sprintf("dir-%d-%s", i, pc.Name),
+			*pc.Processor.Storage.LocalDir, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create LocalDir storage: %w", err)
+		}
+
+		storages = append(storages, localDir)
+	}
+
+	if pc.Processor.Storage.S3.Enabled {
+		s3, err := storage.NewS3(fmt.Sprintf("s3-%d-%s", i, pc.Name),
+			*pc.Processor.Storage.S3, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 storage: %w", err)
+		}
+
+		storages = append(storages, s3)
+	}
+
+	if pc.Processor.Storage.GCS.Enabled {
+		gcs, err := storage.NewGCSWithS3(fmt.Sprintf("gcs-%d-%s", i, pc.Name),
+			*pc.Processor.Storage.GCS, *pc.Processor.Retry, pc.Scanner.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS storage: %w", err)
+		}
+
+		storages = append(storages, gcs)
+	}
+
+	return storages, nil
+}
+
 func startPipeline(ctx context.Context, c *config.PipelineConfig,
-	scanner core.Scanner, processors []core.Processor) error {
+	scanner core.Scanner, processors []core.Processor, poll bool) error {
 
 	delay, err := time.ParseDuration(c.Scanner.Interval)
 	if err != nil {
@@ -234,7 +538,7 @@ func startPipeline(ctx context.Context, c *config.PipelineConfig,
 				return fmt.Errorf("pipeline '%s' encountered error", c.Name)
 			}
 
-			if flagPoll == false {
+			if poll == false {
 				logx.As().Trace().Str("pipeline", c.Name).Msg("Polling is disabled, exiting pipeline...")
 				return nil
 			}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/internal/verify"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	flagVerifyPipeline string
+	flagVerifyRepair   bool
+	flagVerifyMinAge   time.Duration
+	flagVerifyOutput   string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that remote storages contain every uploaded file",
+	Long: "Verify that remote storages contain every uploaded file with the right checksum. The expected files are " +
+		"derived from the remaining local files and the upload journal. Missing, extra and checksum-mismatched objects " +
+		"are reported per storage, and can be re-uploaded from the surviving local copies with --repair.",
+	Run: func(cmd *cobra.Command, args []string) {
+		ok, err := runVerify(cmd, cmd.OutOrStdout())
+		if err != nil {
+			logx.As().Error().Err(err).Msg("Failed to verify storages")
+			os.Exit(1)
+		}
+
+		if !ok {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVarP(&flagVerifyPipeline, "pipeline", "", "", "name of the pipeline to verify (default all enabled pipelines)")
+	verifyCmd.Flags().BoolVarP(&flagVerifyRepair, "repair", "", false, "re-upload missing and mismatched objects from local copies")
+	verifyCmd.Flags().DurationVarP(&flagVerifyMinAge, "min-age", "", verify.DefaultMinAge, "minimum age of local files to be verified")
+	verifyCmd.Flags().StringVarP(&flagVerifyOutput, "output", "o", outputTable, "output format (table or json)")
+}
+
+// runVerify verifies the storages of the selected pipelines and prints the reports.
+// It returns false if any storage has unrepaired discrepancies.
+func runVerify(cmd *cobra.Command, w io.Writer) (bool, error) {
+	if flagVerifyOutput != outputTable && flagVerifyOutput != outputJSON {
+		return false, fmt.Errorf("invalid output format '%s', expected %s or %s", flagVerifyOutput, outputTable, outputJSON)
+	}
+
+	var entries []journal.Entry
+	journalConf := config.Get().Journal
+	if journalConf != nil && journalConf.Directory != "" {
+		var err error
+		entries, err = journal.Query(journalConf.Directory, journalConf.FileName, journal.Filter{})
+		if err != nil {
+			return false, err
+		}
+	} else {
+		logx.As().Warn().Msg("Upload journal is not configured, verifying remaining local files only")
+	}
+
+	var uploadJournal *journal.Journal
+	if flagVerifyRepair {
+		var err error
+		uploadJournal, err = openJournal()
+		if err != nil {
+			return false, err
+		}
+		if uploadJournal != nil {
+			defer func() {
+				if err := uploadJournal.Close(); err != nil {
+					logx.As().Error().Err(err).Msg("Failed to close upload journal")
+				}
+			}()
+		}
+	}
+
+	var reports []verify.Report
+	found := false
+	for _, pipeline := range config.Get().Pipelines {
+		if flagVerifyPipeline != "" && pipeline.Name != flagVerifyPipeline {
+			continue
+		}
+		if flagVerifyPipeline == "" && !pipeline.Enabled {
+			continue
+		}
+		found = true
+
+		storages, err := openStorages(cmd.Context(), pipeline, "", 0)
+		if err != nil {
+			return false, err
+		}
+
+		markers, err := prepareMarkers(pipeline)
+		if err != nil {
+			return false, fmt.Errorf("failed to prepare marker patterns of pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		opts := verify.Options{
+			Pipeline: pipeline.Name,
+			RootDir:  pipeline.Scanner.Directory,
+			Markers:  markers,
+			Entries:  entries,
+			MinAge:   flagVerifyMinAge,
+			Repair:   flagVerifyRepair,
+		}
+		if uploadJournal != nil {
+			opts.Recorder = uploadJournal
+		}
+
+		pipelineReports, err := verify.Run(cmd.Context(), storages, opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to verify pipeline '%s': %w", pipeline.Name, err)
+		}
+
+		reports = append(reports, pipelineReports...)
+	}
+
+	if !found {
+		return false, fmt.Errorf("no pipeline to verify")
+	}
+
+	if flagVerifyOutput == outputJSON {
+		if reports == nil {
+			reports = []verify.Report{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return false, err
+		}
+	} else if err := printVerifyTable(w, reports); err != nil {
+		return false, err
+	}
+
+	ok := true
+	for _, r := range reports {
+		ok = ok && r.OK()
+	}
+
+	return ok, nil
+}
+
+// printVerifyTable prints a summary row per storage followed by one row per finding.
+func printVerifyTable(w io.Writer, reports []verify.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PIPELINE\tSTORAGE\tEXPECTED\tREMOTE\tVERIFIED\tMISSING\tEXTRA\tMISMATCHED\tERROR")
+	for _, r := range reports {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", r.Pipeline, r.Storage, r.Expected, r.Remote,
+			r.Verified, len(r.Missing), len(r.Extra), len(r.Mismatched), oneLine(r.Error))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := false
+	for _, r := range reports {
+		for _, group := range []struct {
+			status   string
+			findings []verify.Finding
+		}{
+			{"missing", r.Missing},
+			{"extra", r.Extra},
+			{"mismatched", r.Mismatched},
+		} {
+			for _, f := range group.findings {
+				if !header {
+					_, _ = fmt.Fprintln(tw, "\nPIPELINE\tSTORAGE\tSTATUS\tKEY\tSRC\tEXPECTED\tACTUAL\tREPAIRED\tERROR")
+					header = true
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\t%s\n", r.Pipeline, r.Storage, group.status,
+					f.Key, f.Src, f.ExpectedChecksum, f.ActualChecksum, f.Repaired, oneLine(f.Error))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
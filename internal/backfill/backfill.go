@@ -0,0 +1,193 @@
+package backfill
+
+import (
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons for skipping a file during a backfill.
+const (
+	ReasonOutOfRange = "outside time range"
+	ReasonUploaded   = "already in storage"
+)
+
+// hostIdRegex restricts host IDs to characters that are safe to use as a single path segment (e.g. 0.0.3 or node1).
+var hostIdRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Window is the time range of a backfill. Start is inclusive and End is exclusive.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Validate returns an error if the window is empty.
+func (w Window) Validate() error {
+	if !w.Start.Before(w.End) {
+		return fmt.Errorf("start date %s must be before end date %s", w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Contains returns true if the time is within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// ValidateHostId returns an error if the host ID cannot be used to namespace object keys.
+func ValidateHostId(hostId string) error {
+	if hostId == "" || hostId == "." || hostId == ".." || !hostIdRegex.MatchString(hostId) {
+		return fmt.Errorf("invalid host ID '%s', expected letters, digits, '.', '_' or '-'", hostId)
+	}
+	return nil
+}
+
+// File describes a file that was uploaded, skipped or failed during a backfill.
+type File struct {
+	Pipeline string `json:"pipeline,omitempty"`
+	Marker   string `json:"marker"`
+	Src      string `json:"src,omitempty"`
+	Storage  string `json:"storage,omitempty"`
+	Dest     string `json:"dest,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report is the summary of a backfill.
+type Report struct {
+	HostId   string    `json:"host_id"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Markers  int       `json:"markers"`
+	Uploaded []File    `json:"uploaded,omitempty"`
+	Skipped  []File    `json:"skipped,omitempty"`
+	Failed   []File    `json:"failed,omitempty"`
+}
+
+// Summary collects the outcome of a backfill. It selects the marker files in the window as a scanner filter and
+// records the processed markers as a core.Recorder.
+type Summary struct {
+	mu     sync.Mutex
+	window Window
+	report Report
+}
+
+// NewSummary creates a summary of a backfill of the host within the window.
+func NewSummary(hostId string, window Window) *Summary {
+	return &Summary{
+		window: window,
+		report: Report{HostId: hostId, Start: window.Start, End: window.End},
+	}
+}
+
+// Filter returns true if the marker file is within the window. Markers outside the window are reported as skipped.
+func (s *Summary) Filter(path string, info os.FileInfo) bool {
+	t, source := scanner.MarkerTime(path, info)
+	if s.window.Contains(t) {
+		return true
+	}
+
+	logx.As().Debug().
+		Str("marker", path).
+		Time("marker_time", t).
+		Str("time_source", source).
+		Msg("Skipping marker file outside of the backfill time range")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Skipped = append(s.report.Skipped, File{Marker: path, Reason: ReasonOutOfRange})
+	return false
+}
+
+// Record records the uploaded, skipped and failed files of a processed marker file.
+func (s *Summary) Record(result core.ProcessorResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.report.Markers++
+
+	failed := false
+	for storageType, sr := range result.Result {
+		if sr == nil {
+			continue
+		}
+
+		if sr.Error != nil {
+			failed = true
+			s.report.Failed = append(s.report.Failed, File{
+				Pipeline: result.Pipeline,
+				Marker:   result.Path,
+				Storage:  storageType,
+				Error:    sr.Error.Error(),
+			})
+			continue
+		}
+
+		for _, u := range sr.UploadResults {
+			f := File{
+				Pipeline: result.Pipeline,
+				Marker:   result.Path,
+				Src:      u.Src,
+				Storage:  storageType,
+				Dest:     u.Dest,
+				Checksum: u.Checksum,
+				Size:     u.Size,
+			}
+
+			if u.Skipped {
+				f.Reason = ReasonUploaded
+				s.report.Skipped = append(s.report.Skipped, f)
+			} else {
+				s.report.Uploaded = append(s.report.Uploaded, f)
+			}
+		}
+	}
+
+	if result.Error != nil && !failed {
+		s.report.Failed = append(s.report.Failed, File{
+			Pipeline: result.Pipeline,
+			Marker:   result.Path,
+			Error:    result.Error.Error(),
+		})
+	}
+
+	return nil
+}
+
+// Report returns a copy of the summary with the files sorted by marker, source and storage.
+func (s *Summary) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.report
+	r.Uploaded = sortFiles(r.Uploaded)
+	r.Skipped = sortFiles(r.Skipped)
+	r.Failed = sortFiles(r.Failed)
+	return r
+}
+
+func sortFiles(files []File) []File {
+	if files == nil {
+		return nil
+	}
+
+	sorted := append([]File(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Marker != sorted[j].Marker {
+			return sorted[i].Marker < sorted[j].Marker
+		}
+		if sorted[i].Src != sorted[j].Src {
+			return sorted[i].Src < sorted[j].Src
+		}
+		return sorted[i].Storage < sorted[j].Storage
+	})
+	return sorted
+}
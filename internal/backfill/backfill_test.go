@@ -0,0 +1,93 @@
+package backfill
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	start := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	w := Window{Start: start, End: start.Add(24 * time.Hour)}
+
+	require.NoError(t, w.Validate())
+	require.True(t, w.Contains(start))
+	require.True(t, w.Contains(start.Add(23*time.Hour)))
+	require.False(t, w.Contains(start.Add(24*time.Hour)))
+	require.False(t, w.Contains(start.Add(-time.Nanosecond)))
+
+	require.Error(t, Window{Start: start, End: start}.Validate())
+}
+
+func TestValidateHostId(t *testing.T) {
+	for _, hostId := range []string{"0.0.3", "node1", "node_1-a"} {
+		require.NoError(t, ValidateHostId(hostId), hostId)
+	}
+
+	for _, hostId := range []string{"", ".", "..", "a/b", "node 1"} {
+		require.Error(t, ValidateHostId(hostId), hostId)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	summary := NewSummary("0.0.3", Window{Start: start, End: start.Add(time.Hour)})
+
+	inRange := filepath.Join(dir, "2025-05-20T00_30_00.000000001Z.rcd_sig")
+	outOfRange := filepath.Join(dir, "2025-05-19T23_59_59.999999999Z.rcd_sig")
+	noTimestamp := filepath.Join(dir, "000000000000000000000000000000000001.blk.gz.mf")
+	for _, file := range []string{inRange, outOfRange, noTimestamp} {
+		require.NoError(t, os.WriteFile(file, []byte("sig"), 0644))
+	}
+	require.NoError(t, os.Chtimes(noTimestamp, start.Add(10*time.Minute), start.Add(10*time.Minute)))
+
+	for _, tt := range []struct {
+		path     string
+		expected bool
+	}{
+		{inRange, true},
+		{outOfRange, false},
+		{noTimestamp, true},
+	} {
+		info, err := os.Stat(tt.path)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, summary.Filter(tt.path, info), tt.path)
+	}
+
+	require.NoError(t, summary.Record(core.ProcessorResult{
+		Path:     inRange,
+		Pipeline: "records",
+		Result: map[string]*core.StorageResult{
+			"S3": {UploadResults: []*core.UploadInfo{
+				{Src: inRange, Dest: "0.0.3/a.rcd_sig", Checksum: "aaa"},
+				{Src: "b.rcd", Dest: "0.0.3/b.rcd", Skipped: true},
+			}},
+			"GCS": {Error: errors.New("upload failed")},
+		},
+		Error: errors.New("failed to upload"),
+	}))
+	require.NoError(t, summary.Record(core.ProcessorResult{
+		Path:  noTimestamp,
+		Error: errors.New("marker not ready"),
+	}))
+
+	report := summary.Report()
+	require.Equal(t, "0.0.3", report.HostId)
+	require.Equal(t, 2, report.Markers)
+	require.Equal(t, []File{
+		{Pipeline: "records", Marker: inRange, Src: inRange, Storage: "S3", Dest: "0.0.3/a.rcd_sig", Checksum: "aaa"},
+	}, report.Uploaded)
+	require.Equal(t, []File{
+		{Marker: outOfRange, Reason: ReasonOutOfRange},
+		{Pipeline: "records", Marker: inRange, Src: "b.rcd", Storage: "S3", Dest: "0.0.3/b.rcd", Reason: ReasonUploaded},
+	}, report.Skipped)
+	require.Equal(t, []File{
+		{Marker: noTimestamp, Error: "marker not ready"},
+		{Pipeline: "records", Marker: inRange, Storage: "GCS", Error: "upload failed"},
+	}, report.Failed)
+}
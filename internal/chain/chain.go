@@ -0,0 +1,330 @@
+package chain
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modes of handling stream files that break the running-hash chain.
+const (
+	ModeFail = "fail" // reject the marker; its files are kept on disk
+	ModeFlag = "flag" // report the break and upload the marker anyway
+)
+
+// Link is the last verified stream file of a directory.
+type Link struct {
+	File      string    `json:"file"`
+	Timestamp time.Time `json:"timestamp"`
+	EndHash   string    `json:"endHash"` // hex encoded end running hash
+}
+
+// state is the content of the state file.
+type state struct {
+	Links     map[string]Link `json:"links"` // directory -> last verified stream file
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// BreakError reports a stream file whose start running hash is not the end running hash of the previous file.
+type BreakError struct {
+	File     string
+	Previous string
+	Expected string
+	Actual   string
+}
+
+func (e *BreakError) Error() string {
+	return fmt.Sprintf("running hash chain broken: start running hash of %s is %s, expected end running hash %s of %s",
+		e.File, e.Actual, e.Expected, e.Previous)
+}
+
+// Verifier verifies that the stream files of a directory form a running-hash chain, i.e. that the start running hash
+// of each file is the end running hash of the previous file.
+//
+// The files of a marker are verified before its upload, but the chain only advances once the upload has succeeded:
+// the verifier is a core.Recorder, and Record commits the verified files of successful markers and drops those of
+// failed ones, so that a failed marker is verified again when it is retried, also after a restart.
+//
+// Notes:
+//   - Record (.rcd, .rcd.gz) and event (.evts, .evts.gz) stream files are verified; sidecar files are not.
+//   - Files are verified against the newest verified file of their directory, committed or still being uploaded, so
+//     the markers of a directory must be verified in order: with ordered commit, or with a single processor.
+//   - The last committed file of each directory is persisted to the state file, if set, so that the chain is verified
+//     across restarts.
+//   - Files older than the last verified file of their directory cannot be verified and are accepted.
+//   - The first file of a directory without state is accepted and starts the chain.
+type Verifier struct {
+	pipeline  string
+	mode      string
+	stateFile string
+
+	mu      sync.Mutex
+	links   map[string]Link            // directory -> last committed stream file
+	pending map[string]map[string]Link // marker -> directory -> last verified stream file of the marker
+}
+
+// Mode returns how the verifier handles stream files that break the chain.
+func (v *Verifier) Mode() string {
+	return v.mode
+}
+
+// Verify verifies the stream files among the candidate files of a marker. The verified files are committed by Record
+// once the marker is uploaded.
+//
+// In fail mode, it returns an error if a file breaks the chain or its running hashes cannot be read, and none of the
+// files of the marker are kept. In flag mode, breaks are only reported and the chain continues from the new file.
+func (v *Verifier) Verify(marker string, candidates []string) error {
+	for _, candidate := range candidates {
+		readHashes := runningHashReader(candidate)
+		if readHashes == nil {
+			continue
+		}
+
+		ts, err := hedera.ParseConsensusTimestamp(candidate)
+		if err != nil {
+			logx.As().Debug().Str("path", candidate).Msg("Skipping chain verification of stream file without consensus timestamp")
+			continue
+		}
+
+		start, end, err := readRunningHashes(candidate, readHashes)
+		if err != nil {
+			err = fmt.Errorf("failed to read running hashes of %s: %w", candidate, err)
+			if v.mode == ModeFail {
+				v.drop(marker)
+				return err
+			}
+
+			logx.As().Warn().Err(err).Str("pipeline", v.pipeline).Msg("Skipping chain verification of stream file")
+			continue
+		}
+
+		if err := v.link(marker, candidate, ts, start, end); err != nil {
+			v.drop(marker)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Record commits the files verified for the marker of the result if it was uploaded, and drops them otherwise.
+func (v *Verifier) Record(result core.ProcessorResult) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	verified, ok := v.pending[result.Path]
+	if !ok {
+		return nil
+	}
+	delete(v.pending, result.Path)
+	if result.Error != nil {
+		return nil
+	}
+
+	for dir, link := range verified {
+		if prev, ok := v.links[dir]; !ok || link.Timestamp.After(prev.Timestamp) {
+			v.links[dir] = link
+		}
+	}
+
+	if err := v.saveLocked(); err != nil {
+		logx.As().Warn().Err(err).Str("pipeline", v.pipeline).Str("file", v.stateFile).Msg("Failed to save running hash chain state")
+	}
+
+	return nil
+}
+
+// drop forgets the files verified for the marker.
+func (v *Verifier) drop(marker string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.pending, marker)
+}
+
+// lastLocked returns the newest verified file of the directory, committed or pending.
+func (v *Verifier) lastLocked(dir string) (Link, bool) {
+	last, ok := v.links[dir]
+	for _, verified := range v.pending {
+		if link, found := verified[dir]; found && (!ok || link.Timestamp.After(last.Timestamp)) {
+			last, ok = link, true
+		}
+	}
+	return last, ok
+}
+
+// link verifies the stream file against the newest verified file of its directory and adds it to the files verified
+// for the marker.
+func (v *Verifier) link(marker string, path string, ts time.Time, start []byte, end []byte) error {
+	dir := filepath.Dir(path)
+	next := Link{File: filepath.Base(path), Timestamp: ts, EndHash: hex.EncodeToString(end)}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	prev, ok := v.lastLocked(dir)
+	if ok && !ts.After(prev.Timestamp) {
+		logx.As().Debug().
+			Str("pipeline", v.pipeline).
+			Str("path", path).
+			Str("last_verified", prev.File).
+			Msg("Skipping chain verification of stream file older than the last verified file")
+		return nil
+	}
+
+	if ok && prev.EndHash != hex.EncodeToString(start) {
+		breakErr := &BreakError{File: path, Previous: prev.File, Expected: prev.EndHash, Actual: hex.EncodeToString(start)}
+		metrics.ChainBreaks.WithLabelValues(v.pipeline).Inc()
+		logx.As().Error().
+			Err(breakErr).
+			Str("pipeline", v.pipeline).
+			Str("path", path).
+			Str("previous", prev.File).
+			Str("mode", v.mode).
+			Msg("Stream file breaks the running hash chain")
+
+		if v.mode == ModeFail {
+			return breakErr
+		}
+	}
+
+	verified, ok := v.pending[marker]
+	if !ok {
+		verified = make(map[string]Link)
+		v.pending[marker] = verified
+	}
+	verified[dir] = next
+
+	return nil
+}
+
+func (v *Verifier) saveLocked() error {
+	if v.stateFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state{Links: v.links, UpdatedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := fsx.WriteFileAtomic(v.stateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	return nil
+}
+
+// runningHashReader returns the function reading the running hashes of a stream file, or nil if the file is not a
+// record or event stream file.
+func runningHashReader(path string) func(r io.Reader) (*hedera.StreamHeader, []byte, error) {
+	name := strings.TrimSuffix(path, ".gz")
+	switch {
+	case hedera.IsSidecarFile(path):
+		return nil
+	case strings.HasSuffix(name, ".rcd"):
+		return hedera.ReadRecordStreamRunningHashes
+	case strings.HasSuffix(name, ".evts"):
+		return hedera.ReadEventStreamRunningHashes
+	default:
+		return nil
+	}
+}
+
+func readRunningHashes(path string, readHashes func(r io.Reader) (*hedera.StreamHeader, []byte, error)) ([]byte, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gzip header: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	header, end, err := readHashes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header.StartRunningHash, end, nil
+}
+
+// loadState reads the state file if it exists.
+func loadState(file string) (map[string]Link, error) {
+	links := make(map[string]Link)
+	if file == "" {
+		return links, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return links, nil
+		}
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("invalid state %s: %w", file, err)
+	}
+
+	if saved.Links != nil {
+		links = saved.Links
+	}
+
+	return links, nil
+}
+
+// NewVerifier creates a running-hash chain verifier for the stream files of the pipeline.
+//
+// Parameters:
+//   - pipeline: The name of the pipeline, used in logs and as metrics label.
+//   - mode: ModeFail or ModeFlag. Default is ModeFail.
+//   - stateFile: The file persisting the last verified file of each directory; the state is kept in memory if empty.
+func NewVerifier(pipeline string, mode string, stateFile string) (*Verifier, error) {
+	if mode == "" {
+		mode = ModeFail
+	}
+	if mode != ModeFail && mode != ModeFlag {
+		return nil, fmt.Errorf("invalid chain verification mode '%s', expected %s or %s", mode, ModeFail, ModeFlag)
+	}
+
+	links, err := loadState(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logx.As().Info().
+		Str("pipeline", pipeline).
+		Str("mode", mode).
+		Str("state_file", stateFile).
+		Int("directories", len(links)).
+		Msg("Running hash chain verification enabled")
+
+	return &Verifier{
+		pipeline:  pipeline,
+		mode:      mode,
+		stateFile: stateFile,
+		links:     links,
+		pending:   make(map[string]map[string]Link),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package chain
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRecordFile writes a gzip compressed version 5 record file with the running hashes.
+func writeRecordFile(t *testing.T, dir string, name string, start []byte, end []byte) string {
+	data := hederatest.RecordFileV5(start, end)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+// upload verifies the files of a marker and records the result of its upload.
+func upload(v *Verifier, marker string, uploadErr error, files ...string) error {
+	if err := v.Verify(marker, files); err != nil {
+		_ = v.Record(core.ProcessorResult{Path: marker, Error: err})
+		return err
+	}
+	return v.Record(core.ProcessorResult{Path: marker, Error: uploadErr})
+}
+
+func TestVerifier(t *testing.T) {
+	tempDir := t.TempDir()
+	node1 := filepath.Join(tempDir, "node1")
+	stateFile := filepath.Join(tempDir, "chain.json")
+
+	f1 := writeRecordFile(t, node1, "2024-01-01T00_00_00.000000000Z.rcd.gz", hederatest.Hash(0), hederatest.Hash(1))
+	f2 := writeRecordFile(t, node1, "2024-01-01T00_00_02.000000000Z.rcd.gz", hederatest.Hash(1), hederatest.Hash(2))
+	f3 := writeRecordFile(t, node1, "2024-01-01T00_00_04.000000000Z.rcd.gz", hederatest.Hash(9), hederatest.Hash(3)) // swapped file
+	sig := filepath.Join(node1, "2024-01-01T00_00_02.000000000Z.rcd_sig")
+	require.NoError(t, os.WriteFile(sig, []byte("signature"), 0644))
+
+	pipeline := "test-chain-verifier"
+	breaks := testutil.ToFloat64(metrics.ChainBreaks.WithLabelValues(pipeline))
+
+	v, err := NewVerifier(pipeline, "", stateFile)
+	require.NoError(t, err)
+	require.Equal(t, ModeFail, v.Mode())
+
+	require.NoError(t, upload(v, "m1", nil, f1))
+	require.NoError(t, upload(v, "m2", nil, f2, sig))
+
+	err = upload(v, "m3", nil, f3)
+	var breakErr *BreakError
+	require.ErrorAs(t, err, &breakErr)
+	require.Equal(t, f3, breakErr.File)
+	require.Equal(t, filepath.Base(f2), breakErr.Previous)
+	require.Equal(t, hex.EncodeToString(hederatest.Hash(2)), breakErr.Expected)
+	require.Equal(t, breaks+1, testutil.ToFloat64(metrics.ChainBreaks.WithLabelValues(pipeline)))
+	require.Empty(t, v.pending)
+
+	// older files cannot be verified and are accepted
+	require.NoError(t, upload(v, "m1", nil, f1))
+
+	// the chain is restored from the state file after a restart
+	v, err = NewVerifier(pipeline, ModeFail, stateFile)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Base(f2), v.links[node1].File)
+	require.Error(t, upload(v, "m3", nil, f3))
+
+	f3 = writeRecordFile(t, node1, "2024-01-01T00_00_04.000000000Z.rcd.gz", hederatest.Hash(2), hederatest.Hash(3))
+	require.NoError(t, upload(v, "m3", nil, f3))
+	require.Equal(t, hex.EncodeToString(hederatest.Hash(3)), v.links[node1].EndHash)
+}
+
+func TestVerifier_FailedUpload(t *testing.T) {
+	tempDir := t.TempDir()
+	stateFile := filepath.Join(tempDir, "chain.json")
+	f1 := writeRecordFile(t, tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz", hederatest.Hash(0), hederatest.Hash(1))
+	f2 := writeRecordFile(t, tempDir, "2024-01-01T00_00_02.000000000Z.rcd.gz", hederatest.Hash(1), hederatest.Hash(2))
+	f3 := writeRecordFile(t, tempDir, "2024-01-01T00_00_04.000000000Z.rcd.gz", hederatest.Hash(2), hederatest.Hash(3))
+
+	v, err := NewVerifier("test-chain-verifier-upload", ModeFail, stateFile)
+	require.NoError(t, err)
+	require.NoError(t, upload(v, "m1", nil, f1))
+
+	// files of markers still being uploaded are the tip of the chain
+	require.NoError(t, v.Verify("m2", []string{f2}))
+	require.NoError(t, v.Verify("m3", []string{f3}))
+	require.NoError(t, v.Record(core.ProcessorResult{Path: "m3", Error: errors.New("upload failed")}))
+	require.NoError(t, v.Record(core.ProcessorResult{Path: "m2", Error: errors.New("upload failed")}))
+
+	// failed uploads do not advance the chain, so a retried marker is verified again
+	require.Equal(t, filepath.Base(f1), v.links[tempDir].File)
+	writeRecordFile(t, tempDir, filepath.Base(f2), hederatest.Hash(9), hederatest.Hash(2)) // swapped before the retry
+	require.Error(t, upload(v, "m2", nil, f2))
+
+	// also after a restart
+	v, err = NewVerifier("test-chain-verifier-upload", ModeFail, stateFile)
+	require.NoError(t, err)
+	require.Error(t, upload(v, "m2", nil, f2))
+}
+
+func TestVerifier_FlagMode(t *testing.T) {
+	tempDir := t.TempDir()
+	f1 := writeRecordFile(t, tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz", hederatest.Hash(0), hederatest.Hash(1))
+	f2 := writeRecordFile(t, tempDir, "2024-01-01T00_00_02.000000000Z.rcd.gz", hederatest.Hash(9), hederatest.Hash(2))
+	f3 := writeRecordFile(t, tempDir, "2024-01-01T00_00_04.000000000Z.rcd.gz", hederatest.Hash(2), hederatest.Hash(3))
+	truncated := filepath.Join(tempDir, "2024-01-01T00_00_06.000000000Z.rcd.gz")
+	require.NoError(t, os.WriteFile(truncated, []byte("truncated"), 0644))
+
+	v, err := NewVerifier("test-chain-verifier-flag", ModeFlag, "")
+	require.NoError(t, err)
+
+	// breaks are reported and the chain continues from the new file
+	require.NoError(t, upload(v, "m1", nil, f1))
+	require.NoError(t, upload(v, "m2", nil, f2))
+	require.NoError(t, upload(v, "m3", nil, f3))
+	require.NoError(t, upload(v, "m4", nil, truncated))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.ChainBreaks.WithLabelValues("test-chain-verifier-flag")))
+
+	v, err = NewVerifier("test-chain-verifier-fail", ModeFail, "")
+	require.NoError(t, err)
+	require.ErrorContains(t, v.Verify("m4", []string{truncated}), "failed to read running hashes")
+
+	_, err = NewVerifier("test", "strict", "")
+	require.ErrorContains(t, err, "invalid chain verification mode")
+
+	stateFile := filepath.Join(tempDir, "invalid.json")
+	require.NoError(t, os.WriteFile(stateFile, []byte("{"), 0644))
+	_, err = NewVerifier("test", ModeFail, stateFile)
+	require.ErrorContains(t, err, "invalid state")
+}
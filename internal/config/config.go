@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"golang.hedera.com/solo-cheetah/pkg/sniff"
 	"os"
@@ -18,6 +21,12 @@ type Config struct {
 	Pipelines []*PipelineConfig
 	// Stats contains the statistics configuration.
 	Profiling *sniff.ProfilingConfig
+	// Metrics contains the Prometheus metrics endpoint configuration.
+	Metrics *metrics.MetricsConfig
+	// Tracing contains the OpenTelemetry tracing configuration.
+	Tracing *tracing.TracingConfig
+	// Journal contains the upload journal configuration.
+	Journal *journal.JournalConfig
 }
 
 // PipelineConfig holds the configuration for a single pipeline.
@@ -33,18 +42,88 @@ type PipelineConfig struct {
 	Processor *ProcessorConfig
 	// StopOnError indicates whether to stop the pipeline on error. We can ignore errors with the hope that the next run will succeed.
 	StopOnError bool
+	// GapDetection contains the configuration for detecting missing stream files. Disabled if not set.
+	GapDetection *GapDetectionConfig
+	// OrphanSweep contains the configuration for finding files left in the scanner directory. Disabled if not set.
+	OrphanSweep *OrphanSweepConfig
+}
+
+// GapDetectionConfig holds the configuration for detecting gaps between the consensus timestamps of uploaded marker
+// files.
+type GapDetectionConfig struct {
+	// Enabled indicates whether gaps are detected.
+	Enabled bool
+	// MaxGap is the largest expected period between two consecutive marker files of a directory (e.g., "10s").
+	MaxGap string
+}
+
+// OrphanSweepConfig holds the configuration for periodically finding candidate files without a marker file and marker
+// files that are never uploaded.
+type OrphanSweepConfig struct {
+	// Enabled indicates whether the scanner directory is swept for orphaned files.
+	Enabled bool
+	// Interval is the delay between two sweeps (e.g., "5m").
+	Interval string
+	// MinAge is how long a file must be unmodified before it is reported as orphaned (e.g., "1h").
+	MinAge string
+	// Patterns is a list of glob patterns, relative to the directory of the marker files, of the candidate files.
+	// Defaults to the patterns of the basic, glob and sequential file matchers.
+	Patterns []string
+	// Action is what is done with orphaned candidate files: report (default), upload under the orphans prefix of the
+	// storages, or move to HoldingDir.
+	Action string
+	// HoldingDir is the directory orphaned candidate files are moved to when Action is move.
+	HoldingDir string
 }
 
 // ScannerConfig holds the configuration for the scanner.
 type ScannerConfig struct {
 	// Directory is the directory to scan.
 	Directory string
-	// Pattern is the file extension pattern to match.
+	// Pattern is the file extension of the marker files to match (e.g., ".rcd_sig").
 	Pattern string
+	// Markers is a list of additional marker patterns, matched in order after Pattern during the same scan.
+	Markers []MarkerConfig
 	// Interval specifies the scan interval (e.g., "5m").
 	Interval string
 	// BatchSize is the number of files to process in a batch.
 	BatchSize int
+	// Ordering is the order in which marker files are queued for processing: oldest, newest or size (smallest first).
+	// The time of a marker is the consensus timestamp in its file name, or its modification time. Empty keeps the scan order.
+	Ordering string
+	// QueueSize is the maximum number of marker files held by the priority queue when Ordering is set. Default is 1000.
+	QueueSize int
+	// Mode is how the scanner finds files ready for processing: marker (default) queues the marker files matching
+	// Pattern or Markers, quiescence queues the files matching the include patterns of Quiescence once they stopped
+	// changing.
+	Mode string
+	// Quiescence contains the configuration of the quiescence mode.
+	Quiescence *QuiescenceConfig
+}
+
+// MarkerConfig holds the configuration of a marker pattern of the scanner.
+type MarkerConfig struct {
+	// Type is how Pattern is matched: extension (default) against the end of the file name (e.g., ".rcd.gz"), glob or
+	// regex against the path relative to the scanner directory (e.g., "**/*_sig.json").
+	Type string
+	// Pattern is the marker pattern to match.
+	Pattern string
+	// FileMatcherConfigs is a list of file matcher config for the markers of this pattern. Defaults to the file
+	// matchers of the processor.
+	FileMatcherConfigs []FileMatcherConfig
+}
+
+// QuiescenceConfig holds the configuration for finding files without marker files, once they are no longer written.
+type QuiescenceConfig struct {
+	// Include is a list of glob patterns, relative to the scanner directory, of the files to process (e.g., "**/*.log").
+	Include []string
+	// Exclude is a list of glob patterns, relative to the scanner directory, of the files to skip.
+	Exclude []string
+	// StablePeriod is how long the size and modification time of a file must be unchanged before it is processed
+	// (e.g., "30s"). Default is 30s.
+	StablePeriod string
+	// SkipOpenCheck disables the check that no process has the file open for writing.
+	SkipOpenCheck bool
 }
 
 // ProcessorConfig holds the configuration for the processor.
@@ -63,6 +142,55 @@ type ProcessorConfig struct {
 	MarkerCheckConfig *MarkerCheckConfig
 	// FileMatcherConfigs is a list of file matcher config to apply to find files to be processed for a marker file
 	FileMatcherConfigs []FileMatcherConfig
+	// Completeness contains the configuration for deferring markers whose required candidate files do not exist yet.
+	Completeness *CompletenessConfig
+	// OrderedCommit contains the configuration for publishing marker files in timestamp order. Disabled if not set.
+	OrderedCommit *OrderedCommitConfig
+	// Validators is a list of validators to apply to the candidate files of a marker file before uploading them.
+	Validators []ValidatorConfig
+	// ChainVerification contains the configuration for verifying the running hash chain of stream files. Disabled if not set.
+	ChainVerification *ChainVerificationConfig
+	// SignatureVerification contains the configuration for verifying signature files against the node public keys. Disabled if not set.
+	SignatureVerification *SignatureVerificationConfig
+}
+
+// ChainVerificationConfig holds the configuration for verifying that the start running hash of each stream file is the
+// end running hash of the previous file of its directory.
+type ChainVerificationConfig struct {
+	// Enabled indicates whether the running hash chain is verified before upload.
+	Enabled bool
+	// Mode is fail to reject the markers breaking the chain, or flag to only report them. Default is fail.
+	Mode string
+	// StateFile is the file persisting the last verified file of each directory across restarts.
+	StateFile string
+}
+
+// SignatureVerificationConfig holds the configuration for verifying that a signature file marker is a valid signature
+// of its node over the stream file before they are uploaded.
+type SignatureVerificationConfig struct {
+	// Enabled indicates whether signature files are verified before upload.
+	Enabled bool
+	// KeyDir is the directory of the PEM files of the node public keys or certificates, named after the node account IDs (e.g., node0.0.3.pem).
+	KeyDir string
+	// AddressBook is an address book file (serialized NodeAddressBook) holding the node public keys.
+	AddressBook string
+}
+
+// OrderedCommitConfig holds the configuration for publishing marker files in timestamp order per directory.
+// Data files are uploaded in parallel, but a marker file is only uploaded once the older markers of its directory
+// have been processed. It requires the default or oldest scanner ordering.
+type OrderedCommitConfig struct {
+	// Enabled indicates whether marker files are published in order.
+	Enabled bool
+	// HeadOfLineTimeout is how long a marker waits for older markers before it is published anyway (e.g., "5m").
+	HeadOfLineTimeout string
+}
+
+// CompletenessConfig holds the configuration for deferring a marker file until the candidate files required by the
+// file matchers exist.
+type CompletenessConfig struct {
+	// Deadline is how long after a marker is first found incomplete it is reported as incomplete (e.g., "1m").
+	Deadline string
 }
 
 type MarkerCheckConfig struct {
@@ -124,6 +252,45 @@ type FileMatcherConfig struct {
 	MatcherType string
 	// Patterns is a list of file patterns to process when a marker file is found.
 	Patterns []string
+	// Requirement declares the files each pattern must match before the marker is uploaded: optional (default),
+	// required (at least one file) or atLeast (at least MinMatches files).
+	Requirement string
+	// MinMatches is the minimum number of files each pattern must match when Requirement is atLeast.
+	MinMatches int
+	// Directories is a list of directories, relative to the marker's directory, searched by the regex matcher.
+	// Defaults to the marker's directory.
+	Directories []string
+	// MaxSequence is the largest sequence number captured by the seq group of a regex pattern; unlimited if 0.
+	MaxSequence int
+	// RootDir is the directory the files listed in a manifest must be within. Defaults to the scanner directory.
+	RootDir string
+	// MarkerExt is the extension of the marker files the configuration applies to, stripped from the marker name in
+	// patterns, e.g. .rcd.gz. Set from the extension marker patterns; the last extension is stripped if empty.
+	MarkerExt string
+	// Sequence configures how the sequential matcher numbers the files of each pattern. Defaults to numbers from 1,
+	// stopping at the first missing number.
+	Sequence *SequenceConfig
+}
+
+// SequenceConfig holds the numbering of the files matched by the # placeholders of a sequential pattern.
+type SequenceConfig struct {
+	// Start is the first sequence number. Default is 1.
+	Start *int
+	// MaxCount is the maximum number of files matched by each pattern. Default is 0, meaning unlimited.
+	MaxCount int
+	// MaxGap is the number of consecutive missing numbers skipped before the sequence ends. Default is 0.
+	MaxGap int
+	// OnGap is what to do when files are numbered after the end of the sequence: stop (default) to report them as
+	// orphans and match the files before the gap, or defer to treat the marker as incomplete until the gap is filled.
+	OnGap string
+}
+
+// ValidatorConfig holds the configuration of a validator of the candidate files of a marker file.
+type ValidatorConfig struct {
+	// ValidatorType is the type of the validator (e.g., gzip, recordStream or eventStream).
+	ValidatorType string
+	// Patterns is a list of file name suffixes to validate (e.g., ".rcd.gz"). Defaults to the suffixes of the validator.
+	Patterns []string
 }
 
 var config = Config{
@@ -136,6 +303,15 @@ var config = Config{
 	Profiling: &sniff.ProfilingConfig{
 		Enabled: false,
 	},
+	Metrics: &metrics.MetricsConfig{
+		Enabled: false,
+	},
+	Tracing: &tracing.TracingConfig{
+		Enabled: false,
+	},
+	Journal: &journal.JournalConfig{
+		Enabled: false,
+	},
 }
 
 // Initialize loads the configuration from the specified file.
@@ -178,6 +354,18 @@ func initializeNestedStructs() {
 		config.Profiling = &sniff.ProfilingConfig{Enabled: false}
 	}
 
+	if config.Metrics == nil {
+		config.Metrics = &metrics.MetricsConfig{Enabled: false}
+	}
+
+	if config.Tracing == nil {
+		config.Tracing = &tracing.TracingConfig{Enabled: false}
+	}
+
+	if config.Journal == nil {
+		config.Journal = &journal.JournalConfig{Enabled: false}
+	}
+
 	for _, pipeline := range config.Pipelines {
 		if pipeline.Scanner == nil {
 			pipeline.Scanner = &ScannerConfig{}
@@ -194,6 +382,27 @@ func initializeNestedStructs() {
 			}
 		}
 
+		// without marker files, the ready file is the file to upload unless matchers are configured
+		if pipeline.Scanner.Mode == "quiescence" && len(pipeline.Processor.FileMatcherConfigs) == 0 {
+			pipeline.Processor.FileMatcherConfigs = []FileMatcherConfig{
+				{MatcherType: "glob", Patterns: []string{"{{.markerFile}}"}},
+			}
+		}
+
+		for i := range pipeline.Processor.FileMatcherConfigs {
+			if pipeline.Processor.FileMatcherConfigs[i].RootDir == "" {
+				pipeline.Processor.FileMatcherConfigs[i].RootDir = pipeline.Scanner.Directory
+			}
+		}
+
+		for i := range pipeline.Scanner.Markers {
+			for j := range pipeline.Scanner.Markers[i].FileMatcherConfigs {
+				if pipeline.Scanner.Markers[i].FileMatcherConfigs[j].RootDir == "" {
+					pipeline.Scanner.Markers[i].FileMatcherConfigs[j].RootDir = pipeline.Scanner.Directory
+				}
+			}
+		}
+
 		if pipeline.Processor.Storage == nil {
 			pipeline.Processor.Storage = &StorageConfig{}
 		}
@@ -15,6 +15,15 @@ func TestInitialize(t *testing.T) {
 	err := os.WriteFile(configFile, []byte(`
 log:
   level: "Debug"
+metrics:
+  enabled: true
+  serverHost: "127.0.0.1"
+  serverPort: 9090
+journal:
+  enabled: true
+  directory: "/test/journal"
+  maxSize: 10
+  maxBackups: 5
 pipelines:
   - name: "TestPipeline"
     enabled: true
@@ -37,6 +46,9 @@ pipelines:
           patterns: [".txt", ".log"]
         - matcherType: sidecar 
           patterns: [".gz", ".log"]
+          sequence:
+            start: 0
+            onGap: defer
       storage:
         s3:
           enabled: false 
@@ -68,6 +80,13 @@ pipelines:
 	err = Initialize(configFile)
 	require.NoError(t, err)
 	require.Equal(t, "Debug", config.Log.Level)
+	require.Equal(t, true, config.Metrics.Enabled)
+	require.Equal(t, "127.0.0.1", config.Metrics.ServerHost)
+	require.Equal(t, 9090, config.Metrics.ServerPort)
+	require.Equal(t, true, config.Journal.Enabled)
+	require.Equal(t, "/test/journal", config.Journal.Directory)
+	require.Equal(t, int64(10), config.Journal.MaxSize)
+	require.Equal(t, 5, config.Journal.MaxBackups)
 	require.Equal(t, 2, len(config.Pipelines))
 	require.Equal(t, "TestPipeline", config.Pipelines[0].Name)
 	require.Equal(t, "/test/dir", config.Pipelines[0].Scanner.Directory)
@@ -79,6 +98,10 @@ pipelines:
 	require.Equal(t, true, config.Pipelines[0].Enabled)
 	require.Equal(t, false, config.Pipelines[1].Enabled)
 	require.ElementsMatch(t, []string{".txt", ".log"}, config.Pipelines[0].Processor.FileMatcherConfigs[0].Patterns)
+	require.Nil(t, config.Pipelines[0].Processor.FileMatcherConfigs[0].Sequence)
+	require.NotNil(t, config.Pipelines[0].Processor.FileMatcherConfigs[1].Sequence.Start)
+	require.Equal(t, 0, *config.Pipelines[0].Processor.FileMatcherConfigs[1].Sequence.Start)
+	require.Equal(t, "defer", config.Pipelines[0].Processor.FileMatcherConfigs[1].Sequence.OnGap)
 
 	_ = os.Setenv("S3_BUCKET", "bucket")
 	_ = os.Setenv("S3_BUCKET_PREFIX", "bucket-prefix")
@@ -149,3 +172,59 @@ func TestSet(t *testing.T) {
 	require.Equal(t, 1, len(config.Pipelines))
 	require.Equal(t, "NewPipeline", config.Pipelines[0].Name)
 }
+
+func TestInitialize_Quiescence(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+pipelines:
+  - name: "LogPipeline"
+    enabled: true
+    scanner:
+      directory: "/test/logs"
+      mode: quiescence
+      quiescence:
+        include: ["**/*.log"]
+        stablePeriod: 1m
+`), 0644))
+
+	require.NoError(t, Initialize(configFile))
+	pipeline := config.Pipelines[0]
+	require.Equal(t, "quiescence", pipeline.Scanner.Mode)
+	require.Equal(t, []string{"**/*.log"}, pipeline.Scanner.Quiescence.Include)
+	require.Equal(t, "1m", pipeline.Scanner.Quiescence.StablePeriod)
+	require.False(t, pipeline.Scanner.Quiescence.SkipOpenCheck)
+
+	// the ready file is uploaded itself
+	require.Equal(t, []FileMatcherConfig{
+		{MatcherType: "glob", Patterns: []string{"{{.markerFile}}"}, RootDir: "/test/logs"},
+	}, pipeline.Processor.FileMatcherConfigs)
+}
+
+func TestInitialize_Markers(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+pipelines:
+  - name: "StreamPipeline"
+    enabled: true
+    scanner:
+      directory: "/test/streams"
+      pattern: ".rcd_sig"
+      markers:
+        - pattern: ".evts_sig"
+        - type: glob
+          pattern: "**/balance*/*_sig.json"
+          fileMatcherConfigs:
+            - matcherType: "glob"
+              patterns: ["{{.markerName}}.json"]
+`), 0644))
+
+	require.NoError(t, Initialize(configFile))
+	pipeline := config.Pipelines[0]
+	require.Equal(t, ".rcd_sig", pipeline.Scanner.Pattern)
+	require.Equal(t, []MarkerConfig{
+		{Pattern: ".evts_sig"},
+		{Type: "glob", Pattern: "**/balance*/*_sig.json", FileMatcherConfigs: []FileMatcherConfig{
+			{MatcherType: "glob", Patterns: []string{"{{.markerName}}.json"}, RootDir: "/test/streams"},
+		}},
+	}, pipeline.Scanner.Markers)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"path"
+	"path/filepath"
+)
+
+// Namespace returns a copy of the pipeline configuration whose storages store objects under the namespace, i.e. the
+// namespace is appended to the bucket prefixes and to the path of the local directory storage. It is used to keep the
+// objects of a backfill under its host ID and the files of the orphan sweep under their own prefix.
+func Namespace(pc *PipelineConfig, namespace string) *PipelineConfig {
+	namespaced := *pc
+	processor := *pc.Processor
+	storage := *pc.Processor.Storage
+
+	if storage.S3 != nil {
+		s3 := *storage.S3
+		s3.Prefix = path.Join(s3.Prefix, namespace)
+		storage.S3 = &s3
+	}
+
+	if storage.GCS != nil {
+		gcs := *storage.GCS
+		gcs.Prefix = path.Join(gcs.Prefix, namespace)
+		storage.GCS = &gcs
+	}
+
+	if storage.LocalDir != nil {
+		localDir := *storage.LocalDir
+		localDir.Path = filepath.Join(localDir.Path, namespace)
+		storage.LocalDir = &localDir
+	}
+
+	processor.Storage = &storage
+	namespaced.Processor = &processor
+	return &namespaced
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespace(t *testing.T) {
+	pc := &PipelineConfig{
+		Name: "records",
+		Processor: &ProcessorConfig{
+			Storage: &StorageConfig{
+				S3:       &BucketConfig{Enabled: true, Prefix: "streams"},
+				GCS:      &BucketConfig{Enabled: true},
+				LocalDir: &LocalDirConfig{Enabled: true, Path: "/backup"},
+			},
+		},
+	}
+
+	namespaced := Namespace(pc, "0.0.3")
+	require.Equal(t, "streams/0.0.3", namespaced.Processor.Storage.S3.Prefix)
+	require.Equal(t, "0.0.3", namespaced.Processor.Storage.GCS.Prefix)
+	require.Equal(t, filepath.Join("/backup", "0.0.3"), namespaced.Processor.Storage.LocalDir.Path)
+
+	// the original configuration is unchanged
+	require.Equal(t, "streams", pc.Processor.Storage.S3.Prefix)
+	require.Equal(t, "", pc.Processor.Storage.GCS.Prefix)
+	require.Equal(t, "/backup", pc.Processor.Storage.LocalDir.Path)
+}
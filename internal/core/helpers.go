@@ -2,8 +2,10 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -28,6 +30,52 @@ func ComputeDestinationBucketPath(rootDir string, srcFile string, bucketPathPref
 	return filepath.Clean(dest)
 }
 
+// ComputeSourcePath computes the local path of an object key under the root directory. It reverses
+// ComputeDestinationBucketPath; the key must be under the bucket path prefix and must not escape the root directory.
+func ComputeSourcePath(rootDir string, key string, bucketPathPrefix string) (string, error) {
+	rel, err := RelativeKey(key, bucketPathPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(rootDir, filepath.FromSlash(rel)), nil
+}
+
+// RelativeKey returns the part of an object key under the bucket path prefix, e.g. "record0.0.3/file.rcd.gz" for the
+// key "streams/record0.0.3/file.rcd.gz" and the prefix "streams".
+func RelativeKey(key string, bucketPathPrefix string) (string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	prefix := strings.Trim(path.Clean("/"+bucketPathPrefix), "/")
+
+	rel := key
+	if prefix != "" {
+		if !strings.HasPrefix(key, prefix+"/") {
+			return "", fmt.Errorf("key %s is not under the path prefix %s", key, prefix)
+		}
+		rel = strings.TrimPrefix(key, prefix+"/")
+	}
+
+	if rel == "" {
+		return "", fmt.Errorf("key %s has no file name", key)
+	}
+
+	return rel, nil
+}
+
+// ComputeListPrefix combines the bucket path prefix with a prefix relative to it into the key prefix to list.
+// It returns an empty string if the prefix is empty, which lists every object under the bucket path prefix.
+func ComputeListPrefix(bucketPathPrefix string, prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	p := strings.TrimPrefix(path.Join(bucketPathPrefix, prefix), "/")
+	if strings.HasSuffix(prefix, "/") {
+		p += "/"
+	}
+	return p
+}
+
 // ApplyDelay applies a delay to the execution of the current context.
 func ApplyDelay(ctx context.Context, delay time.Duration) {
 	if delay > 0 {
@@ -42,3 +90,22 @@ func ApplyDelay(ctx context.Context, delay time.Duration) {
 		}
 	}
 }
+
+// ListAll lists all the objects with the prefix by fetching every page from the storage.
+func ListAll(ctx context.Context, s Storage, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	opts := ListOptions{Prefix: prefix}
+	for {
+		page, err := s.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, page.Objects...)
+		if !page.Truncated {
+			return objects, nil
+		}
+
+		opts.StartAfter = page.NextStartAfter
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -86,3 +87,97 @@ func TestComputeDestinationBucketPath(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeSourcePath(t *testing.T) {
+	tests := []struct {
+		name             string
+		rootDir          string
+		key              string
+		bucketPathPrefix string
+		expected         string
+		wantErr          bool
+	}{
+		{
+			name:             "file under prefix",
+			rootDir:          "/restore",
+			key:              "bucket/subdir/file.txt",
+			bucketPathPrefix: "bucket",
+			expected:         "/restore/subdir/file.txt",
+		},
+		{
+			name:             "prefix with slashes",
+			rootDir:          "/restore",
+			key:              "/streams/records/file.rcd.gz",
+			bucketPathPrefix: "/streams/records/",
+			expected:         "/restore/file.rcd.gz",
+		},
+		{
+			name:     "no prefix",
+			rootDir:  "/restore",
+			key:      "subdir/file.txt",
+			expected: "/restore/subdir/file.txt",
+		},
+		{
+			name:             "key outside prefix",
+			rootDir:          "/restore",
+			key:              "other/file.txt",
+			bucketPathPrefix: "bucket",
+			wantErr:          true,
+		},
+		{
+			name:             "key sharing the prefix name",
+			rootDir:          "/restore",
+			key:              "bucket-old/file.txt",
+			bucketPathPrefix: "bucket",
+			wantErr:          true,
+		},
+		{
+			name:     "key escaping root directory",
+			rootDir:  "/restore",
+			key:      "../../etc/passwd",
+			expected: "/restore/etc/passwd",
+		},
+		{
+			name:             "prefix only",
+			rootDir:          "/restore",
+			key:              "bucket/",
+			bucketPathPrefix: "bucket",
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ComputeSourcePath(tt.rootDir, tt.key, tt.bucketPathPrefix)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+
+			if !strings.Contains(tt.key, "..") {
+				// round trip
+				assert.Equal(t, strings.Trim(tt.key, "/"), strings.Trim(ComputeDestinationBucketPath(tt.rootDir, result, tt.bucketPathPrefix), "/"))
+			}
+		})
+	}
+}
+
+func TestComputeListPrefix(t *testing.T) {
+	tests := []struct {
+		bucketPathPrefix string
+		prefix           string
+		expected         string
+	}{
+		{"", "", ""},
+		{"bucket-prefix", "", ""},
+		{"", "rec/", "rec/"},
+		{"bucket-prefix", "rec/", "bucket-prefix/rec/"},
+		{"/bucket-prefix", "rec/2025", "bucket-prefix/rec/2025"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ComputeListPrefix(tt.bucketPathPrefix, tt.prefix))
+	}
+}
@@ -2,10 +2,29 @@ package core
 
 import (
 	"context"
+	"errors"
 	"os"
 	"time"
 )
 
+// ErrObjectNotFound is returned by a Storage if the object doesn't exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrNotSupported is returned by a Storage for an operation that the backend cannot support.
+var ErrNotSupported = errors.New("operation not supported")
+
+// Capability is an optional operation of a Storage. Use Storage.Supports to detect it before calling the operation.
+type Capability string
+
+// Optional storage operations.
+const (
+	CapabilityStat   Capability = "stat"
+	CapabilityList   Capability = "list"
+	CapabilityGet    Capability = "get"
+	CapabilityDelete Capability = "delete"
+	CapabilityCopy   Capability = "copy"
+)
+
 // Scanner defines the interface for a file scanning component.
 //
 // Methods:
@@ -27,13 +46,18 @@ type Scanner interface {
 // Fields:
 //   - Path: The path of the file that was found during scan(e.g. marker file).
 //   - Info: The file information (os.FileInfo) associated with the scanned file.
+//   - Marker: The marker pattern the file matched; empty if the scanner does not look for marker files.
+//   - Metadata: The metadata stored with the uploaded files, e.g. of the source object when copying objects between
+//     storages; nil for the defaults of the storage.
 //
 // Notes:
 //   - This struct is used to communicate the details of a matched file during scan.
 type ScannerResult struct {
-	Path    string
-	TraceId string // Unique identifier for tracing the file processing
-	Info    os.FileInfo
+	Path     string
+	TraceId  string // Unique identifier for tracing the file processing
+	Info     os.FileInfo
+	Marker   string
+	Metadata *ObjectMetadata
 }
 
 // Processor defines the interface for a file processing pipeline.
@@ -57,6 +81,8 @@ type Processor interface {
 // Fields:
 //   - Error: An error encountered during the processing of the file, if any.
 //   - Path: The path of the file being processed.
+//   - TraceId: The trace ID of the marker file.
+//   - Pipeline: The name of the pipeline that processed the file.
 //   - Result: A map where the key is the storage type (e.g., "S3", "Local") and the value is a pointer to the corresponding StorageResult.
 //
 // Notes:
@@ -64,10 +90,25 @@ type Processor interface {
 //   - The Result map contains the outcomes of the storage operations for the file across different storage handlers.
 //   - This struct is used to communicate the overall outcome of the processing operation for a single file.
 type ProcessorResult struct {
-	Error   error
-	Path    string
-	TraceId string
-	Result  map[string]*StorageResult
+	Error    error
+	Path     string
+	TraceId  string
+	Pipeline string
+	Result   map[string]*StorageResult
+}
+
+// Recorder defines the interface for a component that keeps a record of processed marker files (e.g. upload journal).
+//
+// Methods:
+//   - Record: Records the result of processing a marker file. It is called for both successful and failed results,
+//     before the local copies of the uploaded files are removed.
+//
+// Notes:
+//   - Implementations must be safe for concurrent use since multiple processors may share the same recorder.
+//   - If Record returns an error for a successful result, the processor keeps the local files so that the upload
+//     is not lost from the record.
+type Recorder interface {
+	Record(result ProcessorResult) error
 }
 
 // Storage defines the interface for a storage handler that manages file storage operations.
@@ -76,14 +117,62 @@ type ProcessorResult struct {
 //   - Info: Returns a unique identifier or description of the storage handler.
 //   - Type: Returns the type of storage (e.g., "S3", "Local").
 //   - Put: Handles the storage of a file, taking a ScannerResult as input and sending the result to a channel.
+//   - Upload: Uploads a local file to the key, e.g. a downloaded copy of an object, instead of the key that Put derives
+//     from the path of the file.
+//   - Supports: Returns whether the storage supports an optional operation (Stat, List, Get or Delete).
+//   - Key: Returns the key that a local file is stored as, relative to the storage root (e.g. bucket).
+//   - Stat: Returns the object stored with the key, or ErrObjectNotFound if it doesn't exist.
+//   - List: Returns a page of the objects whose key starts with the prefix, ordered by key.
+//   - Get: Downloads the object stored with the key to a local file.
+//   - Delete: Removes the object stored with the key, or returns ErrObjectNotFound if it doesn't exist.
+//   - Copy: Copies an object of another storage to the key without downloading it (server-side copy).
 //
 // Notes:
 //   - Implementations of this interface are responsible for storing files and reporting the results of the operation.
 //   - The `Put` method should handle errors gracefully and send a `StorageResult` to the provided channel.
+//   - Optional operations return ErrNotSupported if the backend cannot support them.
+//   - Keys returned by List and accepted by Stat, Get and Delete are in the same format as the ones returned by Key.
+//   - Copy may return ErrNotSupported for some source storages even if CapabilityCopy is supported, e.g. buckets
+//     on a different endpoint; callers should then download and upload the object instead.
 type Storage interface {
 	Info() string
 	Type() string
 	Put(ctx context.Context, item ScannerResult, candidates []string, stored chan<- StorageResult)
+	Upload(ctx context.Context, src string, key string, meta *ObjectMetadata) (*UploadInfo, error)
+	Supports(c Capability) bool
+	Key(src string) string
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	List(ctx context.Context, opts ListOptions) (*ListResult, error)
+	Get(ctx context.Context, key string, dest string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Copy(ctx context.Context, src Storage, srcKey string, destKey string) (*ObjectInfo, error)
+}
+
+// ListOptions holds the options for listing objects in a storage.
+//
+// Fields:
+//   - Prefix: Only objects whose key starts with the prefix are listed. If empty, the storage path prefix is used.
+//   - StartAfter: Only objects whose key is after this key are listed; used to fetch the next page.
+//   - MaxKeys: The maximum number of objects in a page. If zero, DefaultListMaxKeys is used.
+type ListOptions struct {
+	Prefix     string
+	StartAfter string
+	MaxKeys    int
+}
+
+// DefaultListMaxKeys is the default maximum number of objects returned in a page by Storage.List.
+const DefaultListMaxKeys = 1000
+
+// ListResult represents a page of objects listed from a storage.
+//
+// Fields:
+//   - Objects: The objects in the page, ordered by key.
+//   - Truncated: Whether there are more objects after this page.
+//   - NextStartAfter: The StartAfter value to fetch the next page, set if the result is truncated.
+type ListResult struct {
+	Objects        []ObjectInfo
+	Truncated      bool
+	NextStartAfter string
 }
 
 // StorageResult represents the result of a file storage operation.
@@ -111,18 +200,52 @@ type StorageResult struct {
 // Fields:
 //   - Src: The source directory of the file being uploaded.
 //   - Dest: The destination directory where the file was uploaded.
+//   - Key: The key of the object, in the format returned by Storage.Key.
 //   - ChecksumType: The type of checksum used (e.g., "md5").
 //   - Checksum: The checksum value of the uploaded file.
 //   - Size: The size of the uploaded file in bytes.
 //   - LastModified: The timestamp of the last modification of the uploaded file.
+//   - Skipped: Whether the upload was skipped because the destination already had the same checksum.
+//   - Duration: The time taken to sync the file with the storage.
 //
 // Notes:
 //   - This struct is used to provide detailed information about a file upload operation.
 type UploadInfo struct {
 	Src          string
 	Dest         string
+	Key          string
+	ChecksumType string
+	Checksum     string
+	Size         int64
+	LastModified time.Time
+	Skipped      bool
+	Duration     time.Duration
+}
+
+// ObjectInfo represents metadata about an object in a storage.
+//
+// Fields:
+//   - Key: The key of the object relative to the storage root.
+//   - ChecksumType: The type of checksum used (e.g., "md5").
+//   - Checksum: The checksum value of the object.
+//   - Size: The size of the object in bytes.
+//   - LastModified: The timestamp of the last modification of the object.
+//   - Metadata: The metadata stored with the object; empty if the storage does not keep metadata.
+type ObjectInfo struct {
+	Key          string
 	ChecksumType string
 	Checksum     string
 	Size         int64
 	LastModified time.Time
+	Metadata     ObjectMetadata
+}
+
+// ObjectMetadata represents the metadata stored with an object besides its content.
+//
+// Fields:
+//   - ContentType: The MIME type of the object; empty for the default of the storage.
+//   - UserMetadata: The user-defined metadata of the object, keyed without the storage specific prefix.
+type ObjectMetadata struct {
+	ContentType  string
+	UserMetadata map[string]string
 }
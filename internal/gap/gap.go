@@ -0,0 +1,179 @@
+package gap
+
+import (
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxGap is the default largest expected period between two consecutive marker files of a directory.
+const DefaultMaxGap = 10 * time.Second
+
+// maxOpenGaps bounds the number of open gaps kept per directory; the oldest gaps are forgotten first.
+const maxOpenGaps = 100
+
+// Gap is a period between two uploaded marker files of a directory that is larger than the expected period.
+// Start and End are the consensus timestamps of the marker files around the gap.
+type Gap struct {
+	Dir   string
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the period of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// stream is the state of a directory of stream files.
+type stream struct {
+	last time.Time // latest consensus timestamp uploaded
+	gaps []Gap     // open gaps, sorted by start
+}
+
+// Detector detects missing stream files from the consensus timestamps in the names of the uploaded marker files.
+//
+// It implements core.Recorder: for each successfully uploaded marker, the consensus timestamp is compared to the latest
+// one of its directory, and a gap is opened if they are further apart than the maximum gap. Since processors upload in
+// parallel, markers may be recorded out of order; a late marker falling within an open gap splits it, and the gap is
+// closed once the remaining periods are within the maximum gap.
+//
+// Notes:
+//   - Markers whose name has no consensus timestamp are ignored.
+//   - The first marker of a directory only sets its latest timestamp; gaps before it are not detected.
+//   - Gaps are logged when opened and closed, and exposed as metrics.GapsDetected and metrics.OpenGaps.
+type Detector struct {
+	pipeline string
+	maxGap   time.Duration
+
+	mu      sync.Mutex
+	streams map[string]*stream
+}
+
+// Record checks the consensus timestamp of a successfully uploaded marker file for gaps.
+// It never returns an error, so that a detected gap does not prevent the local files from being removed.
+func (d *Detector) Record(result core.ProcessorResult) error {
+	if result.Error != nil {
+		return nil
+	}
+
+	ts, err := hedera.ParseConsensusTimestamp(result.Path)
+	if err != nil {
+		return nil
+	}
+
+	d.observe(filepath.Dir(result.Path), ts)
+	return nil
+}
+
+// OpenGaps returns the gaps that have not been filled, sorted by directory and start.
+func (d *Detector) OpenGaps() []Gap {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var gaps []Gap
+	for _, s := range d.streams {
+		gaps = append(gaps, s.gaps...)
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Dir != gaps[j].Dir {
+			return gaps[i].Dir < gaps[j].Dir
+		}
+		return gaps[i].Start.Before(gaps[j].Start)
+	})
+
+	return gaps
+}
+
+func (d *Detector) observe(dir string, ts time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.streams[dir]
+	if !ok {
+		d.streams[dir] = &stream{last: ts}
+		return
+	}
+
+	if ts.After(s.last) {
+		if ts.Sub(s.last) > d.maxGap {
+			d.open(s, Gap{Dir: dir, Start: s.last, End: ts})
+		}
+		s.last = ts
+		return
+	}
+
+	d.fill(s, ts)
+}
+
+// open records a new gap, forgetting the oldest gap of the directory if there are too many.
+func (d *Detector) open(s *stream, g Gap) {
+	metrics.GapsDetected.WithLabelValues(d.pipeline).Inc()
+	metrics.OpenGaps.WithLabelValues(d.pipeline).Inc()
+	logx.As().Warn().
+		Str("pipeline", d.pipeline).
+		Str("dir", g.Dir).
+		Time("gap_start", g.Start).
+		Time("gap_end", g.End).
+		Dur("gap", g.Duration()).
+		Dur("max_gap", d.maxGap).
+		Msg("Gap detected between uploaded stream files")
+
+	s.gaps = append(s.gaps, g)
+	if len(s.gaps) > maxOpenGaps {
+		metrics.OpenGaps.WithLabelValues(d.pipeline).Dec()
+		s.gaps = s.gaps[1:]
+	}
+}
+
+// fill splits the open gap containing the timestamp of a late marker, keeping the parts that are still too large.
+func (d *Detector) fill(s *stream, ts time.Time) {
+	for i, g := range s.gaps {
+		if !ts.After(g.Start) || !ts.Before(g.End) {
+			continue
+		}
+
+		var parts []Gap
+		for _, part := range []Gap{{Dir: g.Dir, Start: g.Start, End: ts}, {Dir: g.Dir, Start: ts, End: g.End}} {
+			if part.Duration() > d.maxGap {
+				parts = append(parts, part)
+			}
+		}
+
+		s.gaps = append(s.gaps[:i], append(parts, s.gaps[i+1:]...)...)
+		metrics.OpenGaps.WithLabelValues(d.pipeline).Add(float64(len(parts) - 1))
+
+		if len(parts) == 0 {
+			logx.As().Info().
+				Str("pipeline", d.pipeline).
+				Str("dir", g.Dir).
+				Time("gap_start", g.Start).
+				Time("gap_end", g.End).
+				Msg("Gap between uploaded stream files has been filled")
+		}
+		return
+	}
+}
+
+// NewDetector creates a gap detector for the marker files of the pipeline.
+//
+// Parameters:
+//   - pipeline: The name of the pipeline, used in logs and as metrics label.
+//   - maxGap: The largest expected period between consecutive marker files. Default is DefaultMaxGap.
+func NewDetector(pipeline string, maxGap time.Duration) *Detector {
+	if maxGap <= 0 {
+		maxGap = DefaultMaxGap
+	}
+
+	return &Detector{
+		pipeline: pipeline,
+		maxGap:   maxGap,
+		streams:  make(map[string]*stream),
+	}
+}
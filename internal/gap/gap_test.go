@@ -0,0 +1,68 @@
+package gap
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"testing"
+	"time"
+)
+
+func result(path string) core.ProcessorResult {
+	return core.ProcessorResult{Path: path, Pipeline: "test-pipeline"}
+}
+
+func TestDetector(t *testing.T) {
+	pipeline := "test-gap-detector"
+	d := NewDetector(pipeline, 5*time.Second)
+	detected := testutil.ToFloat64(metrics.GapsDetected.WithLabelValues(pipeline))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	record := func(path string) {
+		require.NoError(t, d.Record(result(path)))
+	}
+
+	record("/streams/node1/2024-01-01T00_00_00.000000000Z.rcd_sig")
+	record("/streams/node1/2024-01-01T00_00_02.000000000Z.rcd_sig")
+	record("/streams/node2/2024-01-01T00_00_20.000000000Z.rcd_sig") // first marker of another directory
+	record("/streams/node1/balance.csv_sig")                        // no consensus timestamp
+	require.Empty(t, d.OpenGaps())
+
+	// a failed upload is not taken into account
+	require.NoError(t, d.Record(core.ProcessorResult{Path: "/streams/node1/2024-01-01T00_01_00.000000000Z.rcd_sig", Error: errors.New("failed")}))
+	require.Empty(t, d.OpenGaps())
+
+	record("/streams/node1/2024-01-01T00_00_20.000000000Z.rcd_sig")
+	require.Equal(t, []Gap{{Dir: "/streams/node1", Start: start.Add(2 * time.Second), End: start.Add(20 * time.Second)}}, d.OpenGaps())
+	require.Equal(t, detected+1, testutil.ToFloat64(metrics.GapsDetected.WithLabelValues(pipeline)))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.OpenGaps.WithLabelValues(pipeline)))
+
+	// late markers split the gap and close it once the remaining periods are within the maximum gap
+	record("/streams/node1/2024-01-01T00_00_10.000000000Z.rcd_sig")
+	require.Equal(t, []Gap{
+		{Dir: "/streams/node1", Start: start.Add(2 * time.Second), End: start.Add(10 * time.Second)},
+		{Dir: "/streams/node1", Start: start.Add(10 * time.Second), End: start.Add(20 * time.Second)},
+	}, d.OpenGaps())
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.OpenGaps.WithLabelValues(pipeline)))
+
+	record("/streams/node1/2024-01-01T00_00_06.000000000Z.rcd_sig")
+	record("/streams/node1/2024-01-01T00_00_15.000000000Z.rcd_sig")
+	require.Empty(t, d.OpenGaps())
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.OpenGaps.WithLabelValues(pipeline)))
+	require.Equal(t, detected+1, testutil.ToFloat64(metrics.GapsDetected.WithLabelValues(pipeline)))
+}
+
+func TestDetector_MaxOpenGaps(t *testing.T) {
+	d := NewDetector("test-gap-detector-bounded", 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= maxOpenGaps+1; i++ {
+		d.observe("/streams/node1", start.Add(time.Duration(i)*time.Minute))
+	}
+
+	gaps := d.OpenGaps()
+	require.Len(t, gaps, maxOpenGaps)
+	require.Equal(t, start.Add(time.Minute), gaps[0].Start)
+	require.Equal(t, float64(maxOpenGaps), testutil.ToFloat64(metrics.OpenGaps.WithLabelValues("test-gap-detector-bounded")))
+}
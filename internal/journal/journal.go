@@ -0,0 +1,394 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFileName is the default name of the journal file.
+const DefaultFileName = "journal.jsonl"
+
+// DefaultMaxSize is the default maximum size (in MB) of a journal file before it is rotated.
+const DefaultMaxSize = 100
+
+// Outcomes of a processed marker file.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+)
+
+// rotatedTimeFormat is the timestamp format appended to the name of rotated journal files.
+// It sorts lexically in chronological order.
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// JournalConfig holds the configuration for the upload journal.
+type JournalConfig struct {
+	// Enabled indicates whether the upload journal is enabled.
+	Enabled bool
+	// Directory is the directory where journal files are stored.
+	Directory string
+	// FileName is the name of the active journal file. Default is journal.jsonl.
+	FileName string
+	// MaxSize is the maximum size (in MB) of a journal file before it is rotated. Default is 100.
+	MaxSize int64
+	// MaxBackups is the maximum number of rotated journal files to keep. Default (0) keeps all of them.
+	MaxBackups int
+}
+
+// Entry is a single record in the journal describing the outcome of processing a marker file.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Pipeline  string    `json:"pipeline"`
+	Marker    string    `json:"marker"`
+	TraceId   string    `json:"trace_id"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+	Uploads   []Upload  `json:"uploads,omitempty"`
+	Failures  []Failure `json:"failures,omitempty"`
+}
+
+// Upload describes a single file synced to a storage.
+type Upload struct {
+	Storage      string    `json:"storage"`
+	Handler      string    `json:"handler"`
+	Src          string    `json:"src"`
+	Dest         string    `json:"dest"`
+	Key          string    `json:"key,omitempty"`
+	ChecksumType string    `json:"checksum_type"`
+	Checksum     string    `json:"checksum"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Skipped      bool      `json:"skipped,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+}
+
+// Failure describes a storage that failed to sync the files of a marker.
+type Failure struct {
+	Storage string `json:"storage"`
+	Handler string `json:"handler"`
+	Error   string `json:"error"`
+}
+
+// Journal is an append-only JSON-lines log of every processed marker file.
+// Each entry is flushed to disk before Record returns, and the file is rotated once it reaches the configured size.
+type Journal struct {
+	directory  string
+	fileName   string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	mu         sync.Mutex
+}
+
+// New opens (or creates) the journal file in the configured directory.
+func New(cfg JournalConfig) (*Journal, error) {
+	if cfg.Directory == "" {
+		return nil, fmt.Errorf("missing Directory in journal configuration")
+	}
+
+	j := &Journal{
+		directory:  cfg.Directory,
+		fileName:   cfg.FileName,
+		maxBytes:   cfg.MaxSize * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+	}
+
+	if j.fileName == "" {
+		j.fileName = DefaultFileName
+	}
+
+	if j.maxBytes <= 0 {
+		j.maxBytes = DefaultMaxSize * 1024 * 1024
+	}
+
+	if err := os.MkdirAll(j.directory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+
+	logx.As().Info().
+		Str("path", j.Path()).
+		Int64("size", j.size).
+		Int64("max_size_bytes", j.maxBytes).
+		Int("max_backups", j.maxBackups).
+		Msg("Upload journal opened")
+
+	return j, nil
+}
+
+// Path returns the path of the active journal file.
+func (j *Journal) Path() string {
+	return filepath.Join(j.directory, j.fileName)
+}
+
+// Record appends an entry for the processor result to the journal.
+// It implements core.Recorder.
+func (j *Journal) Record(result core.ProcessorResult) error {
+	return j.Append(NewEntry(result))
+}
+
+// Append writes the entry as a single JSON line and flushes it to disk.
+func (j *Journal) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("journal is closed")
+	}
+
+	if j.size > 0 && j.size+int64(len(line)) > j.maxBytes {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to flush journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the active journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Files returns the journal files in chronological order; rotated files first and the active file last.
+func (j *Journal) Files() ([]string, error) {
+	return ListFiles(j.directory, j.fileName)
+}
+
+// open opens the active journal file in append mode.
+func (j *Journal) open() error {
+	f, err := os.OpenFile(j.Path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		fsx.CloseFile(f)
+		return fmt.Errorf("failed to stat journal file: %w", err)
+	}
+
+	j.file = f
+	j.size = info.Size()
+	return nil
+}
+
+// rotate renames the active journal file with a timestamp suffix, opens a new one and prunes old backups.
+// A failed rotation or prune is only logged: the active journal file is opened again so that entries are still
+// appended, and the rotation is retried by the next entry. It returns an error only if no journal file can be opened.
+// It must be called with the mutex held.
+func (j *Journal) rotate() error {
+	rotated, err := j.rename()
+	if err != nil {
+		logx.As().Warn().Err(err).Str("path", j.Path()).Msg("Failed to rotate upload journal, appending to the active file")
+	}
+
+	if err := j.open(); err != nil {
+		return err
+	}
+	if !rotated {
+		return nil
+	}
+
+	if err := j.prune(); err != nil {
+		logx.As().Warn().Err(err).Str("directory", j.directory).Msg("Failed to prune rotated upload journal files")
+	}
+
+	return nil
+}
+
+// rename closes the active journal file and renames it with a timestamp suffix. It returns whether the file was
+// renamed, which may be the case even if the directory could not be flushed afterwards.
+func (j *Journal) rename() (bool, error) {
+	err := j.file.Close()
+	j.file = nil
+	if err != nil {
+		return false, fmt.Errorf("failed to close journal file: %w", err)
+	}
+
+	ext := filepath.Ext(j.fileName)
+	name := strings.TrimSuffix(j.fileName, ext)
+	backupPath := filepath.Join(j.directory, fmt.Sprintf("%s-%s%s", name, time.Now().UTC().Format(rotatedTimeFormat), ext))
+	if err := os.Rename(j.Path(), backupPath); err != nil {
+		return false, fmt.Errorf("failed to rotate journal file: %w", err)
+	}
+
+	logx.As().Info().Str("path", backupPath).Msg("Rotated upload journal")
+
+	return true, syncDir(j.directory)
+}
+
+// prune removes the oldest rotated journal files beyond the configured number of backups.
+func (j *Journal) prune() error {
+	if j.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := listBackups(j.directory, j.fileName)
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > j.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove old journal file: %w", err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// ListFiles returns the journal files with the given name in the directory in chronological order; rotated files
+// first and the active file last.
+func ListFiles(directory string, fileName string) ([]string, error) {
+	if fileName == "" {
+		fileName = DefaultFileName
+	}
+
+	files, err := listBackups(directory, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	active := filepath.Join(directory, fileName)
+	if _, exists := fsx.PathExists(active); exists {
+		files = append(files, active)
+	}
+
+	return files, nil
+}
+
+// listBackups returns the rotated journal files sorted from oldest to newest.
+func listBackups(directory string, fileName string) ([]string, error) {
+	ext := filepath.Ext(fileName)
+	name := strings.TrimSuffix(fileName, ext)
+
+	backups, err := filepath.Glob(filepath.Join(directory, fmt.Sprintf("%s-*%s", name, ext)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal files: %w", err)
+	}
+
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// syncDir flushes the directory entry so that a rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open journal directory: %w", err)
+	}
+	defer fsx.CloseFile(d)
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to flush journal directory: %w", err)
+	}
+
+	return nil
+}
+
+// NewEntry converts a processor result into a journal entry.
+// Uploads and failures are sorted by storage type so that entries are deterministic.
+func NewEntry(result core.ProcessorResult) Entry {
+	e := Entry{
+		Timestamp: time.Now().UTC(),
+		Pipeline:  result.Pipeline,
+		Marker:    result.Path,
+		TraceId:   result.TraceId,
+		Outcome:   OutcomeSuccess,
+	}
+
+	if result.Error != nil {
+		e.Outcome = OutcomeFailed
+		e.Error = result.Error.Error()
+	}
+
+	storageTypes := make([]string, 0, len(result.Result))
+	for storageType := range result.Result {
+		storageTypes = append(storageTypes, storageType)
+	}
+	sort.Strings(storageTypes)
+
+	for _, storageType := range storageTypes {
+		sr := result.Result[storageType]
+		if sr == nil {
+			continue
+		}
+
+		if sr.Error != nil {
+			e.Failures = append(e.Failures, Failure{
+				Storage: storageType,
+				Handler: sr.Handler,
+				Error:   sr.Error.Error(),
+			})
+			continue
+		}
+
+		uploads := make([]Upload, 0, len(sr.UploadResults))
+		for _, info := range sr.UploadResults {
+			if info == nil {
+				continue
+			}
+
+			uploads = append(uploads, Upload{
+				Storage:      storageType,
+				Handler:      sr.Handler,
+				Src:          info.Src,
+				Dest:         info.Dest,
+				Key:          info.Key,
+				ChecksumType: info.ChecksumType,
+				Checksum:     info.Checksum,
+				Size:         info.Size,
+				LastModified: info.LastModified,
+				Skipped:      info.Skipped,
+				DurationMs:   info.Duration.Milliseconds(),
+			})
+		}
+
+		sort.Slice(uploads, func(a, b int) bool {
+			return uploads[a].Src < uploads[b].Src
+		})
+		e.Uploads = append(e.Uploads, uploads...)
+	}
+
+	return e
+}
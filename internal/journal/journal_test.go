@@ -0,0 +1,149 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Entry
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, sc.Err())
+	return entries
+}
+
+func TestNew_MissingDirectory(t *testing.T) {
+	_, err := New(JournalConfig{Enabled: true})
+	require.Error(t, err)
+}
+
+func TestJournal_Record(t *testing.T) {
+	dir := t.TempDir()
+	j, err := New(JournalConfig{Enabled: true, Directory: dir})
+	require.NoError(t, err)
+
+	modTime := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, j.Record(core.ProcessorResult{
+		Path:     "/data/2025-06-01T10_00_00.000000000Z.rcd_sig",
+		TraceId:  "trace-1",
+		Pipeline: "record-pipeline",
+		Result: map[string]*core.StorageResult{
+			"S3": {
+				Type:    "S3",
+				Handler: "s3-0",
+				UploadResults: []*core.UploadInfo{
+					{Src: "/data/b.rcd.gz", Dest: "bucket/b.rcd.gz", ChecksumType: "md5", Checksum: "bb", Size: 20, LastModified: modTime},
+					{Src: "/data/a.rcd.gz", Dest: "bucket/a.rcd.gz", ChecksumType: "md5", Checksum: "aa", Size: 10, LastModified: modTime, Skipped: true},
+				},
+			},
+			"GCS": {Type: "GCS", Handler: "gcs-0", Error: fmt.Errorf("bucket not found")},
+		},
+		Error: fmt.Errorf("bucket not found"),
+	}))
+	require.NoError(t, j.Close())
+
+	// reopening appends to the existing file
+	j, err = New(JournalConfig{Enabled: true, Directory: dir})
+	require.NoError(t, err)
+	require.NoError(t, j.Record(core.ProcessorResult{Path: "/data/marker2", TraceId: "trace-2", Pipeline: "record-pipeline"}))
+	require.NoError(t, j.Close())
+	require.Error(t, j.Record(core.ProcessorResult{Path: "/data/marker3"}))
+
+	entries := readEntries(t, filepath.Join(dir, DefaultFileName))
+	require.Len(t, entries, 2)
+
+	e := entries[0]
+	require.Equal(t, "trace-1", e.TraceId)
+	require.Equal(t, "record-pipeline", e.Pipeline)
+	require.Equal(t, OutcomeFailed, e.Outcome)
+	require.Equal(t, "bucket not found", e.Error)
+	require.Len(t, e.Failures, 1)
+	require.Equal(t, "GCS", e.Failures[0].Storage)
+	require.Len(t, e.Uploads, 2)
+	require.Equal(t, "/data/a.rcd.gz", e.Uploads[0].Src)
+	require.Equal(t, "bucket/a.rcd.gz", e.Uploads[0].Dest)
+	require.Equal(t, "S3", e.Uploads[0].Storage)
+	require.Equal(t, "s3-0", e.Uploads[0].Handler)
+	require.Equal(t, "aa", e.Uploads[0].Checksum)
+	require.True(t, e.Uploads[0].Skipped)
+	require.Equal(t, modTime, e.Uploads[0].LastModified)
+
+	require.Equal(t, OutcomeSuccess, entries[1].Outcome)
+	require.Empty(t, entries[1].Uploads)
+}
+
+func TestJournal_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	j, err := New(JournalConfig{Enabled: true, Directory: dir, FileName: "audit.jsonl", MaxBackups: 2})
+	require.NoError(t, err)
+	j.maxBytes = 200 // force rotation after every entry or two
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, j.Record(core.ProcessorResult{Path: fmt.Sprintf("/data/marker-%d", i), TraceId: fmt.Sprintf("trace-%d", i)}))
+	}
+	require.NoError(t, j.Close())
+
+	files, err := ListFiles(dir, "audit.jsonl")
+	require.NoError(t, err)
+	require.Len(t, files, 3) // two backups and the active file
+	require.Equal(t, filepath.Join(dir, "audit.jsonl"), files[len(files)-1])
+
+	// the active file holds the latest entry and backups are older
+	active := readEntries(t, files[2])
+	require.Equal(t, "trace-9", active[len(active)-1].TraceId)
+	backup := readEntries(t, files[0])
+	require.Less(t, backup[0].Timestamp.UnixNano(), active[0].Timestamp.UnixNano())
+
+	for _, f := range files {
+		info, err := os.Stat(f)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(200))
+	}
+}
+
+func TestJournal_RotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	j, err := New(JournalConfig{Enabled: true, Directory: dir, FileName: "audit.jsonl", MaxBackups: 1})
+	require.NoError(t, err)
+	j.maxBytes = 200
+
+	require.NoError(t, j.Record(core.ProcessorResult{Path: "/data/marker-0", TraceId: "trace-0"}))
+
+	// the active file cannot be renamed, so it is opened again and the entry is still appended
+	require.NoError(t, os.Remove(j.Path()))
+	require.NoError(t, j.Record(core.ProcessorResult{Path: "/data/marker-1", TraceId: "trace-1"}))
+	entries := readEntries(t, j.Path())
+	require.Len(t, entries, 1)
+	require.Equal(t, "trace-1", entries[0].TraceId)
+
+	// an old backup that cannot be removed does not fail the entry
+	stuck := filepath.Join(dir, "audit-00000000T000000.000000000Z.jsonl")
+	require.NoError(t, os.MkdirAll(filepath.Join(stuck, "keep"), 0755))
+	require.NoError(t, j.Record(core.ProcessorResult{Path: "/data/marker-2", TraceId: "trace-2"}))
+	require.NoError(t, j.Record(core.ProcessorResult{Path: "/data/marker-3", TraceId: "trace-3"}))
+	require.DirExists(t, stuck)
+	files, err := ListFiles(dir, "audit.jsonl")
+	require.NoError(t, err)
+	require.Len(t, files, 4) // pruning stops at the stuck backup
+	entries = readEntries(t, j.Path())
+	require.Equal(t, "trace-3", entries[len(entries)-1].TraceId)
+	require.NoError(t, j.Close())
+}
@@ -0,0 +1,184 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/gobwas/glob"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxEntrySize is the maximum size of a single journal line that can be read.
+const maxEntrySize = 16 * 1024 * 1024
+
+// Filter selects journal entries. Zero values match everything.
+type Filter struct {
+	// Path is a glob pattern matched against the marker path as well as the source and destination of the uploads.
+	// The pattern is matched against both the full path and the base name.
+	Path string
+	// TraceId selects the entry with the given trace ID.
+	TraceId string
+	// Storage selects entries with an upload or failure for the storage type (e.g. "S3", "GCS"). It is case-insensitive.
+	Storage string
+	// Since selects entries recorded at or after the time.
+	Since time.Time
+	// Until selects entries recorded before the time.
+	Until time.Time
+	// Outcome selects entries with the outcome (success or failed).
+	Outcome string
+	// Limit is the maximum number of entries to return; the latest entries are kept. Zero means no limit.
+	Limit int
+}
+
+// Query reads the journal files in the directory and returns the entries matching the filter in chronological order.
+// Uploads and failures of the returned entries are narrowed down to the ones matching the storage and path filters.
+// Lines that cannot be decoded (e.g. a partially written last line) are skipped.
+func Query(directory string, fileName string, filter Filter) ([]Entry, error) {
+	var pathGlob glob.Glob
+	if filter.Path != "" {
+		g, err := glob.Compile(filter.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile path pattern '%s': %w", filter.Path, err)
+		}
+		pathGlob = g
+	}
+
+	if filter.Outcome != "" && filter.Outcome != OutcomeSuccess && filter.Outcome != OutcomeFailed {
+		return nil, fmt.Errorf("invalid outcome '%s', expected %s or %s", filter.Outcome, OutcomeSuccess, OutcomeFailed)
+	}
+
+	files, err := ListFiles(directory, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		err := readFile(file, func(e Entry) {
+			if matched, ok := filter.apply(e, pathGlob); ok {
+				entries = append(entries, matched)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+
+	return entries, nil
+}
+
+// readFile decodes each line of the journal file and passes the entry to the callback.
+func readFile(path string, fn func(e Entry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer fsx.CloseFile(f)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxEntrySize)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			logx.As().Warn().
+				Err(err).
+				Str("path", path).
+				Int("line", line).
+				Msg("Skipping invalid journal entry")
+			continue
+		}
+
+		fn(e)
+	}
+
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read journal file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// apply returns the entry narrowed down to the matching uploads and failures, and whether it matches the filter.
+func (f Filter) apply(e Entry, pathGlob glob.Glob) (Entry, bool) {
+	if f.TraceId != "" && e.TraceId != f.TraceId {
+		return e, false
+	}
+
+	if f.Outcome != "" && e.Outcome != f.Outcome {
+		return e, false
+	}
+
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return e, false
+	}
+
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return e, false
+	}
+
+	if f.Storage != "" {
+		var uploads []Upload
+		for _, u := range e.Uploads {
+			if strings.EqualFold(u.Storage, f.Storage) {
+				uploads = append(uploads, u)
+			}
+		}
+
+		var failures []Failure
+		for _, fl := range e.Failures {
+			if strings.EqualFold(fl.Storage, f.Storage) {
+				failures = append(failures, fl)
+			}
+		}
+
+		if len(uploads) == 0 && len(failures) == 0 {
+			return e, false
+		}
+
+		e.Uploads = uploads
+		e.Failures = failures
+	}
+
+	if pathGlob != nil && !matchPath(pathGlob, e.Marker) {
+		// the marker doesn't match, so keep only the uploads of matching files
+		var uploads []Upload
+		for _, u := range e.Uploads {
+			if matchPath(pathGlob, u.Src) || matchPath(pathGlob, u.Dest) {
+				uploads = append(uploads, u)
+			}
+		}
+
+		if len(uploads) == 0 {
+			return e, false
+		}
+
+		e.Uploads = uploads
+	}
+
+	return e, true
+}
+
+// matchPath matches the glob against the full path and its base name.
+func matchPath(g glob.Glob, path string) bool {
+	if path == "" {
+		return false
+	}
+
+	return g.Match(path) || g.Match(filepath.Base(path))
+}
@@ -0,0 +1,112 @@
+package journal
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeEntries(t *testing.T, dir string, entries ...Entry) {
+	j, err := New(JournalConfig{Enabled: true, Directory: dir})
+	require.NoError(t, err)
+	for _, e := range entries {
+		require.NoError(t, j.Append(e))
+	}
+	require.NoError(t, j.Close())
+}
+
+func TestQuery(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	writeEntries(t, dir,
+		Entry{
+			Timestamp: t0,
+			Pipeline:  "records",
+			Marker:    "/data/record0.0.3/2025-06-01T10_00_00Z.rcd_sig",
+			TraceId:   "trace-1",
+			Outcome:   OutcomeSuccess,
+			Uploads: []Upload{
+				{Storage: "GCS", Src: "/data/record0.0.3/2025-06-01T10_00_00Z.rcd.gz", Dest: "bucket/record0.0.3/2025-06-01T10_00_00Z.rcd.gz"},
+				{Storage: "GCS", Src: "/data/record0.0.3/2025-06-01T10_00_00Z.rcd_sig", Dest: "bucket/record0.0.3/2025-06-01T10_00_00Z.rcd_sig"},
+				{Storage: "S3", Src: "/data/record0.0.3/2025-06-01T10_00_00Z.rcd.gz", Dest: "bucket/record0.0.3/2025-06-01T10_00_00Z.rcd.gz"},
+			},
+		},
+		Entry{
+			Timestamp: t0.Add(time.Hour),
+			Pipeline:  "records",
+			Marker:    "/data/record0.0.3/2025-06-01T11_00_00Z.rcd_sig",
+			TraceId:   "trace-2",
+			Outcome:   OutcomeFailed,
+			Error:     "bucket not found",
+			Failures:  []Failure{{Storage: "S3", Error: "bucket not found"}},
+		},
+		Entry{
+			Timestamp: t0.Add(2 * time.Hour),
+			Pipeline:  "events",
+			Marker:    "/data/events_0.0.3/2025-06-01T12_00_00Z.evts_sig",
+			TraceId:   "trace-3",
+			Outcome:   OutcomeSuccess,
+		},
+	)
+
+	// a partially written line is skipped
+	f, err := os.OpenFile(filepath.Join(dir, DefaultFileName), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"timestamp":"2025-06-01T`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		traceIds []string
+		uploads  []int
+	}{
+		{name: "all", filter: Filter{}, traceIds: []string{"trace-1", "trace-2", "trace-3"}, uploads: []int{3, 0, 0}},
+		{name: "trace id", filter: Filter{TraceId: "trace-2"}, traceIds: []string{"trace-2"}, uploads: []int{0}},
+		{name: "outcome", filter: Filter{Outcome: OutcomeSuccess}, traceIds: []string{"trace-1", "trace-3"}, uploads: []int{3, 0}},
+		{name: "storage", filter: Filter{Storage: "gcs"}, traceIds: []string{"trace-1"}, uploads: []int{2}},
+		{name: "storage failure", filter: Filter{Storage: "S3"}, traceIds: []string{"trace-1", "trace-2"}, uploads: []int{1, 0}},
+		{name: "marker glob", filter: Filter{Path: "*.evts_sig"}, traceIds: []string{"trace-3"}, uploads: []int{0}},
+		{name: "file glob", filter: Filter{Path: "*.rcd.gz"}, traceIds: []string{"trace-1"}, uploads: []int{2}},
+		{name: "full path glob", filter: Filter{Path: "bucket/record0.0.3/*"}, traceIds: []string{"trace-1"}, uploads: []int{3}},
+		{name: "since", filter: Filter{Since: t0.Add(time.Hour)}, traceIds: []string{"trace-2", "trace-3"}, uploads: []int{0, 0}},
+		{name: "until", filter: Filter{Until: t0.Add(time.Hour)}, traceIds: []string{"trace-1"}, uploads: []int{3}},
+		{name: "limit", filter: Filter{Limit: 1}, traceIds: []string{"trace-3"}, uploads: []int{0}},
+		{name: "no match", filter: Filter{TraceId: "unknown"}, traceIds: nil, uploads: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Query(dir, "", tt.filter)
+			require.NoError(t, err)
+
+			var traceIds []string
+			var uploads []int
+			for _, e := range entries {
+				traceIds = append(traceIds, e.TraceId)
+				uploads = append(uploads, len(e.Uploads))
+			}
+			require.Equal(t, tt.traceIds, traceIds)
+			require.Equal(t, tt.uploads, uploads)
+		})
+	}
+}
+
+func TestQuery_InvalidFilter(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Query(dir, "", Filter{Path: "[invalid"})
+	require.Error(t, err)
+
+	_, err = Query(dir, "", Filter{Outcome: "unknown"})
+	require.Error(t, err)
+
+	// no journal files
+	entries, err := Query(dir, "", Filter{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -6,6 +6,7 @@ import (
 	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
 	"os"
 )
 
@@ -26,11 +27,17 @@ func (bm *basicFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConf
 		return []string{}, nil
 	}
 
-	markerDir, markerName, _ := fsx.SplitFilePath(marker)
+	markerDir, _, _ := fsx.SplitFilePath(marker)
+	vars := tmplx.MarkerVars(marker, cfg.MarkerExt, cfg.RootDir)
+	markerName := vars[tmplx.VarMarkerName].(string)
 
 	var matches []string
 
-	for _, ext := range cfg.Patterns {
+	for _, pattern := range cfg.Patterns {
+		ext, err := tmplx.Render(pattern, vars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file extension pattern: %w", err)
+		}
 		if !core.IsFileExtension(ext) {
 			return nil, fmt.Errorf("%s is not a valid file extension", ext)
 		}
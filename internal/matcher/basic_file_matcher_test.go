@@ -64,3 +64,24 @@ func TestBasicFileMatcher_MatchFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestBasicFileMatcher_MatchFiles_MarkerExt(t *testing.T) {
+	tempDir := t.TempDir()
+
+	markerPath := filepath.Join(tempDir, "test_marker.evts.gz")
+	require.NoError(t, os.WriteFile(markerPath, []byte("marker"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "test_marker.evts_sig"), []byte("data"), 0644))
+
+	matcher := NewBasicFileMatcher()
+
+	cfg := config.FileMatcherConfig{Patterns: []string{".evts_sig"}, MarkerExt: ".evts.gz"}
+	got, err := matcher.MatchFiles(markerPath, cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(tempDir, "test_marker.evts_sig")}, got)
+
+	// without the marker extension only .gz is stripped
+	cfg.MarkerExt = ""
+	got, err = matcher.MatchFiles(markerPath, cfg)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
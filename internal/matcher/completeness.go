@@ -0,0 +1,91 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"strings"
+)
+
+// Requirements of the patterns of a file matcher configuration.
+const (
+	RequirementOptional = "optional" // a pattern may match no file
+	RequirementRequired = "required" // each pattern must match at least one file
+	RequirementAtLeast  = "atLeast"  // each pattern must match at least MinMatches files
+)
+
+// IncompleteError reports the patterns of a marker that do not match the files they require yet, e.g. a data file
+// that has not been written when its marker file is found.
+type IncompleteError struct {
+	Marker  string
+	Missing []string
+}
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("candidate files of marker %s are incomplete: %s", e.Marker, strings.Join(e.Missing, "; "))
+}
+
+// CheckRequirement returns an error if the requirement of the file matcher configuration is invalid.
+func CheckRequirement(cfg config.FileMatcherConfig) error {
+	switch cfg.Requirement {
+	case "", RequirementOptional, RequirementRequired:
+		return nil
+	case RequirementAtLeast:
+		if cfg.MinMatches <= 0 {
+			return fmt.Errorf("minMatches must be positive for requirement %s, got %d", RequirementAtLeast, cfg.MinMatches)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid requirement '%s', expected %s, %s or %s",
+			cfg.Requirement, RequirementOptional, RequirementRequired, RequirementAtLeast)
+	}
+}
+
+// minMatches returns the minimum number of files each pattern of the configuration must match.
+func minMatches(cfg config.FileMatcherConfig) int {
+	switch cfg.Requirement {
+	case RequirementRequired:
+		return 1
+	case RequirementAtLeast:
+		return cfg.MinMatches
+	default:
+		return 0
+	}
+}
+
+// matchPatterns returns the files matched by the configuration and describes the patterns that match fewer files
+// than required. Patterns with a requirement are matched one at a time, so that the files of each pattern are counted
+// without matching them twice. The manifest matcher does not use the patterns and is matched once.
+func matchPatterns(m FileMatcher, marker string, cfg config.FileMatcherConfig) ([]string, []string, error) {
+	required := minMatches(cfg)
+	if required == 0 || len(cfg.Patterns) == 0 || m.Type() == FileMatcherManifest {
+		matches, err := m.MatchFiles(marker, cfg)
+		return matches, nil, err
+	}
+
+	var matches []string
+	var missing []string
+	var incomplete []string
+	for _, pattern := range cfg.Patterns {
+		single := cfg
+		single.Patterns = []string{pattern}
+		found, err := m.MatchFiles(marker, single)
+		var ie *IncompleteError
+		if errors.As(err, &ie) {
+			incomplete = append(incomplete, ie.Missing...)
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		matches = append(matches, found...)
+		if len(found) < required {
+			missing = append(missing, fmt.Sprintf("pattern %s matched %d of %d required files", pattern, len(found), required))
+		}
+	}
+
+	if len(incomplete) > 0 {
+		return matches, missing, &IncompleteError{Marker: marker, Missing: incomplete}
+	}
+
+	return matches, missing, nil
+}
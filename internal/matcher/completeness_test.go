@@ -0,0 +1,129 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+)
+
+func TestMatchCandidates_Requirement(t *testing.T) {
+	tempDir := t.TempDir()
+	marker := filepath.Join(tempDir, "test_marker.rcd_sig")
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "sidecar"), 0755))
+	for _, f := range []string{"test_marker.rcd_sig", "test_marker.rcd.gz", "sidecar/test_marker_01.rcd.gz", "sidecar/test_marker_03.rcd.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, f), []byte("data"), 0644))
+	}
+
+	tests := []struct {
+		name    string
+		configs []config.FileMatcherConfig
+		want    []string
+		missing []string
+	}{
+		{
+			name: "optional patterns",
+			configs: []config.FileMatcherConfig{
+				{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz", ".evts"}},
+			},
+			want: []string{filepath.Join(tempDir, "test_marker.rcd.gz")},
+		},
+		{
+			name: "required patterns exist",
+			configs: []config.FileMatcherConfig{
+				{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz", ".rcd_sig"}, Requirement: RequirementRequired},
+			},
+			want: []string{filepath.Join(tempDir, "test_marker.rcd.gz"), marker},
+		},
+		{
+			name: "required pattern missing",
+			configs: []config.FileMatcherConfig{
+				{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz", ".evts"}, Requirement: RequirementRequired},
+			},
+			want:    []string{filepath.Join(tempDir, "test_marker.rcd.gz")},
+			missing: []string{"pattern .evts matched 0 of 1 required files"},
+		},
+		{
+			name: "at least N sidecar files",
+			configs: []config.FileMatcherConfig{
+				{MatcherType: FileMatcherSequential, Patterns: []string{"sidecar/{{.markerName}}_##.rcd.gz"},
+					Requirement: RequirementAtLeast, MinMatches: 2},
+			},
+			want:    []string{filepath.Join(tempDir, "sidecar/test_marker_01.rcd.gz")},
+			missing: []string{"pattern sidecar/{{.markerName}}_##.rcd.gz matched 1 of 2 required files"},
+		},
+		{
+			name: "gap reported by the matcher",
+			configs: []config.FileMatcherConfig{
+				{MatcherType: FileMatcherSequential, Patterns: []string{"sidecar/{{.markerName}}_##.rcd.gz"},
+					Requirement: RequirementRequired, Sequence: &config.SequenceConfig{OnGap: GapDefer}},
+			},
+			want: []string{filepath.Join(tempDir, "sidecar/test_marker_01.rcd.gz")},
+			missing: []string{"pattern sidecar/test_marker_##.rcd.gz has a gap after number 1 before " +
+				filepath.Join(tempDir, "sidecar/test_marker_03.rcd.gz")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchCandidates(marker, tt.configs)
+			require.ElementsMatch(t, tt.want, got)
+			if tt.missing == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var incomplete *IncompleteError
+			require.ErrorAs(t, err, &incomplete)
+			require.Equal(t, marker, incomplete.Marker)
+			require.Equal(t, tt.missing, incomplete.Missing)
+		})
+	}
+}
+
+func TestCheckRequirement(t *testing.T) {
+	require.NoError(t, CheckRequirement(config.FileMatcherConfig{}))
+	require.NoError(t, CheckRequirement(config.FileMatcherConfig{Requirement: RequirementAtLeast, MinMatches: 1}))
+	require.ErrorContains(t, CheckRequirement(config.FileMatcherConfig{Requirement: RequirementAtLeast}), "minMatches must be positive")
+	require.ErrorContains(t, CheckRequirement(config.FileMatcherConfig{Requirement: "all"}), "invalid requirement 'all'")
+}
+
+// countingMatcher matches one file per pattern and counts the patterns it is asked to match.
+type countingMatcher struct {
+	patterns int
+}
+
+func (cm *countingMatcher) Type() string {
+	return "counting"
+}
+
+func (cm *countingMatcher) MatchFiles(marker string, cfg config.FileMatcherConfig) ([]string, error) {
+	var matches []string
+	for _, pattern := range cfg.Patterns {
+		cm.patterns++
+		if pattern != "missing" {
+			matches = append(matches, pattern)
+		}
+	}
+	return matches, nil
+}
+
+func TestMatchPatterns_MatchesOnce(t *testing.T) {
+	for _, requirement := range []string{RequirementOptional, RequirementRequired} {
+		m := &countingMatcher{}
+		matches, missing, err := matchPatterns(m, "marker", config.FileMatcherConfig{
+			Patterns:    []string{"a", "missing", "b"},
+			Requirement: requirement,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, matches)
+		require.Equal(t, 3, m.patterns, "each pattern is matched once")
+		if requirement == RequirementRequired {
+			require.Equal(t, []string{"pattern missing matched 0 of 1 required files"}, missing)
+		} else {
+			require.Empty(t, missing)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package matcher
 
 import (
+	"errors"
 	"fmt"
 	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
 )
 
 var fileMatchers map[string]FileMatcher
@@ -22,6 +24,44 @@ func GetFileMatcher(matcherType string) (FileMatcher, error) {
 	return nil, fmt.Errorf("file matcher %s not found", matcherType)
 }
 
+// MatchCandidates returns the files to be uploaded for the marker using all the file matcher configurations.
+// If a pattern matches fewer files than its configuration requires, or a matcher reports missing files itself, the
+// candidates are returned along with an IncompleteError listing the incomplete patterns.
+func MatchCandidates(marker string, configs []config.FileMatcherConfig) ([]string, error) {
+	var candidates []string
+	var missing []string
+	for _, mc := range configs {
+		m, err := GetFileMatcher(mc.MatcherType)
+		if err != nil {
+			return nil, fmt.Errorf("unknown file matcher type: %s", mc.MatcherType)
+		}
+
+		matches, unmatched, err := matchPatterns(m, marker, mc)
+		var incomplete *IncompleteError
+		if errors.As(err, &incomplete) {
+			missing = append(missing, incomplete.Missing...)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to match files for marker %s: %w", marker, err)
+		}
+
+		logx.As().Debug().
+			Str("marker", marker).
+			Str("matcher", m.Type()).
+			Str("matches", fmt.Sprintf("%v", matches)).
+			Str("patterns", fmt.Sprintf("%v", mc.Patterns)).
+			Msg("Results of matcher")
+
+		candidates = append(candidates, matches...)
+		missing = append(missing, unmatched...)
+	}
+
+	if len(missing) > 0 {
+		return candidates, &IncompleteError{Marker: marker, Missing: missing}
+	}
+
+	return candidates, nil
+}
+
 type FileMatcher interface {
 	Type() string
 	MatchFiles(marker string, cfg config.FileMatcherConfig) ([]string, error)
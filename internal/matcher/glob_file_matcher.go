@@ -1,18 +1,22 @@
 package matcher
 
 import (
-	"bytes"
 	"fmt"
 	"golang.hedera.com/solo-cheetah/internal/config"
 	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
 	"path/filepath"
-	"text/template"
 )
 
 var FileMatcherGlob = "glob"
 
+// globFileMatcher matches the files of a marker with glob patterns, which may be file extensions or templates of the
+// marker variables of tmplx, e.g. {{.markerName}}. Only the fixed directory of each expanded pattern is listed, and
+// listings are shared for a short time so that the markers of a scan are matched against a single listing of their
+// directory.
 type globFileMatcher struct {
+	cache *fsx.DirCache
 }
 
 func (gm *globFileMatcher) Type() string {
@@ -24,7 +28,9 @@ func (gm *globFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConfi
 		return []string{}, nil
 	}
 
-	markerDir, markerName, _ := fsx.SplitFilePath(marker)
+	markerDir, _, _ := fsx.SplitFilePath(marker)
+	vars := tmplx.MarkerVars(marker, cfg.MarkerExt, cfg.RootDir)
+	markerName := vars[tmplx.VarMarkerName].(string)
 
 	// determine the matchers
 	var candidatePatterns []string
@@ -33,29 +39,21 @@ func (gm *globFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConfi
 		if core.IsFileExtension(tmplStr) { // it is a file extension like .rcd.gz or .log
 			candidatePattern = fsx.CombineFilePath(markerDir, markerName, tmplStr)
 		} else { // try it is as a template
-			tmpl, err := template.New("pattern").Parse(tmplStr)
+			rendered, err := tmplx.Render(tmplStr, vars)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse template for file extension '%s': %w", tmplStr, err)
+				return nil, fmt.Errorf("invalid glob pattern: %w", err)
 			}
 
-			var buf bytes.Buffer
-			err = tmpl.Execute(&buf, map[string]string{
-				TemplateVarMarkerName: markerName,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("failed to execute template for file extension '%s': %w", tmplStr, err)
-			}
-
-			candidatePattern = filepath.Join(markerDir, buf.String())
+			candidatePattern = filepath.Join(markerDir, rendered)
 		}
 
 		candidatePatterns = append(candidatePatterns, candidatePattern)
 
 	}
 
-	return fsx.MatchFilePatterns(markerDir, candidatePatterns, 1024)
+	return gm.cache.MatchGlob(candidatePatterns)
 }
 
 func NewGlobFileMatcher() FileMatcher {
-	return &globFileMatcher{}
+	return &globFileMatcher{cache: fsx.NewDirCache(fsx.DefaultDirCacheTTL)}
 }
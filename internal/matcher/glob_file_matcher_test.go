@@ -76,6 +76,13 @@ func TestGlobFileMatcher_MatchFiles(t *testing.T) {
 				fmt.Sprintf("%s/sidecar/test_marker_2.gz", tempDir),
 			},
 		},
+		{
+			name:     "data file derived with template helpers",
+			patterns: []string{`{{trimSuffix "_sig" .markerFile}}.gz`},
+			want: []string{
+				fmt.Sprintf("%s/test_marker.rcd.gz", tempDir),
+			},
+		},
 		{
 			name:     "no match",
 			patterns: []string{".notfound"},
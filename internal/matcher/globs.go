@@ -0,0 +1,48 @@
+package matcher
+
+import (
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
+	"strings"
+)
+
+// CandidateGlobs returns glob patterns, relative to the directory of a marker, matching the files that the file matcher
+// configurations can match for any marker, e.g. *.rcd.gz for the basic pattern .rcd.gz and sidecar/*_[0-9][0-9].gz for
+// the sequential pattern sidecar/{{.markerName}}_##.gz. Template variables are replaced by *.
+//
+// The patterns of regex and manifest matchers cannot be expressed as globs and are not included.
+func CandidateGlobs(configs []config.FileMatcherConfig) ([]string, error) {
+	vars := tmplx.MarkerVars("", "", "")
+	for k, v := range vars {
+		if _, ok := v.(string); ok {
+			vars[k] = "*"
+		}
+	}
+
+	var globs []string
+	for _, mc := range configs {
+		for _, pattern := range mc.Patterns {
+			rendered, err := tmplx.Render(pattern, vars)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern: %w", mc.MatcherType, err)
+			}
+
+			switch mc.MatcherType {
+			case FileMatcherBasic:
+				if rendered != "" && core.IsFileExtension(rendered) {
+					globs = append(globs, "*"+rendered)
+				}
+			case FileMatcherGlob:
+				globs = append(globs, rendered)
+			case FileMatcherSequential:
+				globs = append(globs, placeholderRegex.ReplaceAllStringFunc(rendered, func(s string) string {
+					return strings.Repeat("[0-9]", len(s))
+				}))
+			}
+		}
+	}
+
+	return globs, nil
+}
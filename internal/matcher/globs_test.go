@@ -0,0 +1,28 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+)
+
+func TestCandidateGlobs(t *testing.T) {
+	globs, err := CandidateGlobs([]config.FileMatcherConfig{
+		{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz", "{{.markerExt}}", ""}},
+		{MatcherType: FileMatcherGlob, Patterns: []string{"sidecar/{{.markerName}}_*.gz"}},
+		{MatcherType: FileMatcherSequential, Patterns: []string{"sidecar/{{.markerName}}_##.rcd.gz", "{{.markerName}}.evts"}},
+		{MatcherType: FileMatcherRegex, Patterns: []string{`{{.markerName}}_(?P<seq>\d{2})\.rcd\.gz`}},
+		{MatcherType: FileMatcherManifest},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"*.rcd.gz",
+		"sidecar/*_*.gz",
+		"sidecar/*_[0-9][0-9].rcd.gz",
+		"*.evts",
+	}, globs)
+
+	_, err = CandidateGlobs([]config.FileMatcherConfig{{MatcherType: FileMatcherGlob, Patterns: []string{"{{.unknown}}"}}})
+	require.ErrorContains(t, err, "invalid glob pattern")
+}
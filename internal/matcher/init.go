@@ -2,9 +2,6 @@ package matcher
 
 import "sync"
 
-// TemplateVarMarkerName is the template variable used to represent the marker file name in file extension patterns.
-const TemplateVarMarkerName = "markerName"
-
 var registerOnce sync.Once
 
 func init() {
@@ -12,5 +9,7 @@ func init() {
 		RegisterFileMatcher(NewBasicFileMatcher())
 		RegisterFileMatcher(NewGlobFileMatcher())
 		RegisterFileMatcher(NewSidecarFileMatcher())
+		RegisterFileMatcher(NewRegexFileMatcher())
+		RegisterFileMatcher(NewManifestFileMatcher())
 	})
 }
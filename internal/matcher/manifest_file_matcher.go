@@ -0,0 +1,231 @@
+package matcher
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var FileMatcherManifest = "manifest"
+
+// maxManifestSize bounds the size of a marker file parsed as a manifest.
+const maxManifestSize = 1 << 20
+
+// ManifestEntry is a file listed in a manifest.
+//
+// Fields:
+//   - Path: The path of the file, relative to the marker's directory unless absolute.
+//   - Size: The expected size of the file in bytes; not checked if not set.
+//   - Checksum: The expected checksum of the file as <algorithm>:<hex>, with algorithm sha256 or sha384; not checked if
+//     not set.
+type ManifestEntry struct {
+	Path     string `json:"path"`
+	Size     *int64 `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// manifest is the JSON form of a manifest.
+type manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// manifestFileMatcher reads the candidate files of a marker from the marker's content, which lists them either as
+// JSON ({"files": [{"path": ..., "size": ..., "checksum": ...}]} or a bare array of entries) or as one path per line,
+// ignoring blank lines and lines starting with #.
+//
+// The manifest is the authoritative candidate set: the patterns of the configuration are not used. Listed files that
+// do not exist yet are reported with an IncompleteError, so that the marker can be deferred; files whose size or
+// checksum differs from the manifest, and paths outside the root directory, are rejected with an error.
+type manifestFileMatcher struct{}
+
+func (mm *manifestFileMatcher) Type() string {
+	return FileMatcherManifest
+}
+
+func (mm *manifestFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConfig) ([]string, error) {
+	entries, err := ReadManifest(marker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	// paths are checked against the root directory as written, and once resolved against the resolved root directory
+	markerDir := filepath.Dir(marker)
+	root := filepath.Clean(cfg.RootDir)
+	if cfg.RootDir == "" {
+		root = markerDir
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root directory: %w", err)
+	}
+
+	seen := make(map[string]struct{})
+	var matches []string
+	var missing []string
+	for _, entry := range entries {
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(markerDir, path)
+		}
+		path = filepath.Clean(path)
+
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+
+		if !fsx.IsWithin(root, path) {
+			return nil, fmt.Errorf("manifest path %s escapes the root directory %s", entry.Path, root)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				missing = append(missing, fmt.Sprintf("manifest file %s does not exist", entry.Path))
+				continue
+			}
+			return nil, err
+		}
+
+		// the file may be a symlink pointing outside the root directory
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, err
+		}
+		if !fsx.IsWithin(resolvedRoot, resolved) {
+			return nil, fmt.Errorf("manifest path %s resolves to %s outside the root directory %s", entry.Path, resolved, root)
+		}
+
+		if err := checkManifestEntry(path, info, entry); err != nil {
+			return nil, err
+		}
+
+		logx.As().Debug().
+			Str("matcher", mm.Type()).
+			Str("marker", marker).
+			Str("candidate_file", path).
+			Msg("Found manifest file")
+		matches = append(matches, path)
+	}
+
+	if len(missing) > 0 {
+		return matches, &IncompleteError{Marker: marker, Missing: missing}
+	}
+
+	return matches, nil
+}
+
+// checkManifestEntry checks the size and checksum of the file against the manifest entry.
+func checkManifestEntry(path string, info os.FileInfo, entry ManifestEntry) error {
+	if entry.Size != nil && info.Size() != *entry.Size {
+		return fmt.Errorf("size of %s is %d, manifest expects %d", path, info.Size(), *entry.Size)
+	}
+
+	if entry.Checksum == "" {
+		return nil
+	}
+
+	algorithm, expected, ok := strings.Cut(entry.Checksum, ":")
+	if !ok {
+		return fmt.Errorf("invalid checksum '%s' of %s, expected <algorithm>:<hex>", entry.Checksum, entry.Path)
+	}
+
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	default:
+		return fmt.Errorf("unsupported checksum algorithm '%s' of %s", algorithm, entry.Path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to compute checksum of %s: %w", path, err)
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%s checksum of %s is %s, manifest expects %s", algorithm, path, actual, expected)
+	}
+
+	return nil
+}
+
+// ReadManifest parses the content of a marker file as a JSON or newline separated list of files.
+func ReadManifest(marker string) ([]ManifestEntry, error) {
+	f, err := os.Open(marker)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest %s is larger than %d bytes", marker, maxManifestSize)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var m manifest
+		if err := json.Unmarshal(trimmed, &m); err != nil {
+			return nil, fmt.Errorf("invalid JSON manifest %s: %w", marker, err)
+		}
+		return checkManifestPaths(marker, m.Files)
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		var entries []ManifestEntry
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("invalid JSON manifest %s: %w", marker, err)
+		}
+		return checkManifestPaths(marker, entries)
+	}
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, ManifestEntry{Path: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", marker, err)
+	}
+
+	return entries, nil
+}
+
+func checkManifestPaths(marker string, entries []ManifestEntry) ([]ManifestEntry, error) {
+	for i, entry := range entries {
+		if entry.Path == "" {
+			return nil, fmt.Errorf("entry %d of manifest %s has no path", i, marker)
+		}
+	}
+	return entries, nil
+}
+
+func NewManifestFileMatcher() FileMatcher {
+	return &manifestFileMatcher{}
+}
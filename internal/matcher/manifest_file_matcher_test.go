@@ -0,0 +1,116 @@
+package matcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+)
+
+func TestManifestFileMatcher_MatchFiles(t *testing.T) {
+	root := t.TempDir()
+	streamDir := filepath.Join(root, "recordStreams")
+	require.NoError(t, os.MkdirAll(filepath.Join(streamDir, "sidecar"), 0755))
+
+	files := map[string]string{
+		"recordStreams/data.rcd.gz":            "records",
+		"recordStreams/sidecar/data_01.rcd.gz": "sidecar",
+		"outside.txt":                          "outside",
+	}
+	for f, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, f), []byte(content), 0644))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(root, "outside.txt"), filepath.Join(streamDir, "link.txt")))
+
+	sum := sha256.Sum256([]byte("records"))
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		manifest   string
+		want       []string
+		errMsg     string
+		incomplete bool
+	}{
+		{
+			name:     "newline list",
+			manifest: "# companion files\ndata.rcd.gz\n\nsidecar/data_01.rcd.gz\ndata.rcd.gz\n",
+			want: []string{
+				filepath.Join(streamDir, "data.rcd.gz"),
+				filepath.Join(streamDir, "sidecar/data_01.rcd.gz"),
+			},
+		},
+		{
+			name:     "JSON with size and checksum",
+			manifest: `{"files": [{"path": "data.rcd.gz", "size": 7, "checksum": "` + checksum + `"}]}`,
+			want:     []string{filepath.Join(streamDir, "data.rcd.gz")},
+		},
+		{
+			name:     "JSON array",
+			manifest: `[{"path": "sidecar/data_01.rcd.gz"}]`,
+			want:     []string{filepath.Join(streamDir, "sidecar/data_01.rcd.gz")},
+		},
+		{
+			name:     "size mismatch",
+			manifest: `{"files": [{"path": "data.rcd.gz", "size": 8}]}`,
+			errMsg:   "manifest expects 8",
+		},
+		{
+			name:     "checksum mismatch",
+			manifest: `{"files": [{"path": "sidecar/data_01.rcd.gz", "checksum": "` + checksum + `"}]}`,
+			errMsg:   "sha256 checksum of",
+		},
+		{
+			name:     "unsupported checksum",
+			manifest: `{"files": [{"path": "data.rcd.gz", "checksum": "md5:abc"}]}`,
+			errMsg:   "unsupported checksum algorithm 'md5'",
+		},
+		{
+			name:     "path escapes root",
+			manifest: "../outside.txt\n",
+			errMsg:   "escapes the root directory",
+		},
+		{
+			name:     "symlink escapes root",
+			manifest: "link.txt\n",
+			errMsg:   "outside the root directory",
+		},
+		{
+			name:       "missing file",
+			manifest:   "data.rcd.gz\nmissing.rcd.gz\n",
+			want:       []string{filepath.Join(streamDir, "data.rcd.gz")},
+			incomplete: true,
+		},
+		{
+			name:     "entry without path",
+			manifest: `{"files": [{"size": 1}]}`,
+			errMsg:   "has no path",
+		},
+	}
+
+	matcher := NewManifestFileMatcher()
+	marker := filepath.Join(streamDir, "data.manifest")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(marker, []byte(tt.manifest), 0644))
+
+			got, err := matcher.MatchFiles(marker, config.FileMatcherConfig{MatcherType: FileMatcherManifest, RootDir: streamDir})
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			if tt.incomplete {
+				var incomplete *IncompleteError
+				require.ErrorAs(t, err, &incomplete)
+				require.Equal(t, []string{"manifest file missing.rcd.gz does not exist"}, incomplete.Missing)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
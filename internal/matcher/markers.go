@@ -0,0 +1,163 @@
+package matcher
+
+import (
+	"fmt"
+	"github.com/gobwas/glob"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Types of marker patterns.
+const (
+	MarkerExtension = "extension" // suffix of the file name, e.g. .rcd_sig or .rcd.gz
+	MarkerGlob      = "glob"      // glob of the path relative to the scanner directory, e.g. **/*_sig.json
+	MarkerRegex     = "regex"     // regular expression of the path relative to the scanner directory
+)
+
+// MarkerPattern is a compiled marker pattern with the file matchers of its marker files.
+type MarkerPattern struct {
+	Type     string
+	Pattern  string
+	Matchers []config.FileMatcherConfig
+
+	glob glob.Glob
+	re   *regexp.Regexp
+}
+
+// Match returns true if the file is a marker file of the pattern. The path is relative to the scanner directory.
+func (m *MarkerPattern) Match(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	switch m.Type {
+	case MarkerGlob:
+		// paths are also matched with a leading separator, so that **/ matches markers at the top of the directory too
+		return m.glob.Match(rel) || m.glob.Match("/"+rel)
+	case MarkerRegex:
+		return m.re.MatchString(rel)
+	default:
+		return strings.HasSuffix(filepath.Base(rel), m.Pattern)
+	}
+}
+
+// MarkerPatterns is the list of marker patterns of a scanner, matched in order.
+type MarkerPatterns []*MarkerPattern
+
+// Match returns the first marker pattern matching the file, or nil if the file is not a marker file. The path is
+// relative to the scanner directory.
+func (ms MarkerPatterns) Match(rel string) *MarkerPattern {
+	for _, m := range ms {
+		if m.Match(rel) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Lookup returns the marker pattern with the given pattern, or nil if there is none.
+func (ms MarkerPatterns) Lookup(pattern string) *MarkerPattern {
+	for _, m := range ms {
+		if m.Pattern == pattern {
+			return m
+		}
+	}
+	return nil
+}
+
+// Patterns returns the patterns of the marker patterns.
+func (ms MarkerPatterns) Patterns() []string {
+	patterns := make([]string, 0, len(ms))
+	for _, m := range ms {
+		patterns = append(patterns, m.Pattern)
+	}
+	return patterns
+}
+
+// NewMarkerPatterns compiles the marker patterns of a scanner: the file extension of the scanner if set, followed by
+// the additional marker patterns. Marker patterns without their own file matchers use the given file matchers.
+//
+// It returns an error if no marker pattern is configured, if a pattern is invalid or configured twice, or if the file
+// matchers of a marker pattern are invalid.
+func NewMarkerPatterns(ext string, markers []config.MarkerConfig, matchers []config.FileMatcherConfig) (MarkerPatterns, error) {
+	if ext != "" {
+		markers = append([]config.MarkerConfig{{Type: MarkerExtension, Pattern: ext}}, markers...)
+	}
+	if len(markers) == 0 {
+		return nil, fmt.Errorf("no marker pattern configured")
+	}
+
+	var patterns MarkerPatterns
+	for _, mc := range markers {
+		m, err := newMarkerPattern(mc, matchers)
+		if err != nil {
+			return nil, err
+		}
+		if patterns.Lookup(m.Pattern) != nil {
+			return nil, fmt.Errorf("marker pattern '%s' is configured more than once", m.Pattern)
+		}
+		patterns = append(patterns, m)
+	}
+
+	return patterns, nil
+}
+
+func newMarkerPattern(mc config.MarkerConfig, matchers []config.FileMatcherConfig) (*MarkerPattern, error) {
+	if mc.Pattern == "" {
+		return nil, fmt.Errorf("marker pattern must not be empty")
+	}
+
+	m := &MarkerPattern{Type: mc.Type, Pattern: mc.Pattern, Matchers: matchers}
+	if m.Type == "" {
+		m.Type = MarkerExtension
+	}
+
+	var err error
+	switch m.Type {
+	case MarkerExtension:
+		// if pattern contains '*' or '?', it is not an extension. Use the glob type instead
+		if !core.IsFileExtension(m.Pattern) || strings.ContainsAny(m.Pattern, "*?[/") {
+			return nil, fmt.Errorf("invalid marker file extension '%s'. use file extension without * or regex characters; i.e. '.rcd.gz'", m.Pattern)
+		}
+	case MarkerGlob:
+		m.glob, err = glob.Compile(filepath.ToSlash(m.Pattern), '/')
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile marker glob '%s': %w", m.Pattern, err)
+		}
+	case MarkerRegex:
+		m.re, err = regexp.Compile(m.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile marker regex '%s': %w", m.Pattern, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid marker type '%s', expected %s, %s or %s",
+			mc.Type, MarkerExtension, MarkerGlob, MarkerRegex)
+	}
+
+	if len(mc.FileMatcherConfigs) > 0 {
+		for _, fc := range mc.FileMatcherConfigs {
+			if _, err := GetFileMatcher(fc.MatcherType); err != nil {
+				return nil, fmt.Errorf("invalid file matcher configuration of marker '%s': %w", m.Pattern, err)
+			}
+			if err := CheckRequirement(fc); err != nil {
+				return nil, fmt.Errorf("invalid file matcher configuration of marker '%s': %w", m.Pattern, err)
+			}
+			if err := CheckSequence(fc); err != nil {
+				return nil, fmt.Errorf("invalid file matcher configuration of marker '%s': %w", m.Pattern, err)
+			}
+		}
+		m.Matchers = mc.FileMatcherConfigs
+	}
+
+	if m.Type == MarkerExtension {
+		// copy the configurations, the defaults are shared by all marker patterns
+		matchers := make([]config.FileMatcherConfig, len(m.Matchers))
+		for i, fc := range m.Matchers {
+			fc.MarkerExt = m.Pattern
+			matchers[i] = fc
+		}
+		m.Matchers = matchers
+	}
+
+	return m, nil
+}
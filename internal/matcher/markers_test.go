@@ -0,0 +1,87 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+)
+
+func TestMarkerPatterns_Match(t *testing.T) {
+	defaults := []config.FileMatcherConfig{{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz"}}}
+	own := []config.FileMatcherConfig{{MatcherType: FileMatcherGlob, Patterns: []string{"{{.markerName}}.json"}}}
+
+	markers, err := NewMarkerPatterns(".rcd_sig", []config.MarkerConfig{
+		{Pattern: ".evts.gz"},
+		{Type: MarkerGlob, Pattern: "**/balance*/*_sig.json", FileMatcherConfigs: own},
+		{Type: MarkerRegex, Pattern: `^node\d+/[^/]+\.done$`},
+	}, defaults)
+	require.NoError(t, err)
+	require.Equal(t, []string{".rcd_sig", ".evts.gz", "**/balance*/*_sig.json", `^node\d+/[^/]+\.done$`}, markers.Patterns())
+
+	tests := []struct {
+		rel     string
+		pattern string
+	}{
+		{"record0.0.3/2024-01-01T00_00_00.000000000Z.rcd_sig", ".rcd_sig"},
+		{"events0.0.3/2024-01-01T00_00_00.000000000Z.evts.gz", ".evts.gz"},
+		{"balance0.0.3/2024-01-01T00_00_00.000000000Z_sig.json", "**/balance*/*_sig.json"},
+		{"balance/2024-01-01T00_00_00.000000000Z_sig.json", "**/balance*/*_sig.json"},
+		{"node1/batch.done", `^node\d+/[^/]+\.done$`},
+		{"record0.0.3/2024-01-01T00_00_00.000000000Z.rcd.gz", ""},
+		{"events0.0.3/2024-01-01T00_00_00.000000000Z.gz", ""},
+		{"node1/sub/batch.done", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			m := markers.Match(tt.rel)
+			if tt.pattern == "" {
+				require.Nil(t, m)
+				return
+			}
+			require.NotNil(t, m)
+			require.Equal(t, tt.pattern, m.Pattern)
+		})
+	}
+
+	// extension markers strip their extension from the marker name
+	require.Equal(t, []config.FileMatcherConfig{{MatcherType: FileMatcherBasic, Patterns: []string{".rcd.gz"}, MarkerExt: ".evts.gz"}},
+		markers.Lookup(".evts.gz").Matchers)
+	require.Empty(t, defaults[0].MarkerExt)
+	require.Equal(t, defaults, markers.Lookup(`^node\d+/[^/]+\.done$`).Matchers)
+	require.Equal(t, own, markers.Lookup("**/balance*/*_sig.json").Matchers)
+	require.Nil(t, markers.Lookup(".unknown"))
+}
+
+func TestNewMarkerPatterns(t *testing.T) {
+	_, err := NewMarkerPatterns("", nil, nil)
+	require.ErrorContains(t, err, "no marker pattern configured")
+
+	_, err = NewMarkerPatterns("*.txt", nil, nil)
+	require.ErrorContains(t, err, "invalid marker file extension")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Pattern: ".r*"}}, nil)
+	require.ErrorContains(t, err, "invalid marker file extension")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Type: MarkerGlob, Pattern: "[a"}}, nil)
+	require.ErrorContains(t, err, "failed to compile marker glob")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Type: MarkerRegex, Pattern: "(a"}}, nil)
+	require.ErrorContains(t, err, "failed to compile marker regex")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Type: "suffix", Pattern: ".txt"}}, nil)
+	require.ErrorContains(t, err, "invalid marker type 'suffix'")
+
+	_, err = NewMarkerPatterns(".txt", []config.MarkerConfig{{Pattern: ".txt"}}, nil)
+	require.ErrorContains(t, err, "configured more than once")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Pattern: ".txt", FileMatcherConfigs: []config.FileMatcherConfig{
+		{MatcherType: FileMatcherBasic, Requirement: "always"},
+	}}}, nil)
+	require.ErrorContains(t, err, "invalid file matcher configuration of marker '.txt'")
+
+	_, err = NewMarkerPatterns("", []config.MarkerConfig{{Pattern: ".txt", FileMatcherConfigs: []config.FileMatcherConfig{
+		{MatcherType: "unknown"},
+	}}}, nil)
+	require.ErrorContains(t, err, "invalid file matcher configuration of marker '.txt'")
+}
@@ -0,0 +1,177 @@
+package matcher
+
+import (
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var FileMatcherRegex = "regex"
+
+// Named capture groups with a special meaning in regex patterns.
+const (
+	CaptureSeq  = "seq"  // numeric sequence number; matches are sorted by it and must form a sequence from 1
+	CaptureSort = "sort" // sort key; matches are sorted by it lexicographically
+)
+
+// regexFileMatcher matches the file names of the configured directories against regular expressions.
+//
+// Patterns are templates of the marker variables of tmplx, whose string values are quoted, so that the sidecars of
+// 2024-01-01T00_00_00.000000000Z.rcd_sig are matched by `{{.markerName}}_(?P<seq>\d{2})\.rcd\.gz`. Patterns match the
+// whole file name. Directories are relative to the marker's directory; default is the marker's directory itself.
+//
+// Named captures:
+//   - seq: matches are sorted by the sequence number, which must start at 1; matching stops at the first missing
+//     number, and numbers above MaxSequence are ignored if it is set.
+//   - sort: matches are sorted by the captured value.
+//
+// Matches of a pattern without named captures are sorted by path.
+type regexFileMatcher struct {
+	cache *fsx.DirCache
+}
+
+// regexMatch is a file matched by a regex pattern with its named captures.
+type regexMatch struct {
+	path string
+	seq  int
+	key  string
+}
+
+func (rm *regexFileMatcher) Type() string {
+	return FileMatcherRegex
+}
+
+func (rm *regexFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConfig) ([]string, error) {
+	if len(cfg.Patterns) == 0 {
+		return []string{}, nil
+	}
+
+	markerDir, _, _ := fsx.SplitFilePath(marker)
+
+	// variables are literal text in the regex
+	vars := tmplx.MarkerVars(marker, cfg.MarkerExt, cfg.RootDir)
+	for k, v := range vars {
+		if s, ok := v.(string); ok {
+			vars[k] = regexp.QuoteMeta(s)
+		}
+	}
+
+	dirs := cfg.Directories
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	var matches []string
+	for _, tmplStr := range cfg.Patterns {
+		re, err := compileRegexTemplate(tmplStr, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dir := range dirs {
+			found, err := rm.matchDir(filepath.Join(markerDir, dir), re, cfg.MaxSequence)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, found...)
+		}
+	}
+
+	return matches, nil
+}
+
+// matchDir returns the files of the directory whose name matches the regex, sorted and validated by its named captures.
+func (rm *regexFileMatcher) matchDir(dir string, re *regexp.Regexp, maxSequence int) ([]string, error) {
+	entries, err := rm.cache.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory '%s': %w", dir, err)
+	}
+
+	seqIndex := re.SubexpIndex(CaptureSeq)
+	sortIndex := re.SubexpIndex(CaptureSort)
+
+	var found []regexMatch
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		groups := re.FindStringSubmatch(entry.Name())
+		if groups == nil {
+			continue
+		}
+
+		m := regexMatch{path: filepath.Join(dir, entry.Name())}
+		if seqIndex >= 0 {
+			m.seq, err = strconv.Atoi(groups[seqIndex])
+			if err != nil {
+				return nil, fmt.Errorf("capture %s of %s is not a number: %w", CaptureSeq, m.path, err)
+			}
+			if m.seq < 1 || (maxSequence > 0 && m.seq > maxSequence) {
+				logx.As().Debug().
+					Str("matcher", rm.Type()).
+					Str("candidate_file", m.path).
+					Int("seq", m.seq).
+					Int("max_sequence", maxSequence).
+					Msg("Skipping candidate file with sequence number out of range")
+				continue
+			}
+		}
+		if sortIndex >= 0 {
+			m.key = groups[sortIndex]
+		}
+
+		found = append(found, m)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].seq != found[j].seq {
+			return found[i].seq < found[j].seq
+		}
+		if found[i].key != found[j].key {
+			return found[i].key < found[j].key
+		}
+		return found[i].path < found[j].path
+	})
+
+	var matches []string
+	for i, m := range found {
+		if seqIndex >= 0 && m.seq != i+1 {
+			logx.As().Debug().
+				Str("matcher", rm.Type()).
+				Str("candidate_file", m.path).
+				Int("seq", m.seq).
+				Int("expected_seq", i+1).
+				Msg("Stopping at sequence gap")
+			break // files are numbered sequentially, so later files are out of sequence
+		}
+		matches = append(matches, m.path)
+	}
+
+	return matches, nil
+}
+
+// compileRegexTemplate executes the pattern template and compiles the result so that it matches whole file names.
+func compileRegexTemplate(tmplStr string, vars map[string]any) (*regexp.Regexp, error) {
+	rendered, err := tmplx.Render(tmplStr, vars)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	re, err := regexp.Compile("^(?:" + rendered + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regex pattern '%s': %w", rendered, err)
+	}
+
+	return re, nil
+}
+
+func NewRegexFileMatcher() FileMatcher {
+	return &regexFileMatcher{cache: fsx.NewDirCache(fsx.DefaultDirCacheTTL)}
+}
@@ -0,0 +1,99 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+)
+
+func TestRegexFileMatcher_MatchFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	markerPath := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	require.NoError(t, os.WriteFile(markerPath, []byte("marker"), 0644))
+
+	files := []string{
+		"2024-01-01T00_00_00.000000000Z.rcd.gz",
+		"2024-01-01T00_00_00X000000000Z.rcd.gz", // a dot of the marker name must not match any character
+		"sidecar/2024-01-01T00_00_00.000000000Z_02.rcd.gz",
+		"sidecar/2024-01-01T00_00_00.000000000Z_01.rcd.gz",
+		"sidecar/2024-01-01T00_00_00.000000000Z_03.rcd.gz",
+		"sidecar/2024-01-01T00_00_00.000000000Z_05.rcd.gz", // out of sequence
+		"sidecar/2024-01-01T00_00_02.000000000Z_01.rcd.gz", // another marker
+		"logs/node-b.log",
+		"logs/node-a.log",
+	}
+	for _, f := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tempDir, f)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, f), []byte("data"), 0644))
+	}
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		directories []string
+		maxSequence int
+		want        []string
+		errMsg      string
+	}{
+		{
+			name:     "data and marker file",
+			patterns: []string{`{{.markerName}}\.rcd\.gz`, `{{.markerName}}{{.markerExt}}`},
+			want: []string{
+				filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz"),
+				markerPath,
+			},
+		},
+		{
+			name:        "sidecars in sequence",
+			patterns:    []string{`{{.markerName}}_(?P<seq>\d{2})\.rcd\.gz`},
+			directories: []string{"sidecar"},
+			want: []string{
+				filepath.Join(tempDir, "sidecar/2024-01-01T00_00_00.000000000Z_01.rcd.gz"),
+				filepath.Join(tempDir, "sidecar/2024-01-01T00_00_00.000000000Z_02.rcd.gz"),
+				filepath.Join(tempDir, "sidecar/2024-01-01T00_00_00.000000000Z_03.rcd.gz"),
+			},
+		},
+		{
+			name:        "sidecars within maximum sequence",
+			patterns:    []string{`{{.markerName}}_(?P<seq>\d{2})\.rcd\.gz`},
+			directories: []string{"sidecar"},
+			maxSequence: 2,
+			want: []string{
+				filepath.Join(tempDir, "sidecar/2024-01-01T00_00_00.000000000Z_01.rcd.gz"),
+				filepath.Join(tempDir, "sidecar/2024-01-01T00_00_00.000000000Z_02.rcd.gz"),
+			},
+		},
+		{
+			name:        "sorted by capture",
+			patterns:    []string{`node-(?P<sort>[a-z])\.log`},
+			directories: []string{"logs", "missing"},
+			want: []string{
+				filepath.Join(tempDir, "logs/node-a.log"),
+				filepath.Join(tempDir, "logs/node-b.log"),
+			},
+		},
+		{
+			name:     "invalid regex",
+			patterns: []string{`{{.markerName}}(`},
+			errMsg:   "failed to compile regex pattern",
+		},
+	}
+
+	matcher := NewRegexFileMatcher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FileMatcherConfig{Patterns: tt.patterns, Directories: tt.directories, MaxSequence: tt.maxSequence}
+			got, err := matcher.MatchFiles(markerPath, cfg)
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
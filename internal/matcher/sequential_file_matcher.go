@@ -5,26 +5,51 @@ import (
 	"golang.hedera.com/solo-cheetah/internal/config"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 var FileMatcherSequential = "sequential"
 
-type sequentialFileMatcher struct{}
+// Actions of a sequential matcher on files numbered after the end of a sequence.
+const (
+	GapStop  = "stop"  // match the files before the gap and report the later files as orphans
+	GapDefer = "defer" // report the marker as incomplete until the gap is filled
+)
+
+// placeholderRegex matches the digit placeholders of sequential patterns.
+var placeholderRegex = regexp.MustCompile(`#+`)
+
+type sequentialFileMatcher struct {
+	cache *fsx.DirCache
+}
+
+// sequence is the numbering of the files of a sequential pattern.
+type sequence struct {
+	start    int
+	maxCount int
+	maxGap   int
+	onGap    string
+}
 
 func (sm *sequentialFileMatcher) Type() string {
 	return FileMatcherSequential
 }
 
 // MatchFiles returns a list of sidecar file paths that match the given patterns for a marker file.
-// It supports patterns with digit placeholders:
+// Patterns are templates of the marker variables of tmplx, e.g. {{.markerName}}, with digit placeholders:
 //   - "##.gz": matches files with two-digit sequence numbers (e.g., marker_01.gz, marker_02.gz, ...)
 //   - "#.gz": matches files with one-digit sequence numbers (e.g., marker_1.gz, marker_2.gz, ...)
 //
-// For each pattern, files are collected in sequence starting from 1, stopping at the first missing file.
+// For each pattern, files are collected in sequence starting from the configured start (1 by default), skipping up
+// to MaxGap consecutive missing numbers and stopping after MaxCount files if set. Files numbered after the end of the
+// sequence are orphans: they are logged, or, with OnGap defer, reported with an IncompleteError so that the marker is
+// deferred until the gap is filled.
 // The returned paths are relative to the marker's directory.
 // If no patterns are provided, it returns an empty slice.
 func (sm *sequentialFileMatcher) MatchFiles(marker string, cfg config.FileMatcherConfig) ([]string, error) {
@@ -32,17 +57,22 @@ func (sm *sequentialFileMatcher) MatchFiles(marker string, cfg config.FileMatche
 		return []string{}, nil
 	}
 
-	markerDir, markerName, _ := fsx.SplitFilePath(marker)
+	markerDir, _, _ := fsx.SplitFilePath(marker)
+	vars := tmplx.MarkerVars(marker, cfg.MarkerExt, cfg.RootDir)
+	seq := newSequence(cfg.Sequence)
 
 	var matches []string
-	re := regexp.MustCompile(`#+`)
+	var missing []string
 
 	for _, pattern := range cfg.Patterns {
-		pattern = strings.ReplaceAll(pattern, "{{.markerName}}", markerName)
+		pattern, err := tmplx.Render(pattern, vars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequential pattern: %w", err)
+		}
 
 		// Replace sequences of # with corresponding %0Nd
 		isSequenced := false
-		filePattern := re.ReplaceAllStringFunc(pattern, func(s string) string {
+		filePattern := placeholderRegex.ReplaceAllStringFunc(pattern, func(s string) string {
 			isSequenced = true
 			return fmt.Sprintf("%%0%dd", len(s))
 		})
@@ -55,33 +85,175 @@ func (sm *sequentialFileMatcher) MatchFiles(marker string, cfg config.FileMatche
 			continue
 		}
 
-		// search for linearly named files like file_01, file_02, etc.
-		var i int
-		for {
-			fileName := fmt.Sprintf(filePattern, i+1)
-			candidateFile := filepath.Join(markerDir, fileName)
-			logx.As().Debug().
-				Str("matcher", sm.Type()).
-				Str("file_pattern", filePattern).
-				Str("candidate_file", candidateFile).
-				Msg("Checking if candidate file exists")
-			if _, err := os.Stat(candidateFile); err == nil {
-				logx.As().Debug().
-					Str("matcher", sm.Type()).
-					Str("file_pattern", filePattern).
-					Str("candidate_file", candidateFile).
-					Msg("Found candidate file")
-				matches = append(matches, candidateFile)
-				i++
-			} else {
-				break // Stop searching if a file does not exist, as files are sequentially named.
-			}
+		found, last, complete := sm.matchSequence(markerDir, filePattern, seq)
+		matches = append(matches, found...)
+
+		orphans, err := sm.orphans(markerDir, pattern, filePattern, last)
+		if err != nil {
+			return nil, err
+		}
+		if len(orphans) == 0 {
+			continue
 		}
+
+		if seq.onGap == GapDefer && !complete {
+			missing = append(missing, fmt.Sprintf("pattern %s has a gap after number %d before %s",
+				pattern, last, strings.Join(orphans, ", ")))
+			continue
+		}
+
+		logx.As().Warn().
+			Str("matcher", sm.Type()).
+			Str("marker", marker).
+			Str("file_pattern", filePattern).
+			Int("last_seq", last).
+			Strs("orphans", orphans).
+			Msg("Found files numbered after the end of the sequence")
+	}
+
+	if len(missing) > 0 {
+		return matches, &IncompleteError{Marker: marker, Missing: missing}
 	}
 
 	return matches, nil
 }
 
+// matchSequence collects the files of the pattern in sequence. It returns the files, the last number found (start - 1
+// if none) and whether the sequence ended because MaxCount files were found rather than at a gap.
+func (sm *sequentialFileMatcher) matchSequence(markerDir string, filePattern string, seq sequence) ([]string, int, bool) {
+	var found []string
+	last := seq.start - 1
+	misses := 0
+
+	// search for linearly named files like file_01, file_02, etc.
+	for i := seq.start; seq.maxCount == 0 || len(found) < seq.maxCount; i++ {
+		fileName := fmt.Sprintf(filePattern, i)
+		candidateFile := filepath.Join(markerDir, fileName)
+		logx.As().Debug().
+			Str("matcher", sm.Type()).
+			Str("file_pattern", filePattern).
+			Str("candidate_file", candidateFile).
+			Msg("Checking if candidate file exists")
+		if _, err := os.Stat(candidateFile); err != nil {
+			misses++
+			if misses > seq.maxGap {
+				return found, last, false // Stop searching after the tolerated gap, as files are sequentially named.
+			}
+			continue
+		}
+
+		logx.As().Debug().
+			Str("matcher", sm.Type()).
+			Str("file_pattern", filePattern).
+			Str("candidate_file", candidateFile).
+			Msg("Found candidate file")
+		found = append(found, candidateFile)
+		last = i
+		misses = 0
+	}
+
+	return found, last, true
+}
+
+// orphans returns the files of the pattern's directory numbered after the last number of the sequence, sorted by
+// number. Placeholders in directory names are not supported, so such patterns have no orphans.
+func (sm *sequentialFileMatcher) orphans(markerDir string, pattern string, filePattern string, last int) ([]string, error) {
+	dir, base := filepath.Split(pattern)
+	if placeholderRegex.MatchString(dir) {
+		return nil, nil
+	}
+
+	parts := placeholderRegex.Split(base, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, `(\d+)`) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid sequential pattern %s: %w", pattern, err)
+	}
+
+	dir = filepath.Join(markerDir, dir)
+	entries, err := sm.cache.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory '%s': %w", dir, err)
+	}
+
+	numbered := make(map[int]string)
+	for _, entry := range entries {
+		groups := re.FindStringSubmatch(entry.Name())
+		if groups == nil {
+			continue
+		}
+
+		n, err := strconv.Atoi(groups[1])
+		if err != nil || n <= last {
+			continue
+		}
+
+		// the name must be the one of its number, e.g. marker_099.gz is not number 99 of marker_##.gz
+		path := filepath.Join(markerDir, fmt.Sprintf(filePattern, n))
+		if path == filepath.Join(dir, entry.Name()) {
+			numbered[n] = path
+		}
+	}
+
+	numbers := make([]int, 0, len(numbered))
+	for n := range numbered {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	orphans := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		orphans = append(orphans, numbered[n])
+	}
+
+	return orphans, nil
+}
+
+// CheckSequence returns an error if the sequence of the file matcher configuration is invalid.
+func CheckSequence(cfg config.FileMatcherConfig) error {
+	if cfg.Sequence == nil {
+		return nil
+	}
+
+	s := cfg.Sequence
+	if s.Start != nil && *s.Start < 0 {
+		return fmt.Errorf("sequence start must not be negative, got %d", *s.Start)
+	}
+	if s.MaxCount < 0 {
+		return fmt.Errorf("sequence maxCount must not be negative, got %d", s.MaxCount)
+	}
+	if s.MaxGap < 0 {
+		return fmt.Errorf("sequence maxGap must not be negative, got %d", s.MaxGap)
+	}
+
+	switch s.OnGap {
+	case "", GapStop, GapDefer:
+		return nil
+	default:
+		return fmt.Errorf("invalid sequence onGap '%s', expected %s or %s", s.OnGap, GapStop, GapDefer)
+	}
+}
+
+func newSequence(cfg *config.SequenceConfig) sequence {
+	seq := sequence{start: 1, onGap: GapStop}
+	if cfg == nil {
+		return seq
+	}
+
+	if cfg.Start != nil {
+		seq.start = *cfg.Start
+	}
+	seq.maxCount = cfg.MaxCount
+	seq.maxGap = cfg.MaxGap
+	if cfg.OnGap != "" {
+		seq.onGap = cfg.OnGap
+	}
+
+	return seq
+}
+
 func NewSidecarFileMatcher() FileMatcher {
-	return &sequentialFileMatcher{}
+	return &sequentialFileMatcher{cache: fsx.NewDirCache(fsx.DefaultDirCacheTTL)}
 }
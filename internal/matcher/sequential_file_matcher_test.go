@@ -81,3 +81,110 @@ func TestSidecarFileMatcher_MatchFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestSidecarFileMatcher_Sequence(t *testing.T) {
+	tempDir := t.TempDir()
+	markerPath := filepath.Join(tempDir, "test_marker.rcd_sig")
+	require.NoError(t, os.WriteFile(markerPath, []byte("marker"), 0644))
+
+	// number 03 is missing
+	for _, f := range []string{"test_marker_00.gz", "test_marker_01.gz", "test_marker_02.gz", "test_marker_04.gz", "test_marker_05.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, f), []byte("data"), 0644))
+	}
+	file := func(n int) string {
+		return filepath.Join(tempDir, fmt.Sprintf("test_marker_%02d.gz", n))
+	}
+	start := func(n int) *int {
+		return &n
+	}
+
+	tests := []struct {
+		name       string
+		sequence   *config.SequenceConfig
+		want       []string
+		incomplete bool
+	}{
+		{
+			name: "default stops at the gap",
+			want: []string{file(1), file(2)},
+		},
+		{
+			name:     "start at 0",
+			sequence: &config.SequenceConfig{Start: start(0)},
+			want:     []string{file(0), file(1), file(2)},
+		},
+		{
+			name:     "max count",
+			sequence: &config.SequenceConfig{Start: start(0), MaxCount: 2},
+			want:     []string{file(0), file(1)},
+		},
+		{
+			name:     "gap tolerance",
+			sequence: &config.SequenceConfig{MaxGap: 1},
+			want:     []string{file(1), file(2), file(4), file(5)},
+		},
+		{
+			name:       "defer on gap",
+			sequence:   &config.SequenceConfig{OnGap: GapDefer},
+			want:       []string{file(1), file(2)},
+			incomplete: true,
+		},
+		{
+			name:     "defer on gap within max count",
+			sequence: &config.SequenceConfig{MaxCount: 2, OnGap: GapDefer},
+			want:     []string{file(1), file(2)},
+		},
+		{
+			name:     "defer on gap within tolerance",
+			sequence: &config.SequenceConfig{MaxGap: 1, OnGap: GapDefer},
+			want:     []string{file(1), file(2), file(4), file(5)},
+		},
+	}
+
+	matcher := NewSidecarFileMatcher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FileMatcherConfig{Patterns: []string{"{{.markerName}}_##.gz"}, Sequence: tt.sequence}
+			got, err := matcher.MatchFiles(markerPath, cfg)
+			if tt.incomplete {
+				var incomplete *IncompleteError
+				require.ErrorAs(t, err, &incomplete)
+				require.Len(t, incomplete.Missing, 1)
+				require.Contains(t, incomplete.Missing[0], file(4))
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSidecarFileMatcher_Orphans(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "sidecar"), 0755))
+	for _, f := range []string{"m_01.gz", "m_03.gz", "m_010.gz", "m_10.gz", "m_1.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, "sidecar", f), []byte("data"), 0644))
+	}
+
+	sm := NewSidecarFileMatcher().(*sequentialFileMatcher)
+	orphans, err := sm.orphans(tempDir, "sidecar/m_##.gz", "sidecar/m_%02d.gz", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(tempDir, "sidecar", "m_03.gz"),
+		filepath.Join(tempDir, "sidecar", "m_10.gz"),
+	}, orphans)
+
+	orphans, err = sm.orphans(tempDir, "missing/m_##.gz", "missing/m_%02d.gz", 1)
+	require.NoError(t, err)
+	require.Empty(t, orphans)
+}
+
+func TestCheckSequence(t *testing.T) {
+	start := -1
+	require.NoError(t, CheckSequence(config.FileMatcherConfig{}))
+	require.NoError(t, CheckSequence(config.FileMatcherConfig{Sequence: &config.SequenceConfig{MaxGap: 2, OnGap: GapDefer}}))
+	require.ErrorContains(t, CheckSequence(config.FileMatcherConfig{Sequence: &config.SequenceConfig{Start: &start}}), "start must not be negative")
+	require.ErrorContains(t, CheckSequence(config.FileMatcherConfig{Sequence: &config.SequenceConfig{MaxCount: -1}}), "maxCount must not be negative")
+	require.ErrorContains(t, CheckSequence(config.FileMatcherConfig{Sequence: &config.SequenceConfig{MaxGap: -1}}), "maxGap must not be negative")
+	require.ErrorContains(t, CheckSequence(config.FileMatcherConfig{Sequence: &config.SequenceConfig{OnGap: "skip"}}), "invalid sequence onGap 'skip'")
+}
@@ -0,0 +1,267 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Namespace is the prefix of all metric names exposed by cheetah.
+const Namespace = "cheetah"
+
+// DefaultPath is the default HTTP path where metrics are served.
+const DefaultPath = "/metrics"
+
+// Error classes used as the value of the "class" label of ErrorsTotal.
+const (
+	ErrorClassScan           = "scan"
+	ErrorClassMarkerNotReady = "marker_not_ready"
+	ErrorClassMatch          = "match"
+	ErrorClassUpload         = "upload"
+	ErrorClassRemove         = "remove"
+	ErrorClassValidation     = "validation"
+	ErrorClassChain          = "chain"
+	ErrorClassSignature      = "signature"
+	ErrorClassIncomplete     = "incomplete"
+	ErrorClassOrphan         = "orphan"
+)
+
+// Retry reasons used as the value of the "reason" label of RetriesTotal.
+const (
+	RetryReasonMarkerCheck   = "marker_check"
+	RetryReasonUploadBackoff = "upload_backoff"
+	RetryReasonIncomplete    = "incomplete"
+)
+
+// MetricsConfig holds the configuration for the metrics endpoint.
+type MetricsConfig struct {
+	// Enabled indicates whether the metrics endpoint is enabled.
+	Enabled bool
+	// ServerHost is the host for the metrics server.
+	ServerHost string
+	// ServerPort is the port for the metrics server.
+	ServerPort int
+	// Path is the HTTP path where metrics are served. Default is /metrics.
+	Path string
+}
+
+var (
+	registry = prometheus.NewRegistry()
+	factory  = promauto.With(registry)
+
+	serverMu     sync.Mutex
+	serverCancel context.CancelFunc
+)
+
+var (
+	// MarkersDiscovered counts the marker files found by the scanner.
+	MarkersDiscovered = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "markers_discovered_total",
+		Help:      "Total number of marker files discovered by the scanner.",
+	}, []string{"pipeline"})
+
+	// FilesUploaded counts the files successfully synced to a storage, including checksum skips.
+	FilesUploaded = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "files_uploaded_total",
+		Help:      "Total number of files synced to a storage.",
+	}, []string{"pipeline", "storage"})
+
+	// BytesUploaded counts the bytes successfully synced to a storage, including checksum skips.
+	BytesUploaded = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bytes_uploaded_total",
+		Help:      "Total number of bytes synced to a storage.",
+	}, []string{"pipeline", "storage"})
+
+	// UploadDuration observes the time taken to sync a single file to a storage.
+	UploadDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "upload_duration_seconds",
+		Help:      "Time taken to sync a single file to a storage.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14), // 5ms to ~41s
+	}, []string{"pipeline", "storage"})
+
+	// ChecksumSkips counts the uploads skipped because the destination already had the same checksum.
+	ChecksumSkips = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "checksum_skips_total",
+		Help:      "Total number of uploads skipped because the destination already had the same checksum.",
+	}, []string{"pipeline", "storage"})
+
+	// ErrorsTotal counts errors by pipeline and class (e.g. scan, upload, remove).
+	ErrorsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "errors_total",
+		Help:      "Total number of errors by class.",
+	}, []string{"pipeline", "class"})
+
+	// RetriesTotal counts retries by pipeline and reason (e.g. marker_check, upload_backoff).
+	RetriesTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "retries_total",
+		Help:      "Total number of retries by reason.",
+	}, []string{"pipeline", "reason"})
+
+	// ScanDuration observes the time taken by a full scan of the pipeline directory.
+	ScanDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "scan_duration_seconds",
+		Help:      "Time taken to scan the pipeline directory.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10), // 1ms to ~262s
+	}, []string{"pipeline"})
+
+	// QueueDepth reports the number of markers discovered but not yet picked up by a processor.
+	QueueDepth = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "queue_depth",
+		Help:      "Number of marker files discovered but not yet picked up by a processor.",
+	}, []string{"pipeline"})
+
+	// MarkerAge observes the age of a marker file (based on its modification time) when it is uploaded.
+	MarkerAge = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "marker_age_seconds",
+		Help:      "Age of marker files at the time of upload.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 3, 12), // 100ms to ~5h
+	}, []string{"pipeline"})
+
+	// CommitStalls counts the marker files published out of order because an older marker was stuck.
+	CommitStalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ordered_commit_stalls_total",
+		Help:      "Total number of marker files published out of order after the head-of-line timeout.",
+	}, []string{"pipeline"})
+
+	// GapsDetected counts the gaps found between the consensus timestamps of uploaded marker files.
+	GapsDetected = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "stream_gaps_detected_total",
+		Help:      "Total number of gaps detected between consecutive uploaded stream files.",
+	}, []string{"pipeline"})
+
+	// ChainBreaks counts the stream files whose start running hash is not the end running hash of the previous file.
+	ChainBreaks = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "chain_breaks_total",
+		Help:      "Total number of stream files breaking the running hash chain of their directory.",
+	}, []string{"pipeline"})
+
+	// SignatureFailures counts the signature files that failed verification against the node public keys.
+	SignatureFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "signature_failures_total",
+		Help:      "Total number of signature files that failed verification against the node public keys.",
+	}, []string{"pipeline"})
+
+	// OpenGaps reports the number of detected gaps that have not been filled by a late upload.
+	OpenGaps = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "stream_open_gaps",
+		Help:      "Number of detected gaps between uploaded stream files that have not been filled.",
+	}, []string{"pipeline"})
+
+	// OrphanFiles reports the orphaned files found by the last orphan sweep by kind (candidate or marker).
+	OrphanFiles = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "orphan_files",
+		Help:      "Number of orphaned files found by the last orphan sweep of the scanner directory.",
+	}, []string{"pipeline", "kind"})
+
+	// OrphansHandled counts the orphaned candidate files uploaded or moved by the orphan sweep.
+	OrphansHandled = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "orphans_handled_total",
+		Help:      "Total number of orphaned candidate files uploaded or moved by the orphan sweep.",
+	}, []string{"pipeline", "action"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Registry returns the registry holding all cheetah metrics.
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler returns an HTTP handler serving all cheetah metrics in Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// Start starts the metrics server if it is enabled in the configuration.
+// The server is shut down once the context is canceled or Stop is called.
+func Start(ctx context.Context, cfg MetricsConfig) error {
+	if ctx == nil {
+		return fmt.Errorf("metrics context is nil")
+	}
+
+	if !cfg.Enabled {
+		logx.As().Debug().Msg("Metrics are disabled")
+		return nil
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath
+	}
+
+	serverMu.Lock()
+	defer serverMu.Unlock()
+	if serverCancel != nil {
+		return fmt.Errorf("metrics server is already running")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	serverCancel = cancel
+
+	mux := http.NewServeMux()
+	mux.Handle(path, Handler())
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.ServerHost, cfg.ServerPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logx.As().Info().Msg(fmt.Sprintf("Starting metrics server on %s%s", server.Addr, path))
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logx.As().Error().Err(err).Msg("metrics server failed")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		logx.As().Info().Msg("Shutting down metrics server...")
+		if err := server.Shutdown(context.Background()); err != nil {
+			logx.As().Error().Err(err).Msg("Failed to shut down metrics server")
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the metrics server if it is running.
+func Stop() {
+	serverMu.Lock()
+	defer serverMu.Unlock()
+
+	if serverCancel != nil {
+		serverCancel()
+		serverCancel = nil
+	}
+}
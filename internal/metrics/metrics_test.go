@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	MarkersDiscovered.WithLabelValues("handler-pipeline").Add(3)
+	BytesUploaded.WithLabelValues("handler-pipeline", "S3").Add(1024)
+	UploadDuration.WithLabelValues("handler-pipeline", "S3").Observe(0.25)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	require.Contains(t, body, `cheetah_markers_discovered_total{pipeline="handler-pipeline"} 3`)
+	require.Contains(t, body, `cheetah_bytes_uploaded_total{pipeline="handler-pipeline",storage="S3"} 1024`)
+	require.Contains(t, body, `cheetah_upload_duration_seconds_count{pipeline="handler-pipeline",storage="S3"} 1`)
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestCounters(t *testing.T) {
+	ErrorsTotal.WithLabelValues("counter-pipeline", ErrorClassUpload).Inc()
+	ErrorsTotal.WithLabelValues("counter-pipeline", ErrorClassUpload).Inc()
+	RetriesTotal.WithLabelValues("counter-pipeline", RetryReasonUploadBackoff).Inc()
+	QueueDepth.WithLabelValues("counter-pipeline").Inc()
+	QueueDepth.WithLabelValues("counter-pipeline").Inc()
+	QueueDepth.WithLabelValues("counter-pipeline").Dec()
+
+	require.Equal(t, float64(2), testutil.ToFloat64(ErrorsTotal.WithLabelValues("counter-pipeline", ErrorClassUpload)))
+	require.Equal(t, float64(0), testutil.ToFloat64(ErrorsTotal.WithLabelValues("counter-pipeline", ErrorClassRemove)))
+	require.Equal(t, float64(1), testutil.ToFloat64(RetriesTotal.WithLabelValues("counter-pipeline", RetryReasonUploadBackoff)))
+	require.Equal(t, float64(1), testutil.ToFloat64(QueueDepth.WithLabelValues("counter-pipeline")))
+}
+
+func TestStartAndStop(t *testing.T) {
+	// disabled metrics should not start the server
+	require.NoError(t, Start(context.Background(), MetricsConfig{Enabled: false}))
+
+	// find a free port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := MetricsConfig{Enabled: true, ServerHost: "127.0.0.1", ServerPort: port}
+	require.NoError(t, Start(ctx, cfg))
+	defer Stop()
+
+	// a second server should not be started
+	require.Error(t, Start(ctx, cfg))
+
+	url := fmt.Sprintf("http://127.0.0.1:%d%s", port, DefaultPath)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		body, err := io.ReadAll(resp.Body)
+		return err == nil && resp.StatusCode == http.StatusOK && strings.Contains(string(body), "cheetah_")
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,506 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"golang.hedera.com/solo-cheetah/pkg/tmplx"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultConcurrency is the default number of objects copied in parallel.
+const DefaultConcurrency = 8
+
+// checkpointInterval is the number of completed objects between two writes of the checkpoint file.
+const checkpointInterval = 100
+
+// Template variables available in key templates, in addition to the file variables and helper functions of tmplx
+// (e.g. nodeId, year, month, day).
+const (
+	TemplateVarKey      = "key"      // key relative to the source path prefix, e.g. record0.0.3/file.rcd.gz
+	TemplateVarDir      = "dir"      // directory of the relative key, e.g. record0.0.3
+	TemplateVarFileName = "fileName" // file name of the key, e.g. file.rcd.gz
+)
+
+// Methods used to copy an object.
+const (
+	MethodCopy     = "copy"     // server-side copy
+	MethodDownload = "download" // download from the source and upload to the destination
+)
+
+// Options holds the options for migrating objects between two storages.
+type Options struct {
+	// SrcPathPrefix is the path prefix of the source storage, stripped from the source keys.
+	SrcPathPrefix string
+	// DestPathPrefix is the path prefix of the destination storage, prepended to the destination keys.
+	DestPathPrefix string
+	// Prefix selects the source objects whose key under the source path prefix starts with it.
+	Prefix string
+	// KeyTemplate, if set, is a text/template rendering the destination key under the destination path prefix.
+	// By default, the key under the source path prefix is kept.
+	KeyTemplate string
+	// WorkDir is the root directory of the destination storage. Objects that can't be copied server-side are
+	// downloaded under it and uploaded from there.
+	WorkDir string
+	// Concurrency is the number of objects copied in parallel. Default is DefaultConcurrency.
+	Concurrency int
+	// CheckpointFile, if set, records the progress so that an interrupted migration resumes where it stopped.
+	CheckpointFile string
+}
+
+// Object describes the outcome of migrating a single object.
+type Object struct {
+	SrcKey   string `json:"src_key"`
+	DestKey  string `json:"dest_key,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	Size     int64  `json:"size"`
+	Method   string `json:"method,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report is the result of migrating objects between two storages.
+type Report struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Listed      int      `json:"listed"`
+	Resumed     bool     `json:"resumed"`
+	Copied      []Object `json:"copied,omitempty"`
+	Skipped     []Object `json:"skipped,omitempty"`
+	Failed      []Object `json:"failed,omitempty"`
+}
+
+// Checkpoint is the progress of a migration. Every source key up to StartAfter was processed; failed keys are
+// retried when the migration is resumed.
+type Checkpoint struct {
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	StartAfter  string    `json:"start_after"`
+	Failed      []string  `json:"failed,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// task is a source object to migrate; index is its position in the ordered list of selected objects.
+type task struct {
+	index   int
+	obj     core.ObjectInfo
+	destKey string
+}
+
+// Run copies the selected objects of the source storage to the destination storage. Objects already in the
+// destination with the same checksum are skipped. Failures of individual objects are reported in the result; an error
+// is returned only if the migration can't start or the checkpoint can't be written.
+func Run(ctx context.Context, src core.Storage, dest core.Storage, opts Options) (*Report, error) {
+	if !src.Supports(core.CapabilityList) || !src.Supports(core.CapabilityGet) {
+		return nil, fmt.Errorf("%w: %s cannot list or download objects", core.ErrNotSupported, src.Type())
+	}
+
+	if !dest.Supports(core.CapabilityStat) {
+		return nil, fmt.Errorf("%w: %s cannot verify copied objects", core.ErrNotSupported, dest.Type())
+	}
+
+	if opts.WorkDir == "" {
+		return nil, fmt.Errorf("missing work directory")
+	}
+
+	tmpl, err := parseTemplate(opts.KeyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	report := &Report{Source: storageId(src), Destination: storageId(dest)}
+	checkpoint, err := loadCheckpoint(opts.CheckpointFile, report.Source, report.Destination)
+	if err != nil {
+		return nil, err
+	}
+	report.Resumed = checkpoint.StartAfter != "" || len(checkpoint.Failed) > 0
+
+	objects, err := core.ListAll(ctx, src, core.ComputeListPrefix(opts.SrcPathPrefix, opts.Prefix))
+	if err != nil {
+		return nil, err
+	}
+	report.Listed = len(objects)
+
+	retry := make(map[string]bool, len(checkpoint.Failed))
+	for _, key := range checkpoint.Failed {
+		retry[key] = true
+	}
+
+	var tasks []task
+	for _, obj := range objects {
+		if obj.Key <= checkpoint.StartAfter && !retry[obj.Key] {
+			continue
+		}
+
+		t := task{index: len(tasks), obj: obj}
+		t.destKey, err = destinationKey(obj.Key, tmpl, opts)
+		if err != nil {
+			report.Failed = append(report.Failed, Object{SrcKey: obj.Key, Size: obj.Size, Error: err.Error()})
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+
+	// failures of the checkpoint are retried, so only the keys that fail again are kept
+	checkpoint.Failed = nil
+	for _, f := range report.Failed {
+		checkpoint.Failed = append(checkpoint.Failed, f.SrcKey)
+	}
+
+	p := &progress{
+		checkpoint: checkpoint,
+		file:       opts.CheckpointFile,
+		done:       make([]bool, len(tasks)),
+		tasks:      tasks,
+	}
+
+	work := make(chan task)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range work {
+				o, skipped, err := migrateObject(ctx, src, dest, t, opts)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					o.Error = err.Error()
+					report.Failed = append(report.Failed, o)
+				case skipped:
+					report.Skipped = append(report.Skipped, o)
+				default:
+					report.Copied = append(report.Copied, o)
+				}
+				mu.Unlock()
+
+				p.complete(t, err != nil)
+			}
+		}()
+	}
+
+	for _, t := range tasks {
+		select {
+		case work <- t:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	for _, objs := range [][]Object{report.Copied, report.Skipped, report.Failed} {
+		sort.Slice(objs, func(i, j int) bool {
+			return objs[i].SrcKey < objs[j].SrcKey
+		})
+	}
+
+	if err := p.save(); err != nil {
+		return report, err
+	}
+
+	logx.As().Info().
+		Str("source", report.Source).
+		Str("destination", report.Destination).
+		Int("listed", report.Listed).
+		Int("copied", len(report.Copied)).
+		Int("skipped", len(report.Skipped)).
+		Int("failed", len(report.Failed)).
+		Bool("resumed", report.Resumed).
+		Msg("Migration completed")
+
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+
+	return report, nil
+}
+
+// parseTemplate parses the key template. It returns nil if the template is empty.
+func parseTemplate(keyTemplate string) (*template.Template, error) {
+	if keyTemplate == "" {
+		return nil, nil
+	}
+
+	tmpl, err := tmplx.Parse("key", keyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key template '%s': %w", keyTemplate, err)
+	}
+
+	return tmpl, nil
+}
+
+// destinationKey computes the destination key of a source key, rendering the key template if set.
+func destinationKey(srcKey string, tmpl *template.Template, opts Options) (string, error) {
+	rel, err := core.RelativeKey(srcKey, opts.SrcPathPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	if tmpl != nil {
+		vars := tmplx.FileVars(rel)
+		vars[TemplateVarKey] = rel
+		vars[TemplateVarDir] = path.Dir(rel)
+		vars[TemplateVarFileName] = path.Base(rel)
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return "", fmt.Errorf("failed to render key template for %s: %w", srcKey, err)
+		}
+
+		rel = strings.TrimPrefix(path.Clean("/"+buf.String()), "/")
+		if rel == "" {
+			return "", fmt.Errorf("key template rendered an empty key for %s", srcKey)
+		}
+	}
+
+	return strings.TrimPrefix(path.Join(opts.DestPathPrefix, rel), "/"), nil
+}
+
+// migrateObject copies a single object, using a server-side copy if the destination supports it for the source and a
+// download and upload otherwise. It returns true if the object was skipped because the destination already has it.
+func migrateObject(ctx context.Context, src core.Storage, dest core.Storage, t task, opts Options) (Object, bool, error) {
+	o := Object{SrcKey: t.obj.Key, DestKey: t.destKey, Checksum: t.obj.Checksum, Size: t.obj.Size}
+
+	existing, err := dest.Stat(ctx, t.destKey)
+	if err == nil && sameContent(t.obj, *existing) {
+		return o, true, nil
+	}
+	if err != nil && !errors.Is(err, core.ErrObjectNotFound) {
+		return o, false, err
+	}
+
+	if dest.Supports(core.CapabilityCopy) {
+		obj, err := dest.Copy(ctx, src, t.obj.Key, t.destKey)
+		if err == nil {
+			o.Method = MethodCopy
+			o.Checksum = obj.Checksum
+			return o, false, nil
+		}
+
+		if !errors.Is(err, core.ErrNotSupported) {
+			return o, false, err
+		}
+	}
+
+	o.Method = MethodDownload
+	checksum, err := downloadAndUpload(ctx, src, dest, t, opts)
+	if err != nil {
+		return o, false, err
+	}
+	o.Checksum = checksum
+
+	return o, false, nil
+}
+
+// downloadAndUpload downloads the object into the work directory and uploads it to the destination storage with the
+// metadata of the source object. The downloaded file is placed so that the destination storage stores it with the
+// destination key. The upload is verified against the MD5 checksum of the source, or its size if the checksum is not
+// the MD5 of the content (multipart ETag).
+func downloadAndUpload(ctx context.Context, src core.Storage, dest core.Storage, t task, opts Options) (string, error) {
+	local, err := core.ComputeSourcePath(opts.WorkDir, t.destKey, opts.DestPathPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	if key := dest.Key(local); key != t.destKey {
+		return "", fmt.Errorf("destination would store %s as %s instead of %s", local, key, t.destKey)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	defer func() {
+		if _, exists := fsx.PathExists(local); exists {
+			fsx.RemoveFile(local)
+		}
+	}()
+
+	downloaded, err := src.Get(ctx, t.obj.Key, local)
+	if err != nil {
+		return "", err
+	}
+
+	traceId := fmt.Sprintf("migrate-%s", uuid.NewString())
+	stored := make(chan core.StorageResult, 1)
+	item := core.ScannerResult{Path: local, TraceId: traceId}
+	if downloaded != nil {
+		item.Metadata = &downloaded.Metadata
+	}
+	dest.Put(ctx, item, []string{local}, stored)
+
+	var result core.StorageResult
+	select {
+	case result = <-stored:
+	default:
+		return "", errors.New("storage did not return a result")
+	}
+
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	if len(result.UploadResults) != 1 {
+		return "", fmt.Errorf("expected 1 upload result, got %d", len(result.UploadResults))
+	}
+
+	uploaded := result.UploadResults[0]
+	if verifiable(t.obj) && uploaded.ChecksumType == "md5" {
+		if !strings.EqualFold(uploaded.Checksum, t.obj.Checksum) {
+			return "", fmt.Errorf("checksum mismatch after upload: expected %s, got %s", t.obj.Checksum, uploaded.Checksum)
+		}
+	} else if uploaded.Size != t.obj.Size {
+		return "", fmt.Errorf("size mismatch after upload: expected %d bytes, got %d bytes", t.obj.Size, uploaded.Size)
+	}
+
+	return uploaded.Checksum, nil
+}
+
+// sameContent returns true if the destination object has the content of the source object.
+// Multipart checksums can't be compared across storages, so the sizes are compared instead.
+func sameContent(src core.ObjectInfo, dest core.ObjectInfo) bool {
+	if verifiable(src) && verifiable(dest) {
+		return strings.EqualFold(src.Checksum, dest.Checksum)
+	}
+
+	return src.Size == dest.Size
+}
+
+// verifiable returns true if the checksum of the object is the MD5 of its content.
+func verifiable(obj core.ObjectInfo) bool {
+	return obj.ChecksumType == "md5" && obj.Checksum != "" && !strings.Contains(obj.Checksum, "-")
+}
+
+func storageId(s core.Storage) string {
+	return fmt.Sprintf("%s:%s", s.Type(), s.Info())
+}
+
+func appendUnique(keys []string, key string) []string {
+	for _, k := range keys {
+		if k == key {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
+
+// progress tracks the completed tasks to advance the checkpoint. The checkpoint only advances over a contiguous run
+// of completed tasks, so that no object before StartAfter is left behind when the migration is interrupted.
+type progress struct {
+	mu         sync.Mutex
+	checkpoint Checkpoint
+	file       string
+	tasks      []task
+	done       []bool
+	next       int // index of the first task not completed
+	pending    int // number of completed tasks since the last save
+}
+
+// complete marks the task as completed and saves the checkpoint periodically.
+func (p *progress) complete(t task, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done[t.index] = true
+	if failed {
+		p.checkpoint.Failed = appendUnique(p.checkpoint.Failed, t.obj.Key)
+	}
+
+	for p.next < len(p.done) && p.done[p.next] {
+		if key := p.tasks[p.next].obj.Key; key > p.checkpoint.StartAfter {
+			p.checkpoint.StartAfter = key
+		}
+		p.next++
+	}
+
+	p.pending++
+	if p.pending >= checkpointInterval {
+		if err := p.saveLocked(); err != nil {
+			logx.As().Warn().Err(err).Str("file", p.file).Msg("Failed to save migration checkpoint")
+		}
+	}
+}
+
+// save writes the checkpoint file.
+func (p *progress) save() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.saveLocked()
+}
+
+func (p *progress) saveLocked() error {
+	p.pending = 0
+	if p.file == "" {
+		return nil
+	}
+
+	p.checkpoint.UpdatedAt = time.Now().UTC()
+	sort.Strings(p.checkpoint.Failed)
+	data, err := json.MarshalIndent(p.checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	if err := fsx.WriteFileAtomic(p.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// loadCheckpoint reads the checkpoint file if it exists. A checkpoint of a migration between other storages is
+// rejected to avoid skipping objects that were never copied.
+func loadCheckpoint(file string, source string, destination string) (Checkpoint, error) {
+	checkpoint := Checkpoint{Source: source, Destination: destination}
+	if file == "" {
+		return checkpoint, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoint, nil
+		}
+		return checkpoint, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var saved Checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return checkpoint, fmt.Errorf("invalid checkpoint %s: %w", file, err)
+	}
+
+	if saved.Source != source || saved.Destination != destination {
+		return checkpoint, fmt.Errorf("checkpoint %s is for a migration from %s to %s, not from %s to %s",
+			file, saved.Source, saved.Destination, source, destination)
+	}
+
+	logx.As().Info().
+		Str("file", file).
+		Str("start_after", saved.StartAfter).
+		Int("failed", len(saved.Failed)).
+		Msg("Resuming migration from checkpoint")
+
+	return saved, nil
+}
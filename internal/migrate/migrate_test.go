@@ -0,0 +1,270 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// noCopyStorage hides the server-side copy of a storage to force downloads and uploads.
+type noCopyStorage struct {
+	core.Storage
+}
+
+func (n *noCopyStorage) Supports(c core.Capability) bool {
+	return c != core.CapabilityCopy && n.Storage.Supports(c)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func srcKeys(objects []Object) []string {
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.SrcKey)
+	}
+	return keys
+}
+
+func newDir(t *testing.T, id string, dir string, rootDir string) core.Storage {
+	s, err := storage.NewLocalDir(id, config.LocalDirConfig{Path: dir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+	return s
+}
+
+func TestRun(t *testing.T) {
+	srcDir := t.TempDir()
+	writeFile(t, filepath.Join(srcDir, "rec", "a.rcd"), "data-a")
+	writeFile(t, filepath.Join(srcDir, "rec", "b.rcd"), "data-b")
+	writeFile(t, filepath.Join(srcDir, "evt", "e.evts"), "data-e")
+
+	oldTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(srcDir, "rec", "a.rcd"), oldTime, oldTime))
+
+	t.Run("server-side copy", func(t *testing.T) {
+		destDir := t.TempDir()
+		workDir := t.TempDir()
+		writeFile(t, filepath.Join(destDir, "rec", "b.rcd"), "data-b") // already migrated
+
+		report, err := Run(context.Background(), newDir(t, "src", srcDir, "/unused"), newDir(t, "dest", destDir, workDir),
+			Options{WorkDir: workDir, Prefix: "rec/"})
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Listed)
+		require.Equal(t, []string{"rec/a.rcd"}, srcKeys(report.Copied))
+		require.Equal(t, MethodCopy, report.Copied[0].Method)
+		require.Equal(t, []string{"rec/b.rcd"}, srcKeys(report.Skipped))
+		require.Empty(t, report.Failed)
+
+		require.Equal(t, "data-a", readFile(t, filepath.Join(destDir, "rec", "a.rcd")))
+		info, err := os.Stat(filepath.Join(destDir, "rec", "a.rcd"))
+		require.NoError(t, err)
+		require.True(t, oldTime.Equal(info.ModTime()))
+	})
+
+	t.Run("download with key template", func(t *testing.T) {
+		destDir := t.TempDir()
+		workDir := t.TempDir()
+		dest := &noCopyStorage{Storage: newDir(t, "dest", destDir, workDir)}
+
+		report, err := Run(context.Background(), newDir(t, "src", srcDir, "/unused"), dest, Options{
+			WorkDir:     workDir,
+			KeyTemplate: "archive/{{.dir}}/{{.fileName}}",
+			Concurrency: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"evt/e.evts", "rec/a.rcd", "rec/b.rcd"}, srcKeys(report.Copied))
+		for _, o := range report.Copied {
+			require.Equal(t, MethodDownload, o.Method)
+			require.Equal(t, "archive/"+o.SrcKey, o.DestKey)
+		}
+
+		require.Equal(t, "data-e", readFile(t, filepath.Join(destDir, "archive", "evt", "e.evts")))
+
+		// downloads are removed from the work directory once uploaded
+		objects, err := core.ListAll(context.Background(), newDir(t, "work", workDir, "/unused"), "")
+		require.NoError(t, err)
+		require.Empty(t, objects)
+	})
+
+	t.Run("invalid key template", func(t *testing.T) {
+		workDir := t.TempDir()
+		_, err := Run(context.Background(), newDir(t, "src", srcDir, "/unused"), newDir(t, "dest", t.TempDir(), workDir),
+			Options{WorkDir: workDir, KeyTemplate: "{{.key"})
+		require.ErrorContains(t, err, "failed to parse key template")
+
+		report, err := Run(context.Background(), newDir(t, "src", srcDir, "/unused"), newDir(t, "dest", t.TempDir(), workDir),
+			Options{WorkDir: workDir, KeyTemplate: "{{.unknown}}"})
+		require.NoError(t, err)
+		require.Len(t, report.Failed, 3)
+	})
+}
+
+func TestRun_Checkpoint(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	workDir := t.TempDir()
+	for _, name := range []string{"a.rcd", "b.rcd", "c.rcd", "d.rcd"} {
+		writeFile(t, filepath.Join(srcDir, name), name)
+	}
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	src := newDir(t, "src", srcDir, "/unused")
+	dest := newDir(t, "dest", destDir, workDir)
+
+	// a previous run processed up to b.rcd, and a.rcd failed
+	saved := Checkpoint{Source: "LocalDir:src", Destination: "LocalDir:dest", StartAfter: "b.rcd", Failed: []string{"a.rcd"}}
+	data, err := json.Marshal(saved)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(checkpointFile, data, 0644))
+
+	report, err := Run(context.Background(), src, dest, Options{WorkDir: workDir, CheckpointFile: checkpointFile})
+	require.NoError(t, err)
+	require.True(t, report.Resumed)
+	require.Equal(t, []string{"a.rcd", "c.rcd", "d.rcd"}, srcKeys(report.Copied))
+
+	_, err = os.Stat(filepath.Join(destDir, "b.rcd"))
+	require.True(t, os.IsNotExist(err))
+
+	data, err = os.ReadFile(checkpointFile)
+	require.NoError(t, err)
+	var checkpoint Checkpoint
+	require.NoError(t, json.Unmarshal(data, &checkpoint))
+	require.Equal(t, "d.rcd", checkpoint.StartAfter)
+	require.Empty(t, checkpoint.Failed)
+
+	// a checkpoint of another migration is rejected
+	_, err = Run(context.Background(), src, newDir(t, "other", t.TempDir(), workDir),
+		Options{WorkDir: workDir, CheckpointFile: checkpointFile})
+	require.ErrorContains(t, err, "is for a migration from")
+}
+
+func TestDestinationKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		srcKey   string
+		template string
+		opts     Options
+		expected string
+		wantErr  bool
+	}{
+		{name: "same prefix", srcKey: "streams/rec/a.rcd", opts: Options{SrcPathPrefix: "streams", DestPathPrefix: "streams"}, expected: "streams/rec/a.rcd"},
+		{name: "new prefix", srcKey: "streams/rec/a.rcd", opts: Options{SrcPathPrefix: "streams", DestPathPrefix: "cheetah/streams"}, expected: "cheetah/streams/rec/a.rcd"},
+		{name: "no prefix", srcKey: "rec/a.rcd", expected: "rec/a.rcd"},
+		{name: "template", srcKey: "rec/a.rcd", template: "{{.fileName}}", opts: Options{DestPathPrefix: "flat"}, expected: "flat/a.rcd"},
+		{name: "outside prefix", srcKey: "other/a.rcd", opts: Options{SrcPathPrefix: "streams"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.KeyTemplate = tt.template
+			tmpl, err := parseTemplate(opts.KeyTemplate)
+			require.NoError(t, err)
+
+			key, err := destinationKey(tt.srcKey, tmpl, opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, key)
+		})
+	}
+}
+
+// multipartStorage reports the objects of a storage with a multipart checksum and metadata, like a bucket does for
+// objects uploaded in parts.
+type multipartStorage struct {
+	core.Storage
+}
+
+func (m *multipartStorage) List(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	result, err := m.Storage.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Objects {
+		result.Objects[i].Checksum += "-2"
+	}
+	return result, nil
+}
+
+func (m *multipartStorage) Get(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	obj, err := m.Storage.Get(ctx, key, dest)
+	if err != nil {
+		return nil, err
+	}
+	obj.Checksum += "-2"
+	obj.Metadata = core.ObjectMetadata{ContentType: "application/gzip", UserMetadata: map[string]string{"Node": "0.0.3"}}
+	return obj, nil
+}
+
+// putStorage records the metadata of the uploaded files and changes their reported size.
+type putStorage struct {
+	noCopyStorage
+	metadata  *core.ObjectMetadata
+	extraSize int64
+}
+
+func (p *putStorage) Put(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+	p.metadata = item.Metadata
+	results := make(chan core.StorageResult, 1)
+	p.Storage.Put(ctx, item, candidates, results)
+	result := <-results
+	for _, u := range result.UploadResults {
+		u.Size += p.extraSize
+	}
+	stored <- result
+}
+
+func TestRun_Metadata(t *testing.T) {
+	srcDir := t.TempDir()
+	writeFile(t, filepath.Join(srcDir, "rec", "a.rcd"), "data-a")
+	src := &multipartStorage{Storage: newDir(t, "src", srcDir, "/unused")}
+
+	workDir := t.TempDir()
+	dest := &putStorage{noCopyStorage: noCopyStorage{Storage: newDir(t, "dest", t.TempDir(), workDir)}}
+	report, err := Run(context.Background(), src, dest, Options{WorkDir: workDir})
+	require.NoError(t, err)
+	require.Equal(t, []string{"rec/a.rcd"}, srcKeys(report.Copied))
+	require.NotNil(t, dest.metadata)
+	require.Equal(t, "application/gzip", dest.metadata.ContentType)
+	require.Equal(t, map[string]string{"Node": "0.0.3"}, dest.metadata.UserMetadata)
+
+	// multipart checksums can't be compared, so the size is verified instead
+	workDir = t.TempDir()
+	dest = &putStorage{noCopyStorage: noCopyStorage{Storage: newDir(t, "dest", t.TempDir(), workDir)}, extraSize: 1}
+	report, err = Run(context.Background(), src, dest, Options{WorkDir: workDir})
+	require.NoError(t, err)
+	require.Len(t, report.Failed, 1)
+	require.Contains(t, report.Failed[0].Error, "size mismatch after upload")
+}
+
+func TestSameContent(t *testing.T) {
+	md5 := core.ObjectInfo{Size: 4, Checksum: "098f6bcd4621d373cade4e832627b4f6", ChecksumType: "md5"}
+	multipart := core.ObjectInfo{Size: 4, Checksum: "9f5e0b2a1c3d4e5f6a7b8c9d0e1f2a3b-2", ChecksumType: "md5"}
+	crc := core.ObjectInfo{Size: 4, Checksum: "AAAAAA==", ChecksumType: "crc32c"}
+
+	require.True(t, sameContent(md5, md5))
+	require.False(t, sameContent(md5, core.ObjectInfo{Size: 4, Checksum: "ad0234829205b9033196ba818f7a872b", ChecksumType: "md5"}))
+
+	// checksums that cannot be compared fall back to the sizes
+	require.True(t, sameContent(md5, multipart))
+	require.True(t, sameContent(crc, md5))
+	require.False(t, sameContent(md5, core.ObjectInfo{Size: 5, Checksum: "AAAAAA==", ChecksumType: "crc32c"}))
+}
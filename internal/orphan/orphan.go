@@ -0,0 +1,488 @@
+package orphan
+
+import (
+	"context"
+	"fmt"
+	"github.com/gobwas/glob"
+	"github.com/google/uuid"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Defaults of the orphan sweep.
+const (
+	DefaultInterval = 5 * time.Minute
+	DefaultMinAge   = time.Hour
+)
+
+// Prefix is the storage prefix that orphaned candidate files are uploaded under.
+const Prefix = "orphans"
+
+// Actions on orphaned candidate files.
+const (
+	ActionReport = "report" // only log and count the orphaned files
+	ActionUpload = "upload" // upload the orphaned files under the orphans prefix and remove them
+	ActionMove   = "move"   // move the orphaned files to the holding directory
+)
+
+// Kinds of orphaned files, used as the value of the "kind" label of metrics.OrphanFiles.
+const (
+	KindCandidate = "candidate" // candidate file without a marker file
+	KindMarker    = "marker"    // marker file that has not been uploaded
+)
+
+// File is an orphaned file found by a sweep.
+type File struct {
+	Path    string
+	Kind    string
+	ModTime time.Time
+}
+
+// Options holds the settings of a Sweeper.
+//
+// Fields:
+//   - RootDir: The scanner directory to sweep.
+//   - Markers: The marker patterns of the scanner with the file matchers used to find the candidate files of their
+//     marker files.
+//   - Patterns: Glob patterns, relative to the directory of the markers, of the candidate files.
+//   - Interval: The delay between two sweeps.
+//   - MinAge: How long a file must be unmodified before it is orphaned.
+//   - Action: What is done with orphaned candidate files: report, upload or move.
+//   - HoldingDir: The directory orphaned candidate files are moved to.
+//   - Storages: The storages orphaned candidate files are uploaded to, storing them under the orphans prefix.
+//   - BatchSize: The maximum number of directory entries read at once.
+//   - InFlight: The marker files being processed; files within their directories are left to the processors.
+type Options struct {
+	RootDir    string
+	Markers    matcher.MarkerPatterns
+	Patterns   []string
+	Interval   time.Duration
+	MinAge     time.Duration
+	Action     string
+	HoldingDir string
+	Storages   []core.Storage
+	Recorders  []core.Recorder
+	BatchSize  int
+	InFlight   InFlight
+}
+
+// InFlight reports whether a file belongs to the directory of a marker file that a processor is working on.
+type InFlight interface {
+	Covers(path string) bool
+}
+
+// Sweeper periodically finds orphaned files in the scanner directory of a pipeline: candidate files that are not
+// matched by any marker file, and marker files that have not been uploaded, both unmodified for at least the minimum
+// age. The scanner only reacts to marker files, so these files would otherwise stay on disk unnoticed.
+//
+// Orphaned files are logged and counted in metrics.OrphanFiles. Orphaned candidate files are then uploaded under the
+// orphans prefix of the storages or moved to the holding directory if configured; marker files are left to the
+// processors, which retry them on every scan.
+//
+// Notes:
+//   - A candidate file is only orphaned if no marker file currently on disk matches it, so the minimum age must be
+//     larger than the time a producer takes to write the marker file after its data files.
+//   - Candidate files of markers whose files cannot be matched are not reported.
+//   - Files in the directories of marker files being processed are skipped, and every file is checked again right
+//     before it is handled, since processors upload and remove files while the directory is swept.
+type Sweeper struct {
+	pipeline string
+	opts     Options
+	globs    []glob.Glob
+}
+
+// Run sweeps the scanner directory every interval until the context is canceled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				metrics.ErrorsTotal.WithLabelValues(s.pipeline, metrics.ErrorClassOrphan).Inc()
+				logx.As().Error().Err(err).Str("pipeline", s.pipeline).Msg("Orphan sweep failed")
+			}
+		}
+	}
+}
+
+// Sweep finds the orphaned files of the scanner directory, reports them and applies the action to the orphaned
+// candidate files. It returns the orphaned files sorted by path.
+func (s *Sweeper) Sweep(ctx context.Context) ([]File, error) {
+	markers, candidates, err := s.walk()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claimed, unsure := s.claim(markers)
+
+	var orphans []File
+	counts := map[string]int{KindCandidate: 0, KindMarker: 0}
+	for path, m := range markers {
+		if s.inFlight(path) {
+			continue
+		}
+		if now.Sub(m.modTime) >= s.opts.MinAge {
+			orphans = append(orphans, File{Path: path, Kind: KindMarker, ModTime: m.modTime})
+			counts[KindMarker]++
+		}
+	}
+	for path, modTime := range candidates {
+		if _, ok := claimed[path]; ok {
+			continue
+		}
+		if isUnsure(unsure, path) || s.inFlight(path) {
+			continue
+		}
+		if now.Sub(modTime) >= s.opts.MinAge {
+			orphans = append(orphans, File{Path: path, Kind: KindCandidate, ModTime: modTime})
+			counts[KindCandidate]++
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].Path < orphans[j].Path
+	})
+
+	for kind, count := range counts {
+		metrics.OrphanFiles.WithLabelValues(s.pipeline, kind).Set(float64(count))
+	}
+
+	for _, f := range orphans {
+		logx.As().Warn().
+			Str("pipeline", s.pipeline).
+			Str("path", f.Path).
+			Str("kind", f.Kind).
+			Time("mod_time", f.ModTime).
+			Str("action", s.opts.Action).
+			Msg("Found orphaned file")
+
+		if f.Kind != KindCandidate || s.opts.Action == ActionReport {
+			continue
+		}
+
+		if !s.stillOrphaned(f) {
+			logx.As().Debug().
+				Str("pipeline", s.pipeline).
+				Str("path", f.Path).
+				Msg("Orphaned file changed during the sweep, skipping it")
+			continue
+		}
+
+		if err := s.handle(ctx, f); err != nil {
+			metrics.ErrorsTotal.WithLabelValues(s.pipeline, metrics.ErrorClassOrphan).Inc()
+			logx.As().Error().
+				Err(err).
+				Str("pipeline", s.pipeline).
+				Str("path", f.Path).
+				Str("action", s.opts.Action).
+				Msg("Failed to handle orphaned file")
+			continue
+		}
+		metrics.OrphansHandled.WithLabelValues(s.pipeline, s.opts.Action).Inc()
+	}
+
+	logx.As().Info().
+		Str("pipeline", s.pipeline).
+		Int("markers", len(markers)).
+		Int("candidates", len(candidates)).
+		Int("orphaned_markers", counts[KindMarker]).
+		Int("orphaned_candidates", counts[KindCandidate]).
+		Msg("Orphan sweep completed")
+
+	return orphans, nil
+}
+
+// markerFile is a marker file found by a sweep.
+type markerFile struct {
+	modTime time.Time
+	pattern *matcher.MarkerPattern
+}
+
+// walk returns the marker files and the candidate files of the scanner directory with their modification times.
+func (s *Sweeper) walk() (map[string]markerFile, map[string]time.Time, error) {
+	markers := make(map[string]markerFile)
+	candidates := make(map[string]time.Time)
+
+	walker := fsx.NewWalker(s.opts.BatchSize)
+	defer walker.End()
+	err := walker.Start(s.opts.RootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.opts.RootDir, path)
+		if err != nil {
+			return nil
+		}
+
+		if m := s.opts.Markers.Match(rel); m != nil {
+			markers[filepath.Clean(path)] = markerFile{modTime: info.ModTime(), pattern: m}
+			return nil
+		}
+
+		rel = "/" + filepath.ToSlash(rel)
+		for _, g := range s.globs {
+			if g.Match(rel) {
+				candidates[filepath.Clean(path)] = info.ModTime()
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sweep directory '%s': %w", s.opts.RootDir, err)
+	}
+
+	return markers, candidates, nil
+}
+
+// claim returns the candidate files matched by the marker files, and the directories of the marker files whose
+// candidate files could not be matched.
+func (s *Sweeper) claim(markers map[string]markerFile) (map[string]struct{}, map[string]struct{}) {
+	claimed := make(map[string]struct{})
+	unsure := make(map[string]struct{})
+	for marker, m := range markers {
+		// incomplete markers still claim the files they match
+		files, err := matcher.MatchCandidates(marker, m.pattern.Matchers)
+		if files == nil && err != nil {
+			logx.As().Warn().
+				Err(err).
+				Str("pipeline", s.pipeline).
+				Str("marker", marker).
+				Msg("Failed to match candidate files of marker, skipping its directory")
+			unsure[filepath.Dir(marker)] = struct{}{}
+			continue
+		}
+
+		for _, f := range files {
+			claimed[filepath.Clean(f)] = struct{}{}
+		}
+	}
+
+	return claimed, unsure
+}
+
+// inFlight returns true if the file is in the directory of a marker file being processed.
+func (s *Sweeper) inFlight(path string) bool {
+	return s.opts.InFlight != nil && s.opts.InFlight.Covers(path)
+}
+
+// stillOrphaned returns true if the orphaned file still exists unmodified and no processor has picked up a marker file
+// of its directory since the walk.
+func (s *Sweeper) stillOrphaned(f File) bool {
+	if s.inFlight(f.Path) {
+		return false
+	}
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().Equal(f.ModTime)
+}
+
+// isUnsure returns true if the file is in one of the directories whose candidate files could not be matched.
+func isUnsure(unsure map[string]struct{}, path string) bool {
+	for dir := range unsure {
+		if fsx.IsWithin(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// handle uploads or moves an orphaned candidate file.
+func (s *Sweeper) handle(ctx context.Context, f File) error {
+	switch s.opts.Action {
+	case ActionUpload:
+		return s.upload(ctx, f.Path)
+	case ActionMove:
+		return s.move(f.Path)
+	default:
+		return nil
+	}
+}
+
+// upload uploads the file to all storages and removes it once every upload succeeded and has been recorded, e.g. in
+// the upload journal, like the files of a marker.
+func (s *Sweeper) upload(ctx context.Context, path string) error {
+	if len(s.opts.Storages) == 0 {
+		return fmt.Errorf("no storage to upload orphaned files to")
+	}
+
+	stored := make(chan core.StorageResult, len(s.opts.Storages))
+	item := core.ScannerResult{Path: path, TraceId: fmt.Sprintf("orphan-%s", uuid.NewString())}
+	for _, st := range s.opts.Storages {
+		st.Put(ctx, item, []string{path}, stored)
+	}
+	close(stored)
+
+	pr := core.ProcessorResult{
+		Path:     path,
+		TraceId:  item.TraceId,
+		Pipeline: s.pipeline,
+		Result:   make(map[string]*core.StorageResult),
+	}
+	for result := range stored {
+		if result.Error != nil && pr.Error == nil {
+			pr.Error = fmt.Errorf("failed to upload to %s: %w", result.Type, result.Error)
+		}
+		pr.Result[result.Type] = &result
+	}
+	if pr.Error == nil && ctx.Err() != nil {
+		pr.Error = ctx.Err()
+	}
+
+	if err := s.record(pr); err != nil && pr.Error == nil {
+		return err
+	}
+	if pr.Error != nil {
+		return pr.Error
+	}
+
+	return os.Remove(path)
+}
+
+// record passes the result of an orphaned file upload to all recorders and returns the first error encountered.
+func (s *Sweeper) record(pr core.ProcessorResult) error {
+	var firstErr error
+	for _, r := range s.opts.Recorders {
+		if err := r.Record(pr); err != nil {
+			logx.As().Error().
+				Err(err).
+				Str("pipeline", s.pipeline).
+				Str("file", pr.Path).
+				Str("trace_id", pr.TraceId).
+				Msg("Failed to record orphaned file upload")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to record orphaned file %s: %w", pr.Path, err)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// move moves the file to the holding directory, keeping its path relative to the scanner directory.
+func (s *Sweeper) move(path string) error {
+	rel, err := filepath.Rel(s.opts.RootDir, path)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(s.opts.HoldingDir, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create holding directory: %w", err)
+	}
+
+	if err := os.Rename(path, dest); err == nil {
+		return nil
+	}
+
+	// the holding directory may be on another file system
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return fsx.Move(path, dest, info.Mode().Perm())
+}
+
+// NewSweeper creates an orphan sweeper for the scanner directory of the pipeline.
+//
+// If no patterns are set, the candidate files are the ones matched by the globs derived from the file matchers of the
+// marker patterns (see matcher.CandidateGlobs).
+func NewSweeper(pipeline string, opts Options) (*Sweeper, error) {
+	if opts.RootDir == "" {
+		return nil, fmt.Errorf("orphan sweep requires a scanner directory")
+	}
+	if len(opts.Markers) == 0 {
+		return nil, fmt.Errorf("orphan sweep requires marker patterns")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.MinAge <= 0 {
+		opts.MinAge = DefaultMinAge
+	}
+
+	switch opts.Action {
+	case "":
+		opts.Action = ActionReport
+	case ActionReport:
+	case ActionUpload:
+		if len(opts.Storages) == 0 {
+			return nil, fmt.Errorf("orphan action %s requires an enabled storage", ActionUpload)
+		}
+	case ActionMove:
+		if opts.HoldingDir == "" {
+			return nil, fmt.Errorf("orphan action %s requires a holding directory", ActionMove)
+		}
+		if fsx.IsWithin(opts.RootDir, opts.HoldingDir) {
+			return nil, fmt.Errorf("holding directory %s must not be inside the scanner directory", opts.HoldingDir)
+		}
+	default:
+		return nil, fmt.Errorf("invalid orphan action '%s', expected %s, %s or %s",
+			opts.Action, ActionReport, ActionUpload, ActionMove)
+	}
+
+	patterns := opts.Patterns
+	if len(patterns) == 0 {
+		seen := make(map[string]struct{})
+		for _, m := range opts.Markers {
+			globs, err := matcher.CandidateGlobs(m.Matchers)
+			if err != nil {
+				return nil, err
+			}
+			for _, g := range globs {
+				if _, ok := seen[g]; !ok {
+					seen[g] = struct{}{}
+					patterns = append(patterns, g)
+				}
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no candidate file patterns; set the patterns of the orphan sweep")
+	}
+
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		// candidate files may be in any directory below the scanner directory
+		g, err := glob.Compile("**/"+filepath.ToSlash(pattern), '/')
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile glob pattern '%s': %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+
+	logx.As().Info().
+		Str("pipeline", pipeline).
+		Str("root_dir", opts.RootDir).
+		Strs("patterns", patterns).
+		Dur("interval", opts.Interval).
+		Dur("min_age", opts.MinAge).
+		Str("action", opts.Action).
+		Msg("Orphan sweep enabled")
+
+	return &Sweeper{pipeline: pipeline, opts: opts, globs: globs}, nil
+}
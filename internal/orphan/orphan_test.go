@@ -0,0 +1,274 @@
+package orphan
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var matchers = []config.FileMatcherConfig{
+	{MatcherType: "basic", Patterns: []string{".rcd.gz", ".rcd_sig"}},
+	{MatcherType: "sequential", Patterns: []string{"sidecar/{{.markerName}}_##.rcd.gz"}},
+}
+
+// markerPatterns returns the marker patterns of the extension with the matchers.
+func markerPatterns(t *testing.T, ext string, matchers []config.FileMatcherConfig) matcher.MarkerPatterns {
+	markers, err := matcher.NewMarkerPatterns(ext, nil, matchers)
+	require.NoError(t, err)
+	return markers
+}
+
+// writeFile writes a file under the directory and sets its modification time to the given age.
+func writeFile(t *testing.T, dir string, name string, age time.Duration) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	return path
+}
+
+// streamDir writes a record stream directory with a complete marker, an old orphaned data file and sidecar, a recent
+// data file without marker, an old marker without data and an unrelated file.
+func streamDir(t *testing.T) (string, []string) {
+	root := t.TempDir()
+	writeFile(t, root, "record0.0.3/2024-01-01T00_00_00.000000000Z.rcd_sig", 0)
+	writeFile(t, root, "record0.0.3/2024-01-01T00_00_00.000000000Z.rcd.gz", 2*time.Hour)
+	writeFile(t, root, "record0.0.3/sidecar/2024-01-01T00_00_00.000000000Z_01.rcd.gz", 2*time.Hour)
+	orphaned := writeFile(t, root, "record0.0.3/2024-01-01T00_00_02.000000000Z.rcd.gz", 2*time.Hour)
+	sidecar := writeFile(t, root, "record0.0.3/sidecar/2024-01-01T00_00_02.000000000Z_01.rcd.gz", 2*time.Hour)
+	writeFile(t, root, "record0.0.3/2024-01-01T00_00_04.000000000Z.rcd.gz", time.Minute)
+	stale := writeFile(t, root, "record0.0.4/2024-01-01T00_00_00.000000000Z.rcd_sig", 2*time.Hour)
+	writeFile(t, root, "record0.0.3/notes.txt", 2*time.Hour)
+	return root, []string{orphaned, sidecar, stale}
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	pipeline := "test-orphan-report"
+	root, expected := streamDir(t)
+
+	s, err := NewSweeper(pipeline, Options{RootDir: root, Markers: markerPatterns(t, ".rcd_sig", matchers)})
+	require.NoError(t, err)
+
+	orphans, err := s.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orphans, 3)
+	for i, f := range orphans {
+		require.Equal(t, expected[i], f.Path)
+	}
+	require.Equal(t, KindCandidate, orphans[0].Kind)
+	require.Equal(t, KindCandidate, orphans[1].Kind)
+	require.Equal(t, KindMarker, orphans[2].Kind)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.OrphanFiles.WithLabelValues(pipeline, KindCandidate)))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.OrphanFiles.WithLabelValues(pipeline, KindMarker)))
+
+	// reporting leaves the files in place
+	for _, path := range expected {
+		require.FileExists(t, path)
+	}
+}
+
+func TestSweeper_Move(t *testing.T) {
+	pipeline := "test-orphan-move"
+	root, expected := streamDir(t)
+	holding := t.TempDir()
+
+	s, err := NewSweeper(pipeline, Options{
+		RootDir:    root,
+		Markers:    markerPatterns(t, ".rcd_sig", matchers),
+		Action:     ActionMove,
+		HoldingDir: holding,
+	})
+	require.NoError(t, err)
+
+	_, err = s.Sweep(context.Background())
+	require.NoError(t, err)
+
+	require.NoFileExists(t, expected[0])
+	require.FileExists(t, filepath.Join(holding, "record0.0.3/2024-01-01T00_00_02.000000000Z.rcd.gz"))
+	require.FileExists(t, filepath.Join(holding, "record0.0.3/sidecar/2024-01-01T00_00_02.000000000Z_01.rcd.gz"))
+	require.FileExists(t, expected[2], "stale markers are not moved")
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.OrphansHandled.WithLabelValues(pipeline, ActionMove)))
+}
+
+// dirs is an in-flight set covering the files of directories.
+type dirs []string
+
+func (d dirs) Covers(path string) bool {
+	for _, dir := range d {
+		if filepath.Dir(path) == dir || filepath.Dir(filepath.Dir(path)) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSweeper_InFlight(t *testing.T) {
+	pipeline := "test-orphan-in-flight"
+	root, expected := streamDir(t)
+
+	s, err := NewSweeper(pipeline, Options{
+		RootDir:    root,
+		Markers:    markerPatterns(t, ".rcd_sig", matchers),
+		Action:     ActionMove,
+		HoldingDir: t.TempDir(),
+		InFlight:   dirs{filepath.Join(root, "record0.0.3")},
+	})
+	require.NoError(t, err)
+
+	orphans, err := s.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	require.Equal(t, expected[2], orphans[0].Path)
+	require.FileExists(t, expected[0], "files of markers in flight are left to the processors")
+	require.FileExists(t, expected[1])
+}
+
+func TestSweeper_StillOrphaned(t *testing.T) {
+	root := t.TempDir()
+	path := writeFile(t, root, "record0.0.3/2024-01-01T00_00_02.000000000Z.rcd.gz", 2*time.Hour)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	f := File{Path: path, Kind: KindCandidate, ModTime: info.ModTime()}
+
+	s, err := NewSweeper("test", Options{RootDir: root, Markers: markerPatterns(t, ".rcd_sig", matchers)})
+	require.NoError(t, err)
+	require.True(t, s.stillOrphaned(f))
+
+	// a file written again since the walk is not orphaned anymore
+	modTime := time.Now()
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	require.False(t, s.stillOrphaned(f))
+
+	// nor is a file removed by a processor
+	require.NoError(t, os.Remove(path))
+	require.False(t, s.stillOrphaned(f))
+
+	s.opts.InFlight = dirs{filepath.Dir(path)}
+	require.False(t, s.stillOrphaned(File{Path: path}))
+}
+
+// recorder collects the recorded results and fails with err if set.
+type recorder struct {
+	mu      sync.Mutex
+	results []core.ProcessorResult
+	err     error
+}
+
+func (r *recorder) Record(result core.ProcessorResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+	return r.err
+}
+
+func TestSweeper_Upload(t *testing.T) {
+	pipeline := "test-orphan-upload"
+	root, expected := streamDir(t)
+	remote := t.TempDir()
+	rec := &recorder{}
+
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: filepath.Join(remote, Prefix), Mode: 0755},
+		config.RetryConfig{Limit: 1}, root)
+	require.NoError(t, err)
+
+	s, err := NewSweeper(pipeline, Options{
+		RootDir:   root,
+		Markers:   markerPatterns(t, ".rcd_sig", matchers),
+		Patterns:  []string{"*.rcd.gz"},
+		Action:    ActionUpload,
+		Storages:  []core.Storage{dir},
+		Recorders: []core.Recorder{rec},
+	})
+	require.NoError(t, err)
+
+	orphans, err := s.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orphans, 3, "sidecars match *.rcd.gz in any directory")
+
+	require.NoFileExists(t, expected[0])
+	require.FileExists(t, filepath.Join(remote, Prefix, "record0.0.3/2024-01-01T00_00_02.000000000Z.rcd.gz"))
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.OrphansHandled.WithLabelValues(pipeline, ActionUpload)))
+
+	// each uploaded file is recorded under its own trace ID
+	require.Len(t, rec.results, 2)
+	traceIds := make(map[string]struct{})
+	for _, result := range rec.results {
+		require.NoError(t, result.Error)
+		require.Equal(t, pipeline, result.Pipeline)
+		require.True(t, strings.HasPrefix(result.TraceId, "orphan-"))
+		require.Len(t, result.Result[dir.Type()].UploadResults, 1)
+		traceIds[result.TraceId] = struct{}{}
+	}
+	require.Len(t, traceIds, 2)
+}
+
+func TestSweeper_UploadRecorderFailure(t *testing.T) {
+	root, expected := streamDir(t)
+
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: filepath.Join(t.TempDir(), Prefix), Mode: 0755},
+		config.RetryConfig{Limit: 1}, root)
+	require.NoError(t, err)
+
+	s, err := NewSweeper("test-orphan-record", Options{
+		RootDir:   root,
+		Markers:   markerPatterns(t, ".rcd_sig", matchers),
+		Patterns:  []string{"*.rcd.gz"},
+		Action:    ActionUpload,
+		Storages:  []core.Storage{dir},
+		Recorders: []core.Recorder{&recorder{err: errors.New("journal is closed")}},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Sweep(context.Background())
+	require.NoError(t, err)
+
+	// a file whose upload is not recorded is kept on disk
+	require.FileExists(t, expected[0])
+}
+
+func TestNewSweeper(t *testing.T) {
+	root := t.TempDir()
+
+	markers := markerPatterns(t, ".rcd_sig", matchers)
+
+	_, err := NewSweeper("test", Options{Markers: markers})
+	require.ErrorContains(t, err, "requires a scanner directory")
+
+	_, err = NewSweeper("test", Options{RootDir: root})
+	require.ErrorContains(t, err, "requires marker patterns")
+
+	_, err = NewSweeper("test", Options{RootDir: root, Markers: markers, Action: "delete"})
+	require.ErrorContains(t, err, "invalid orphan action 'delete'")
+
+	_, err = NewSweeper("test", Options{RootDir: root, Markers: markers, Action: ActionUpload})
+	require.ErrorContains(t, err, "requires an enabled storage")
+
+	_, err = NewSweeper("test", Options{RootDir: root, Markers: markers, Action: ActionMove})
+	require.ErrorContains(t, err, "requires a holding directory")
+
+	_, err = NewSweeper("test", Options{RootDir: root, Markers: markers, Action: ActionMove, HoldingDir: filepath.Join(root, "orphans")})
+	require.ErrorContains(t, err, "must not be inside the scanner directory")
+
+	_, err = NewSweeper("test", Options{RootDir: root, Markers: markerPatterns(t, ".rcd_sig", []config.FileMatcherConfig{{MatcherType: "manifest"}})})
+	require.ErrorContains(t, err, "no candidate file patterns")
+
+	s, err := NewSweeper("test", Options{RootDir: root, Markers: markers})
+	require.NoError(t, err)
+	require.Equal(t, ActionReport, s.opts.Action)
+	require.Equal(t, DefaultInterval, s.opts.Interval)
+	require.Equal(t, DefaultMinAge, s.opts.MinAge)
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcomes of a marker whose required candidate files do not exist.
+const (
+	deferMarker  = iota // the files may still be written; the marker is matched again by the next scan
+	reportMarker        // the deadline has passed; the marker is reported as incomplete
+	skipMarker          // the marker was already reported as incomplete and is only matched again
+)
+
+// deferral is the state of an incomplete marker.
+type deferral struct {
+	time      time.Time // the time of the marker, see scanner.MarkerTime
+	firstSeen time.Time
+	reported  bool
+}
+
+// Deferrals tracks the markers whose required candidate files do not exist yet. It is shared by the processors of a
+// pipeline, so that the deadline of a marker runs from the first time any processor found it incomplete rather than
+// from each scan that finds it again.
+//
+// A nil Deferrals is valid: without later scans, incomplete markers are reported at once.
+type Deferrals struct {
+	mu      sync.Mutex
+	markers map[string]*deferral
+}
+
+// incomplete records that the marker, of the given time, is incomplete and returns whether it is deferred, reported
+// or skipped.
+func (d *Deferrals) incomplete(marker string, t time.Time, deadline time.Duration, now time.Time) int {
+	if d == nil {
+		return reportMarker
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	df, ok := d.markers[marker]
+	if !ok {
+		df = &deferral{time: t, firstSeen: now}
+		d.markers[marker] = df
+	}
+
+	switch {
+	case df.reported:
+		return skipMarker
+	case now.Before(df.firstSeen.Add(deadline)):
+		return deferMarker
+	default:
+		df.reported = true
+		return reportMarker
+	}
+}
+
+// older returns an older marker of the same directory that is deferred and whose deadline has not passed, if any.
+// Ties are broken by path, like the order in which the sequencer publishes markers.
+func (d *Deferrals) older(marker string, t time.Time, deadline time.Duration, now time.Time) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dir := filepath.Dir(marker)
+	for path, df := range d.markers {
+		if path == marker || filepath.Dir(path) != dir || df.reported || !now.Before(df.firstSeen.Add(deadline)) {
+			continue
+		}
+
+		if df.time.Before(t) || (df.time.Equal(t) && path < marker) {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// forget drops the state of a marker once it is complete or no longer exists.
+func (d *Deferrals) forget(marker string) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.markers, marker)
+}
+
+// NewDeferrals creates the deferral state of a pipeline whose scanner runs repeatedly.
+func NewDeferrals() *Deferrals {
+	return &Deferrals{markers: make(map[string]*deferral)}
+}
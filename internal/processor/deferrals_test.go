@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeferrals(t *testing.T) {
+	d := NewDeferrals()
+	start := time.Now()
+
+	require.Equal(t, deferMarker, d.incomplete("a", time.Time{}, time.Minute, start))
+	require.Equal(t, deferMarker, d.incomplete("a", time.Time{}, time.Minute, start.Add(59*time.Second)))
+	require.Equal(t, reportMarker, d.incomplete("a", time.Time{}, time.Minute, start.Add(time.Minute)))
+	require.Equal(t, skipMarker, d.incomplete("a", time.Time{}, time.Minute, start.Add(2*time.Minute)))
+
+	// a forgotten marker starts over
+	d.forget("a")
+	require.Equal(t, deferMarker, d.incomplete("a", time.Time{}, time.Minute, start.Add(3*time.Minute)))
+
+	var none *Deferrals
+	require.Equal(t, reportMarker, none.incomplete("a", time.Time{}, time.Minute, start))
+	none.forget("a")
+}
+
+func TestDeferrals_Older(t *testing.T) {
+	d := NewDeferrals()
+	start := time.Now()
+	markerTime := time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC)
+
+	require.Equal(t, deferMarker, d.incomplete("dir/b", markerTime, time.Minute, start))
+
+	older, ok := d.older("dir/c", markerTime.Add(time.Second), time.Minute, start)
+	require.True(t, ok)
+	require.Equal(t, "dir/b", older)
+
+	// ties are broken by path
+	_, ok = d.older("dir/c", markerTime, time.Minute, start)
+	require.True(t, ok)
+	_, ok = d.older("dir/a", markerTime, time.Minute, start)
+	require.False(t, ok)
+
+	// markers of other directories, newer markers and the marker itself do not defer it
+	_, ok = d.older("other/c", markerTime.Add(time.Second), time.Minute, start)
+	require.False(t, ok)
+	_, ok = d.older("dir/a", markerTime.Add(-time.Second), time.Minute, start)
+	require.False(t, ok)
+	_, ok = d.older("dir/b", markerTime, time.Minute, start)
+	require.False(t, ok)
+
+	// once its deadline has passed, the older marker no longer defers newer markers
+	_, ok = d.older("dir/c", markerTime.Add(time.Second), time.Minute, start.Add(time.Minute))
+	require.False(t, ok)
+
+	var none *Deferrals
+	_, ok = none.older("dir/c", markerTime, time.Minute, start)
+	require.False(t, ok)
+}
@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"path/filepath"
+	"sync"
+)
+
+// InFlight tracks the marker files that processors are working on, from the moment a processor picks them up until
+// their local files are removed or kept. It is shared by the processors of a pipeline and the orphan sweep, so that
+// the sweep does not act on files that a processor is about to upload or remove.
+//
+// A nil InFlight is valid and tracks nothing.
+type InFlight struct {
+	mu      sync.Mutex
+	markers map[string]int // marker path -> number of times the marker is in flight
+}
+
+// add registers a marker file as in flight.
+func (f *InFlight) add(marker string) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.markers[filepath.Clean(marker)]++
+}
+
+// done marks a marker file as no longer in flight.
+func (f *InFlight) done(marker string) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	marker = filepath.Clean(marker)
+	if f.markers[marker] <= 1 {
+		delete(f.markers, marker)
+		return
+	}
+	f.markers[marker]--
+}
+
+// Covers returns true if the path is within the directory of a marker file in flight, including the marker file.
+func (f *InFlight) Covers(path string) bool {
+	if f == nil {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for marker := range f.markers {
+		if fsx.IsWithin(filepath.Dir(marker), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewInFlight creates the in-flight set of a pipeline.
+func NewInFlight() *InFlight {
+	return &InFlight{markers: make(map[string]int)}
+}
@@ -0,0 +1,28 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlight(t *testing.T) {
+	f := NewInFlight()
+	f.add("/data/record0.0.3/a.rcd_sig")
+	f.add("/data/record0.0.3/a.rcd_sig") // found again by a scan while being processed
+
+	require.True(t, f.Covers("/data/record0.0.3/a.rcd_sig"))
+	require.True(t, f.Covers("/data/record0.0.3/b.rcd.gz"))
+	require.True(t, f.Covers("/data/record0.0.3/sidecar/a_01.rcd.gz"))
+	require.False(t, f.Covers("/data/record0.0.4/b.rcd.gz"))
+
+	f.done("/data/record0.0.3/a.rcd_sig")
+	require.True(t, f.Covers("/data/record0.0.3/b.rcd.gz"))
+	f.done("/data/record0.0.3/a.rcd_sig")
+	require.False(t, f.Covers("/data/record0.0.3/b.rcd.gz"))
+
+	var none *InFlight
+	none.add("/data/record0.0.3/a.rcd_sig")
+	none.done("/data/record0.0.3/a.rcd_sig")
+	require.False(t, none.Covers("/data/record0.0.3/a.rcd_sig"))
+}
@@ -2,10 +2,19 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.hedera.com/solo-cheetah/internal/chain"
 	"golang.hedera.com/solo-cheetah/internal/config"
 	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"golang.hedera.com/solo-cheetah/internal/sequencer"
+	"golang.hedera.com/solo-cheetah/internal/signature"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
+	"golang.hedera.com/solo-cheetah/internal/validator"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"math/rand"
@@ -20,15 +29,25 @@ const DefaultDelayBeforeUpload = time.Millisecond * 150
 const DefaultMarkerFileCheckInterval = time.Millisecond * 100
 const DefaultMarkerFileCheckMaxAttempts = 3
 const DefaultMarkerFileCheckMinSize = 0 // default minimum size for marker files to be considered ready
+const DefaultCompletenessDeadline = time.Minute
 
 type processor struct {
 	id                 string
+	pipeline           string // name of the pipeline the processor belongs to, used as metrics label
 	storages           []core.Storage
 	fileMatcherConfigs []config.FileMatcherConfig
-	flushDelay         time.Duration     // delay before uploading files to allow flushing data files
-	backoffDelay       time.Duration     // delay before processing the next marker file after an error
-	markerCheckConfig  markerCheckConfig // configuration for marker file checks
-
+	markers            matcher.MarkerPatterns   // marker patterns with the file matchers of their marker files
+	flushDelay         time.Duration            // delay before uploading files to allow flushing data files
+	backoffDelay       time.Duration            // delay before processing the next marker file after an error
+	markerCheckConfig  markerCheckConfig        // configuration for marker file checks
+	completeness       completenessConfig       // configuration for deferring markers with incomplete candidate files
+	deferrals          *Deferrals               // markers deferred until their candidate files exist, nil if not rescanned
+	recorders          []core.Recorder          // recorders of processed marker files (e.g. upload journal)
+	sequencer          *sequencer.Sequencer     // publishes marker files in order, nil if ordered commit is disabled
+	inFlight           *InFlight                // marker files being processed, nil if no orphan sweep needs them
+	chain              *chain.Verifier          // verifies the running hash chain, nil if chain verification is disabled
+	signatures         *signature.Verifier      // verifies signature files, nil if signature verification is disabled
+	validators         []config.ValidatorConfig // validators of the candidate files before upload
 }
 
 // markerCheckConfig holds the configuration for checking marker files before processing them.
@@ -40,6 +59,11 @@ type markerCheckConfig struct {
 	minSize       int64
 }
 
+// completenessConfig holds the parsed config.CompletenessConfig.
+type completenessConfig struct {
+	deadline time.Duration
+}
+
 func (p *processor) Info() string {
 	return p.id
 }
@@ -154,6 +178,7 @@ func (p *processor) waitForMarkerFileToBeReady(ctx context.Context, marker core.
 				return nil, fmt.Errorf("marker file doesn't exist %s: %w", markerPath, err)
 			}
 
+			metrics.RetriesTotal.WithLabelValues(p.pipeline, metrics.RetryReasonMarkerCheck).Inc()
 			core.ApplyDelay(ctx, p.markerCheckConfig.checkInterval)
 			attempts++
 		}
@@ -189,43 +214,110 @@ func (p *processor) upload(ctx context.Context, markers <-chan core.ScannerResul
 	go func() {
 		defer close(processed)
 		for marker := range markers {
+			metrics.QueueDepth.WithLabelValues(p.pipeline).Dec()
 			select {
 			case <-ctx.Done():
 				logx.As().Warn().Msg("Processor context cancelled, stopping uploading files")
 			default:
+				p.inFlight.add(marker.Path)
+				pr := core.ProcessorResult{
+					Error:    nil,
+					Path:     marker.Path,
+					TraceId:  marker.TraceId,
+					Pipeline: p.pipeline,
+					Result:   make(map[string]*core.StorageResult),
+				}
+
 				if _, exists := fsx.PathExists(marker.Path); !exists {
+					p.deferrals.forget(marker.Path)
+					if !p.fail(ctx, processed, pr, fmt.Errorf("marker file no longer exists: %s", marker.Path)) {
+						return
+					}
 					continue
 				}
 
 				var err error
-				marker.Info, err = p.waitForMarkerFileToBeReady(ctx, marker)
+				waitCtx, waitSpan := tracing.StartMarkerSpan(ctx, "processor.wait_marker", marker.TraceId,
+					tracing.AttrPipeline.String(p.pipeline),
+					tracing.AttrMarker.String(marker.Path))
+				marker.Info, err = p.waitForMarkerFileToBeReady(waitCtx, marker)
+				tracing.EndSpan(waitSpan, err)
 				if err != nil {
+					metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassMarkerNotReady).Inc()
 					logx.As().Warn().
 						Err(err).
 						Str("marker", marker.Path).
 						Str("trace_id", marker.TraceId).
 						Msg("Failed to wait for marker file to be ready, skipping upload")
+					if !p.fail(ctx, processed, pr, err) {
+						return
+					}
 					continue // skip this file if it is not ready
 				}
 
-				stored := make(chan core.StorageResult) // shared channel to receive storage results, closed after all storages are done
-				pr := core.ProcessorResult{
-					Error:   nil,
-					Path:    marker.Path,
-					TraceId: marker.TraceId,
-					Result:  make(map[string]*core.StorageResult),
+				_, matchSpan := tracing.StartMarkerSpan(ctx, "processor.match_candidates", marker.TraceId,
+					tracing.AttrPipeline.String(p.pipeline),
+					tracing.AttrMarker.String(marker.Path))
+				candidates, err := p.prepareUploadCandidates(marker.Path, p.fileMatchers(marker.Marker))
+				matchSpan.SetAttributes(attribute.Int("candidates", len(candidates)))
+				tracing.EndSpan(matchSpan, err)
+				var incomplete *matcher.IncompleteError
+				if !errors.As(err, &incomplete) {
+					p.deferrals.forget(marker.Path)
+				} else if p.deferIncomplete(marker, err) {
+					p.release(marker.Path)
+					p.inFlight.done(marker.Path)
+					continue
+				}
+				if incomplete != nil {
+					metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassIncomplete).Inc()
+					logx.As().Error().
+						Err(err).
+						Str("marker", marker.Path).
+						Str("trace_id", marker.TraceId).
+						Dur("deadline", p.completeness.deadline).
+						Msg("Required candidate files are missing after deadline, skipping upload and keeping local files")
+					if !p.fail(ctx, processed, pr, err) {
+						return
+					}
+					continue
 				}
-
-				candidates, err := p.prepareUploadCandidates(marker.Path)
 				if err != nil {
+					metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassMatch).Inc()
 					logx.As().Warn().
 						Err(err).
 						Str("marker", marker.Path).
 						Str("trace_id", marker.TraceId).
 						Msg("Failed to prepare upload candidates, skipping upload")
+					if !p.fail(ctx, processed, pr, err) {
+						return
+					}
 					continue // skip this file if we cannot prepare candidates
 				}
 
+				if p.deferBehindOlder(marker) {
+					p.release(marker.Path)
+					p.inFlight.done(marker.Path)
+					continue
+				}
+
+				_, checkSpan := tracing.StartMarkerSpan(ctx, "processor.check", marker.TraceId,
+					tracing.AttrPipeline.String(p.pipeline),
+					tracing.AttrMarker.String(marker.Path))
+				err = p.check(marker.Path, candidates)
+				tracing.EndSpan(checkSpan, err)
+				if err != nil {
+					logx.As().Error().
+						Err(err).
+						Str("marker", marker.Path).
+						Str("trace_id", marker.TraceId).
+						Msg("Candidate files failed checks, skipping upload and keeping local files")
+					if !p.fail(ctx, processed, pr, err) {
+						return
+					}
+					continue
+				}
+
 				logx.As().Info().
 					Str("marker", marker.Path).
 					Str("trace_id", marker.TraceId).
@@ -234,31 +326,17 @@ func (p *processor) upload(ctx context.Context, markers <-chan core.ScannerResul
 					Msg("Processor processing marker file")
 
 				// parallel upload
-				var wg sync.WaitGroup
-				for _, storage := range p.storages {
-					wg.Add(1)
-					go func(s core.Storage) {
-						defer wg.Done()
-						s.Put(ctx, marker, candidates, stored)
-					}(storage)
-				}
-
-				// Wait for all storages to finish storing
-				go func() {
-					wg.Wait()
-					close(stored) // Close the channel after all storages are done
-				}()
-
-				// accumulate response from the storage handlers
-				for resp := range stored {
-					if resp.Error != nil {
-						if pr.Error == nil {
-							pr.Error = fmt.Errorf("%s: %s", resp.Error, resp.MarkerPath) // set the first error
-						}
-					}
-
-					pr.Result[resp.Type] = &resp
+				uploadCtx, uploadSpan := tracing.StartMarkerSpan(ctx, "processor.upload", marker.TraceId,
+					tracing.AttrPipeline.String(p.pipeline),
+					tracing.AttrMarker.String(marker.Path),
+					attribute.String("processor", p.Info()))
+				if p.sequencer == nil {
+					p.put(uploadCtx, marker, candidates, &pr)
+				} else {
+					p.putInOrder(uploadCtx, marker, candidates, &pr)
 				}
+				tracing.EndSpan(uploadSpan, pr.Error)
+				p.recordUploadMetrics(marker, pr)
 
 				// if there was an error, we apply a random backoff delay before processing the next marker file
 				// this is to avoid overwhelming the storage with requests in case of errors; also we don't want to scan
@@ -269,6 +347,7 @@ func (p *processor) upload(ctx context.Context, markers <-chan core.ScannerResul
 				if pr.Error != nil {
 					backoffMultiplier := rand.Intn(9) + 2 // random int between 2 and 10
 					backoffDelay = p.backoffDelay * time.Duration(backoffMultiplier)
+					metrics.RetriesTotal.WithLabelValues(p.pipeline, metrics.RetryReasonUploadBackoff).Inc()
 					logx.As().Warn().
 						Str("marker", marker.Path).
 						Str("trace_id", marker.TraceId).
@@ -294,6 +373,146 @@ func (p *processor) upload(ctx context.Context, markers <-chan core.ScannerResul
 	return processed
 }
 
+// check validates the candidate files of a marker and verifies their signature and running hash chain before they are
+// uploaded.
+func (p *processor) check(marker string, candidates []string) error {
+	if len(p.validators) > 0 {
+		if err := validator.ValidateCandidates(marker, candidates, p.validators); err != nil {
+			metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassValidation).Inc()
+			return err
+		}
+	}
+
+	if p.signatures != nil {
+		if err := p.signatures.Verify(marker, candidates); err != nil {
+			metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassSignature).Inc()
+			return err
+		}
+	}
+
+	// with ordered commit, the chain is verified once the older markers of the directory are done (see putInOrder)
+	if p.sequencer == nil {
+		return p.verifyChain(marker, candidates)
+	}
+
+	return nil
+}
+
+// verifyChain verifies the running hash chain of the candidate files, if chain verification is enabled.
+func (p *processor) verifyChain(marker string, candidates []string) error {
+	if p.chain == nil {
+		return nil
+	}
+
+	if err := p.chain.Verify(marker, candidates); err != nil {
+		metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassChain).Inc()
+		return fmt.Errorf("%w: %s", err, marker)
+	}
+
+	return nil
+}
+
+// put uploads the candidate files to all storages in parallel and accumulates the storage results into the processor
+// result. Results of a storage that already has a result are merged, keeping the first error.
+func (p *processor) put(ctx context.Context, marker core.ScannerResult, candidates []string, pr *core.ProcessorResult) {
+	stored := make(chan core.StorageResult) // shared channel to receive storage results, closed after all storages are done
+	var wg sync.WaitGroup
+	for _, storage := range p.storages {
+		wg.Add(1)
+		go func(s core.Storage) {
+			defer wg.Done()
+			s.Put(ctx, marker, candidates, stored)
+		}(storage)
+	}
+
+	// Wait for all storages to finish storing
+	go func() {
+		wg.Wait()
+		close(stored) // Close the channel after all storages are done
+	}()
+
+	// accumulate response from the storage handlers
+	for resp := range stored {
+		if resp.Error != nil {
+			if pr.Error == nil {
+				pr.Error = fmt.Errorf("%s: %s", resp.Error, resp.MarkerPath) // set the first error
+			}
+		}
+
+		if prev, ok := pr.Result[resp.Type]; ok {
+			resp.UploadResults = append(prev.UploadResults, resp.UploadResults...)
+			if prev.Error != nil {
+				resp.Error = prev.Error
+			}
+		}
+
+		pr.Result[resp.Type] = &resp
+	}
+}
+
+// putInOrder uploads the data files of a marker in parallel, then waits for the older markers of its directory to be
+// processed before uploading the marker file itself, so that marker files are published in timestamp order.
+// The marker is released once it has been published or has failed.
+func (p *processor) putInOrder(ctx context.Context, marker core.ScannerResult, candidates []string, pr *core.ProcessorResult) {
+	defer p.release(marker.Path)
+
+	var data, commit []string
+	for _, candidate := range candidates {
+		if candidate == marker.Path {
+			commit = append(commit, candidate)
+		} else {
+			data = append(data, candidate)
+		}
+	}
+
+	p.put(ctx, marker, data, pr)
+	if pr.Error != nil {
+		return
+	}
+
+	waitCtx, waitSpan := tracing.StartMarkerSpan(ctx, "processor.wait_commit", marker.TraceId,
+		tracing.AttrPipeline.String(p.pipeline),
+		tracing.AttrMarker.String(marker.Path))
+	err := p.sequencer.Wait(waitCtx, marker.Path)
+	tracing.EndSpan(waitSpan, err)
+	if err != nil {
+		pr.Error = fmt.Errorf("failed to wait for older marker files: %w: %s", err, marker.Path)
+		return
+	}
+
+	// the marker is not published if its files break the chain; its data files are kept on disk and uploaded again
+	if err := p.verifyChain(marker.Path, candidates); err != nil {
+		pr.Error = err
+		return
+	}
+
+	if len(commit) > 0 {
+		p.put(ctx, marker, commit, pr)
+	}
+}
+
+// fail releases a marker file that is not uploaded and sends its failed result, so that it is recorded; local files
+// are not removed for failed results. It returns false if the context is canceled.
+func (p *processor) fail(ctx context.Context, processed chan<- core.ProcessorResult, pr core.ProcessorResult,
+	err error) bool {
+	p.release(pr.Path)
+
+	pr.Error = err
+	select {
+	case processed <- pr:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release marks the marker file as processed for the sequencer, if marker files are published in order.
+func (p *processor) release(marker string) {
+	if p.sequencer != nil {
+		p.sequencer.Done(marker)
+	}
+}
+
 // remove handles the removal of local files after they have been successfully uploaded to remote storage.
 // It processes the results of the upload operation and ensures that files with no errors are deleted locally.
 // Any errors encountered during the removal process are sent to the provided error channel.
@@ -306,12 +525,14 @@ func (p *processor) upload(ctx context.Context, markers <-chan core.ScannerResul
 //   - A channel of errors, which contains any errors encountered during the file removal process.
 //
 // Behavior:
+//   - Each result is passed to the configured recorders before any local file is removed.
 //   - For each file, if the upload was successful, the local file is removed.
 //   - If an error occurs during the removal, it is sent to the error channel.
 //   - The function terminates processing if the context is canceled.
 //
 // Notes:
 //   - Files with upload errors are skipped and not removed.
+//   - Files whose result could not be recorded are skipped and not removed.
 //   - The returned error channel is closed after all files have been processed or if the context is canceled.
 func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResult) <-chan error {
 	sch := make(chan error, 1)
@@ -327,6 +548,8 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 					Msg("Processor context cancelled, stopping file removal")
 				return
 			default:
+				recordErr := p.record(resp)
+
 				if resp.Error != nil {
 					if resp.Error != nil {
 						logx.As().Warn().
@@ -336,6 +559,7 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 							Msg("One or more storage sync operation has failed. Skipping file removal")
 					}
 
+					p.inFlight.done(resp.Path)
 					select {
 					case sch <- resp.Error:
 					case <-ctx.Done():
@@ -344,7 +568,27 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 					continue // skip file removal if there was an error
 				}
 
+				if recordErr != nil {
+					logx.As().Warn().
+						Str("processor", p.Info()).
+						Str("marker", resp.Path).
+						Str("trace_id", resp.TraceId).
+						Msg("Failed to record processed marker file. Skipping file removal")
+
+					p.inFlight.done(resp.Path)
+					select {
+					case sch <- recordErr:
+					case <-ctx.Done():
+						return
+					}
+					continue // keep local files so that the upload is not lost from the record
+				}
+
 				removalCandidates := p.prepareRemovalCandidates(resp)
+				removeCtx, removeSpan := tracing.StartMarkerSpan(ctx, "processor.remove", resp.TraceId,
+					tracing.AttrPipeline.String(p.pipeline),
+					tracing.AttrMarker.String(resp.Path),
+					attribute.Int("files", len(removalCandidates)))
 
 				logx.As().Info().
 					Str("marker", resp.Path).
@@ -355,8 +599,11 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 
 				for _, pathToRemove := range removalCandidates {
 					if _, exists := fsx.PathExists(pathToRemove); exists {
+						_, fileSpan := tracing.StartSpan(removeCtx, "processor.remove_file", tracing.AttrSrc.String(pathToRemove))
 						err := os.Remove(pathToRemove)
+						tracing.EndSpan(fileSpan, err)
 						if err != nil {
+							metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassRemove).Inc()
 							logx.As().
 								Err(err).
 								Str("trace_id", resp.TraceId).
@@ -365,6 +612,7 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 							select {
 							case sch <- err:
 							case <-ctx.Done():
+								removeSpan.End()
 								return
 							}
 						}
@@ -375,41 +623,126 @@ func (p *processor) remove(ctx context.Context, stored <-chan core.ProcessorResu
 							Msg("Removed local file after successful upload")
 					}
 				}
+				removeSpan.End()
+				p.inFlight.done(resp.Path)
 			}
 		}
 	}()
 	return sch
 }
 
-func (p *processor) prepareUploadCandidates(marker string) ([]string, error) {
-	var candidates []string
-	for _, mc := range p.fileMatcherConfigs {
-		m, err := matcher.GetFileMatcher(mc.MatcherType)
-		if err != nil {
-			return nil, fmt.Errorf("unknown file matcher type: %s", mc.MatcherType)
+// record passes the processor result to all recorders and returns the first error encountered.
+func (p *processor) record(resp core.ProcessorResult) error {
+	var firstErr error
+	for _, r := range p.recorders {
+		if err := r.Record(resp); err != nil {
+			logx.As().Error().
+				Err(err).
+				Str("marker", resp.Path).
+				Str("trace_id", resp.TraceId).
+				Str("processor", p.Info()).
+				Msg("Failed to record processed marker file")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to record marker %s: %w", resp.Path, err)
+			}
 		}
+	}
 
-		matches, err := m.MatchFiles(marker, mc)
-		if err != nil {
-			return nil, fmt.Errorf("failed to match files for marker %s: %w", marker, err)
+	return firstErr
+}
+
+// recordUploadMetrics records the upload metrics of a processed marker file for each storage handler.
+// Files skipped because of a matching checksum are counted as uploaded as well as checksum skips.
+func (p *processor) recordUploadMetrics(marker core.ScannerResult, pr core.ProcessorResult) {
+	for storageType, storageResult := range pr.Result {
+		if storageResult.Error != nil {
+			metrics.ErrorsTotal.WithLabelValues(p.pipeline, metrics.ErrorClassUpload).Inc()
+			continue
 		}
 
+		for _, info := range storageResult.UploadResults {
+			if info == nil {
+				continue
+			}
+
+			metrics.FilesUploaded.WithLabelValues(p.pipeline, storageType).Inc()
+			metrics.BytesUploaded.WithLabelValues(p.pipeline, storageType).Add(float64(info.Size))
+			metrics.UploadDuration.WithLabelValues(p.pipeline, storageType).Observe(info.Duration.Seconds())
+			if info.Skipped {
+				metrics.ChecksumSkips.WithLabelValues(p.pipeline, storageType).Inc()
+			}
+		}
+	}
+
+	if pr.Error == nil && marker.Info != nil {
+		metrics.MarkerAge.WithLabelValues(p.pipeline).Observe(time.Since(marker.Info.ModTime()).Seconds())
+	}
+}
+
+// deferIncomplete returns true if the marker, whose required candidate files do not exist, is deferred to a later
+// scan rather than reported: until the completeness deadline has passed since it was first found incomplete, and
+// after it has been reported once.
+func (p *processor) deferIncomplete(marker core.ScannerResult, err error) bool {
+	t, _ := scanner.MarkerTime(marker.Path, marker.Info)
+	switch p.deferrals.incomplete(marker.Path, t, p.completeness.deadline, time.Now()) {
+	case deferMarker:
+		logx.As().Debug().
+			Err(err).
+			Str("marker", marker.Path).
+			Str("trace_id", marker.TraceId).
+			Dur("deadline", p.completeness.deadline).
+			Msg("Required candidate files do not exist yet, deferring marker")
+		metrics.RetriesTotal.WithLabelValues(p.pipeline, metrics.RetryReasonIncomplete).Inc()
+		return true
+	case skipMarker:
 		logx.As().Debug().
-			Str("marker", marker).
-			Str("matcher", m.Type()).
-			Str("matches", fmt.Sprintf("%v", matches)).
-			Str("patterns", fmt.Sprintf("%v", mc.Patterns)).
-			Msg("Results of matcher")
+			Err(err).
+			Str("marker", marker.Path).
+			Str("trace_id", marker.TraceId).
+			Msg("Required candidate files are still missing, marker was already reported")
+		return true
+	default:
+		return false
+	}
+}
 
-		candidates = append(candidates, matches...)
+// deferBehindOlder returns true if the marker is deferred to a later scan because an older marker of its directory
+// is deferred until its required candidate files exist. Only markers published in order are deferred, so that a
+// newer marker is never published ahead of an older one that is still expected to complete.
+func (p *processor) deferBehindOlder(marker core.ScannerResult) bool {
+	if p.sequencer == nil {
+		return false
 	}
 
-	return candidates, nil
+	t, _ := scanner.MarkerTime(marker.Path, marker.Info)
+	older, ok := p.deferrals.older(marker.Path, t, p.completeness.deadline, time.Now())
+	if !ok {
+		return false
+	}
+
+	logx.As().Debug().
+		Str("marker", marker.Path).
+		Str("trace_id", marker.TraceId).
+		Str("deferred", older).
+		Msg("Older marker file of the directory is deferred, deferring marker")
+	metrics.RetriesTotal.WithLabelValues(p.pipeline, metrics.RetryReasonIncomplete).Inc()
+	return true
+}
+
+func (p *processor) prepareUploadCandidates(marker string, configs []config.FileMatcherConfig) ([]string, error) {
+	return matcher.MatchCandidates(marker, configs)
+}
+
+// fileMatchers returns the file matcher configurations of the marker files of the marker pattern.
+func (p *processor) fileMatchers(pattern string) []config.FileMatcherConfig {
+	if m := p.markers.Lookup(pattern); m != nil {
+		return m.Matchers
+	}
+	return p.fileMatcherConfigs
 }
 
 func (p *processor) prepareRemovalCandidates(resp core.ProcessorResult) []string {
 	uniqueCandidates := make(map[string]struct{})
-	uniqueCandidates[resp.Path] = struct{}{}
 
 	for _, storageResult := range resp.Result {
 		if storageResult.Error != nil {
@@ -422,17 +755,40 @@ func (p *processor) prepareRemovalCandidates(resp core.ProcessorResult) []string
 		}
 	}
 
-	removalCandidates := make([]string, 0, len(uniqueCandidates))
+	delete(uniqueCandidates, resp.Path) // the marker file is usually uploaded with its candidate files
+	removalCandidates := make([]string, 0, len(uniqueCandidates)+1)
 	for path := range uniqueCandidates {
 		removalCandidates = append(removalCandidates, path)
 	}
 
 	sort.Strings(removalCandidates) // ensure deterministic order
 
-	return removalCandidates
+	// the marker file is removed last, so that data files on disk are always claimed by their marker file
+	return append(removalCandidates, resp.Path)
 }
 
-func NewProcessor(id string, storages []core.Storage, pc *config.ProcessorConfig) (core.Processor, error) {
+// Shared holds the state shared by the processors of a pipeline.
+type Shared struct {
+	// Sequencer publishes marker files in order; nil if ordered commit is disabled.
+	Sequencer *sequencer.Sequencer
+	// Chain verifies the running hash chain of stream files; nil if chain verification is disabled.
+	Chain *chain.Verifier
+	// Signatures verifies signature files against the node public keys; nil if signature verification is disabled.
+	Signatures *signature.Verifier
+	// Markers are the marker patterns of the scanner with the file matchers of their marker files; if nil, all the
+	// marker files use the file matchers of the processor.
+	Markers matcher.MarkerPatterns
+	// Deferrals tracks the markers deferred until their required candidate files exist; nil if the scanner does not
+	// run again, so that incomplete markers are reported at once.
+	Deferrals *Deferrals
+	// InFlight tracks the marker files being processed, so that the orphan sweep leaves their files alone; nil if the
+	// orphan sweep is disabled.
+	InFlight *InFlight
+}
+
+// NewProcessor creates a processor of the pipeline. The shared state is shared by all the processors of the pipeline.
+func NewProcessor(id string, pipeline string, storages []core.Storage, pc *config.ProcessorConfig, shared Shared,
+	recorders ...core.Recorder) (core.Processor, error) {
 	flushDelay := DefaultDelayBeforeUpload
 	var err error
 	if pc.FlushDelay != "" {
@@ -467,7 +823,46 @@ func NewProcessor(id string, storages []core.Storage, pc *config.ProcessorConfig
 		mc.maxAttempts = pc.MarkerCheckConfig.MaxAttempts
 	}
 
-	return newProcessor(id, storages, pc.FileMatcherConfigs, flushDelay, backoffDelay, mc)
+	for _, fc := range pc.FileMatcherConfigs {
+		if err := matcher.CheckRequirement(fc); err != nil {
+			return nil, fmt.Errorf("invalid file matcher configuration: %w", err)
+		}
+		if err := matcher.CheckSequence(fc); err != nil {
+			return nil, fmt.Errorf("invalid file matcher configuration: %w", err)
+		}
+	}
+
+	completeness := completenessConfig{deadline: DefaultCompletenessDeadline}
+	if pc.Completeness != nil && pc.Completeness.Deadline != "" {
+		completeness.deadline, err = time.ParseDuration(pc.Completeness.Deadline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse completeness deadline: %w", err)
+		}
+	}
+
+	for _, vc := range pc.Validators {
+		if _, err := validator.GetValidator(vc.ValidatorType); err != nil {
+			return nil, fmt.Errorf("invalid validator configuration: %w", err)
+		}
+	}
+
+	p, err := newProcessor(id, storages, pc.FileMatcherConfigs, flushDelay, backoffDelay, mc)
+	if err != nil {
+		return nil, err
+	}
+
+	p.pipeline = pipeline
+	p.completeness = completeness
+	p.recorders = recorders
+	p.sequencer = shared.Sequencer
+	p.chain = shared.Chain
+	p.signatures = shared.Signatures
+	p.markers = shared.Markers
+	p.deferrals = shared.Deferrals
+	p.inFlight = shared.InFlight
+	p.validators = pc.Validators
+
+	return p, nil
 }
 
 func newProcessor(id string, storages []core.Storage, fileMatchersConfigs []config.FileMatcherConfig,
@@ -479,5 +874,6 @@ func newProcessor(id string, storages []core.Storage, fileMatchersConfigs []conf
 		flushDelay:         flushDelay,
 		backoffDelay:       backoffDelay,
 		markerCheckConfig:  mc,
+		completeness:       completenessConfig{deadline: DefaultCompletenessDeadline},
 	}, nil
 }
@@ -3,15 +3,20 @@ package processor
 import (
 	"context"
 	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.hedera.com/solo-cheetah/internal/config"
 	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/sequencer"
+	"golang.hedera.com/solo-cheetah/internal/validator"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -46,6 +51,38 @@ func (m *mockStorage) Put(ctx context.Context, item core.ScannerResult, candidat
 	}
 }
 
+func (m *mockStorage) Upload(ctx context.Context, src string, key string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
+	return &core.UploadInfo{Src: src, Dest: key}, nil
+}
+
+func (m *mockStorage) Supports(c core.Capability) bool {
+	return false
+}
+
+func (m *mockStorage) Key(src string) string {
+	return src
+}
+
+func (m *mockStorage) Stat(ctx context.Context, key string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (m *mockStorage) List(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (m *mockStorage) Get(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (m *mockStorage) Delete(ctx context.Context, key string) error {
+	return core.ErrNotSupported
+}
+
+func (m *mockStorage) Copy(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+
 func TestProcess_Upload_Success(t *testing.T) {
 	tempDir := t.TempDir()
 	defer func() {
@@ -122,7 +159,7 @@ func TestProcess_Upload_Failure(t *testing.T) {
 		}
 	}
 
-	// add an invalid file, which should be ignored
+	// add a file that no longer exists, which should be reported as failed
 	items <- core.ScannerResult{Path: filepath.Join(tempDir, "invalid.txt")}
 	close(items)
 
@@ -163,7 +200,7 @@ func TestProcess_Upload_Failure(t *testing.T) {
 		assert.Error(t, result.Error)
 	}
 
-	assert.Equal(t, 2, totalResults)
+	assert.Equal(t, 3, totalResults)
 }
 
 func TestProcess_Remove_Success(t *testing.T) {
@@ -200,6 +237,9 @@ func TestProcess_Remove_Success(t *testing.T) {
 	}
 	p, err := newProcessor("test-processor", nil, fileMatcherConfigs, delay, delay, mc)
 	assert.NoError(t, err)
+	p.inFlight = NewInFlight()
+	p.inFlight.add(testFile1)
+	p.inFlight.add(testFile2)
 
 	// Execute the remove function
 	errors := p.remove(ctx, stored)
@@ -214,6 +254,9 @@ func TestProcess_Remove_Success(t *testing.T) {
 	assert.False(t, exists)
 	_, exists = fsx.PathExists(testFile2)
 	assert.False(t, exists)
+
+	// Verify the markers are no longer in flight
+	assert.False(t, p.inFlight.Covers(testFile1))
 }
 
 func TestProcess_Remove_Failure(t *testing.T) {
@@ -269,6 +312,115 @@ func TestProcess_Remove_Failure(t *testing.T) {
 	assert.True(t, exists)
 }
 
+type mockRecorder struct {
+	err     error
+	results []core.ProcessorResult
+	mu      sync.Mutex
+}
+
+func (m *mockRecorder) Record(result core.ProcessorResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+	return m.err
+}
+
+func TestProcess_Remove_Recorder(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile1 := filepath.Join(tempDir, "file1.txt")
+	testFile2 := filepath.Join(tempDir, "file2.txt")
+	require.NoError(t, os.WriteFile(testFile1, []byte("test content"), 0644))
+	require.NoError(t, os.WriteFile(testFile2, []byte("test content"), 0644))
+
+	stored := make(chan core.ProcessorResult, 2)
+	stored <- core.ProcessorResult{Path: testFile1, TraceId: "trace-1", Error: nil}
+	stored <- core.ProcessorResult{Path: testFile2, TraceId: "trace-2", Error: fmt.Errorf("upload failed")}
+	close(stored)
+
+	p, err := newProcessor("test-processor", nil, nil, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	recorder := &mockRecorder{}
+	p.recorders = []core.Recorder{recorder}
+
+	var errs []error
+	for err := range p.remove(context.Background(), stored) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+
+	// both successful and failed results are recorded
+	require.Len(t, recorder.results, 2)
+	require.Equal(t, "trace-1", recorder.results[0].TraceId)
+	require.Equal(t, "trace-2", recorder.results[1].TraceId)
+
+	_, exists := fsx.PathExists(testFile1)
+	require.False(t, exists)
+	_, exists = fsx.PathExists(testFile2)
+	require.True(t, exists)
+}
+
+func TestProcess_Remove_RecorderFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "file1.txt")
+	require.NoError(t, os.WriteFile(testFile, []byte("test content"), 0644))
+
+	stored := make(chan core.ProcessorResult, 1)
+	stored <- core.ProcessorResult{Path: testFile, Error: nil}
+	close(stored)
+
+	p, err := newProcessor("test-processor", nil, nil, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	p.recorders = []core.Recorder{&mockRecorder{err: fmt.Errorf("disk full")}}
+
+	var errs []error
+	for err := range p.remove(context.Background(), stored) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "disk full")
+
+	// local file must be kept if the result could not be recorded
+	_, exists := fsx.PathExists(testFile)
+	require.True(t, exists)
+}
+
+func TestProcess_Skipped_Recorded(t *testing.T) {
+	tempDir := t.TempDir()
+	vanished := filepath.Join(tempDir, "vanished.txt")
+	notReady := filepath.Join(tempDir, "empty.txt")
+	unmatched := filepath.Join(tempDir, "file1.txt")
+	require.NoError(t, os.WriteFile(notReady, nil, 0644))
+	require.NoError(t, os.WriteFile(unmatched, []byte("test content"), 0644))
+
+	items := make(chan core.ScannerResult, 3)
+	for _, path := range []string{vanished, notReady, unmatched} {
+		items <- core.ScannerResult{Path: path, TraceId: filepath.Base(path)}
+	}
+	close(items)
+
+	// the empty marker never reaches the minimum size and the matcher of the other marker does not exist
+	mc := markerCheckConfig{checkInterval: time.Millisecond, maxAttempts: 1, minSize: 1}
+	p, err := newProcessor("test-processor", nil, []config.FileMatcherConfig{{MatcherType: "unknown"}}, 0, 0, mc)
+	require.NoError(t, err)
+	recorder := &mockRecorder{}
+	p.recorders = []core.Recorder{recorder}
+
+	ech := make(chan error, 3)
+	p.Process(context.Background(), items, ech)
+	close(ech)
+	require.Len(t, ech, 3)
+
+	// markers that are skipped are recorded as failed, and their files are kept
+	require.Len(t, recorder.results, 3)
+	for i, path := range []string{vanished, notReady, unmatched} {
+		require.Equal(t, path, recorder.results[i].Path)
+		require.Error(t, recorder.results[i].Error)
+	}
+	require.ErrorContains(t, recorder.results[0].Error, "no longer exists")
+	require.FileExists(t, notReady)
+	require.FileExists(t, unmatched)
+}
+
 func TestNewProcessor_DelayParsing(t *testing.T) {
 	var storages []core.Storage
 	fileMatcherConfigs := []config.FileMatcherConfig{
@@ -284,28 +436,28 @@ func TestNewProcessor_DelayParsing(t *testing.T) {
 		FileMatcherConfigs: fileMatcherConfigs,
 	}
 
-	p, err := NewProcessor("test", storages, pc)
+	p, err := NewProcessor("test", "test-pipeline", storages, pc, Shared{})
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
 	assert.Equal(t, 250*time.Millisecond, p.(*processor).flushDelay)
 
 	// Default flushDelay (0)
 	pc.FlushDelay = "0ms"
-	p, err = NewProcessor("test", storages, pc)
+	p, err = NewProcessor("test", "test-pipeline", storages, pc, Shared{})
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
 	assert.Equal(t, time.Millisecond*0, p.(*processor).flushDelay)
 
 	// Default flushDelay (empty string)
 	pc.FlushDelay = ""
-	p, err = NewProcessor("test", storages, pc)
+	p, err = NewProcessor("test", "test-pipeline", storages, pc, Shared{})
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
 	assert.Equal(t, 150*time.Millisecond, p.(*processor).flushDelay)
 
 	// Invalid flushDelay
 	pc.FlushDelay = "notaduration"
-	p, err = NewProcessor("test", storages, pc)
+	p, err = NewProcessor("test", "test-pipeline", storages, pc, Shared{})
 	assert.Error(t, err)
 	assert.Nil(t, p)
 }
@@ -408,7 +560,7 @@ func TestProcessor_PrepareRemovalCandidates_DeduplicationAndOrder(t *testing.T)
 	}
 
 	got := p.prepareRemovalCandidates(resp)
-	want := []string{"/tmp/file1.txt", "/tmp/file2.txt", "/tmp/file3.txt"}
+	want := []string{"/tmp/file2.txt", "/tmp/file3.txt", "/tmp/file1.txt"}
 	assert.Equal(t, want, got)
 }
 
@@ -434,7 +586,7 @@ func TestProcessor_PrepareRemovalCandidates_SkipErroredStorage(t *testing.T) {
 	}
 
 	got := p.prepareRemovalCandidates(resp)
-	want := []string{"/tmp/file1.txt", "/tmp/file3.txt"}
+	want := []string{"/tmp/file3.txt", "/tmp/file1.txt"}
 	assert.Equal(t, want, got)
 }
 
@@ -450,3 +602,348 @@ func TestProcessor_PrepareRemovalCandidates_EmptyResult(t *testing.T) {
 	want := []string{"/tmp/file1.txt"}
 	assert.Equal(t, want, got)
 }
+
+func TestProcessor_RecordUploadMetrics(t *testing.T) {
+	p := &processor{pipeline: "metrics-pipeline"}
+
+	markerPath := filepath.Join(t.TempDir(), "file1.txt")
+	require.NoError(t, os.WriteFile(markerPath, []byte("test content"), 0644))
+	info, err := os.Stat(markerPath)
+	require.NoError(t, err)
+
+	pr := core.ProcessorResult{
+		Path: markerPath,
+		Result: map[string]*core.StorageResult{
+			"S3": {
+				UploadResults: []*core.UploadInfo{
+					{Src: markerPath, Size: 10, Duration: time.Millisecond},
+					{Src: "/tmp/file2.txt", Size: 20, Skipped: true},
+				},
+			},
+			"GCS": {
+				Error: fmt.Errorf("upload failed"),
+			},
+		},
+	}
+
+	p.recordUploadMetrics(core.ScannerResult{Path: markerPath, Info: info}, pr)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.FilesUploaded.WithLabelValues("metrics-pipeline", "S3")))
+	assert.Equal(t, float64(30), testutil.ToFloat64(metrics.BytesUploaded.WithLabelValues("metrics-pipeline", "S3")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ChecksumSkips.WithLabelValues("metrics-pipeline", "S3")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.FilesUploaded.WithLabelValues("metrics-pipeline", "GCS")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ErrorsTotal.WithLabelValues("metrics-pipeline", metrics.ErrorClassUpload)))
+}
+
+func TestProcess_Upload_OrderedCommit(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var markers []core.ScannerResult
+	for _, name := range []string{"2024-01-01T00_00_00.000000000Z", "2024-01-01T00_00_02.000000000Z"} {
+		for _, ext := range []string{".rcd.gz", ".rcd_sig"} {
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, name+ext), []byte("test content"), 0644))
+		}
+		path := filepath.Join(tempDir, name+".rcd_sig")
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		markers = append(markers, core.ScannerResult{Path: path, Info: info})
+	}
+
+	// the data files of the older marker upload slowly, so that the newer marker would be published first
+	var mu sync.Mutex
+	var published []string
+	storages := []core.Storage{
+		&mockStorage{id: "mock-storage-1", storageType: "S3",
+			putFunc: func(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+				if item.Path == markers[0].Path && len(candidates) > 0 && candidates[0] != item.Path {
+					time.Sleep(100 * time.Millisecond)
+				}
+
+				var results []*core.UploadInfo
+				mu.Lock()
+				for _, c := range candidates {
+					published = append(published, filepath.Base(c))
+					results = append(results, &core.UploadInfo{Src: c})
+				}
+				mu.Unlock()
+
+				stored <- core.StorageResult{MarkerPath: item.Path, Type: "S3", Handler: "mock-storage-1", UploadResults: results}
+			}},
+	}
+
+	fileMatcherConfigs := []config.FileMatcherConfig{
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd.gz", ".rcd_sig"},
+		},
+	}
+
+	seq := sequencer.NewSequencer("test-pipeline", time.Minute)
+	var wg sync.WaitGroup
+	for i, m := range markers {
+		seq.Add(m)
+
+		p, err := newProcessor(fmt.Sprintf("test-processor-%d", i), storages, fileMatcherConfigs, 0, 0, markerCheckConfig{})
+		require.NoError(t, err)
+		p.sequencer = seq
+
+		items := make(chan core.ScannerResult, 1)
+		items <- m
+		close(items)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for result := range p.upload(context.Background(), items) {
+				assert.NoError(t, result.Error)
+				assert.Len(t, result.Result["S3"].UploadResults, 2)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the data file of the newer marker is uploaded in parallel, but its marker is published last
+	require.Len(t, published, 4)
+	require.Equal(t, "2024-01-01T00_00_02.000000000Z.rcd.gz", published[0])
+	require.Equal(t, []string{"2024-01-01T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_02.000000000Z.rcd_sig"},
+		[]string{published[2], published[3]})
+}
+
+func TestProcess_Upload_ValidationFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	data := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz")
+	marker := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	require.NoError(t, os.WriteFile(data, []byte("truncated"), 0644))
+	require.NoError(t, os.WriteFile(marker, []byte("signature"), 0644))
+	info, err := os.Stat(marker)
+	require.NoError(t, err)
+
+	items := make(chan core.ScannerResult, 1)
+	items <- core.ScannerResult{Path: marker, Info: info}
+	close(items)
+
+	storages := []core.Storage{
+		&mockStorage{id: "mock-storage-1", storageType: "S3",
+			putFunc: func(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+				t.Errorf("invalid files must not be uploaded: %v", candidates)
+				stored <- core.StorageResult{MarkerPath: item.Path, Type: "S3", Handler: "mock-storage-1"}
+			}},
+	}
+
+	fileMatcherConfigs := []config.FileMatcherConfig{
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd.gz", ".rcd_sig"},
+		},
+	}
+	p, err := newProcessor("test-processor", storages, fileMatcherConfigs, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	p.pipeline = "test-validation"
+	p.validators = []config.ValidatorConfig{{ValidatorType: validator.ValidatorGzip}}
+
+	ech := make(chan error, 10)
+	p.Process(context.Background(), items, ech)
+	close(ech)
+
+	var errs []error
+	for err := range ech {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], data+": gzip validation failed")
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.ErrorsTotal.WithLabelValues("test-validation", metrics.ErrorClassValidation)))
+
+	// invalid files are kept on disk
+	require.FileExists(t, data)
+	require.FileExists(t, marker)
+
+	_, err = NewProcessor("test", "test-pipeline", storages, &config.ProcessorConfig{
+		Validators: []config.ValidatorConfig{{ValidatorType: "unknown"}},
+	}, Shared{})
+	require.ErrorContains(t, err, "invalid validator configuration")
+}
+
+func TestProcess_Upload_Incomplete(t *testing.T) {
+	tempDir := t.TempDir()
+	lateData := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz")
+	lateMarker := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	missingMarker := filepath.Join(tempDir, "2024-01-01T00_00_02.000000000Z.rcd_sig")
+	require.NoError(t, os.WriteFile(lateMarker, []byte("signature"), 0644))
+	require.NoError(t, os.WriteFile(missingMarker, []byte("signature"), 0644))
+
+	var uploaded [][]string
+	storages := []core.Storage{
+		&mockStorage{id: "mock-storage-1", storageType: "S3",
+			putFunc: func(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+				uploaded = append(uploaded, candidates)
+				stored <- core.StorageResult{MarkerPath: item.Path, Type: "S3", Handler: "mock-storage-1"}
+			}},
+	}
+
+	fileMatcherConfigs := []config.FileMatcherConfig{
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd.gz"},
+			Requirement: matcher.RequirementRequired,
+		},
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd_sig"},
+		},
+	}
+	p, err := newProcessor("test-processor", storages, fileMatcherConfigs, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	p.pipeline = "test-incomplete"
+	p.completeness = completenessConfig{deadline: 100 * time.Millisecond}
+	p.deferrals = NewDeferrals()
+
+	// scan processes the markers that still exist once, like a scan of the scanner directory
+	scan := func() []error {
+		items := make(chan core.ScannerResult, 2)
+		for _, m := range []string{lateMarker, missingMarker} {
+			if info, err := os.Stat(m); err == nil {
+				items <- core.ScannerResult{Path: m, Info: info}
+			}
+		}
+		close(items)
+
+		ech := make(chan error, 10)
+		start := time.Now()
+		p.Process(context.Background(), items, ech)
+		close(ech)
+		require.Less(t, time.Since(start), p.completeness.deadline, "incomplete markers do not block the processor")
+
+		var errs []error
+		for err := range ech {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+
+	// both markers are deferred without blocking
+	require.Empty(t, scan())
+	require.Empty(t, uploaded)
+
+	// the data file of the first marker is written while the marker is deferred
+	require.NoError(t, os.WriteFile(lateData, []byte("data"), 0644))
+	require.Empty(t, scan())
+	require.Equal(t, [][]string{{lateData, lateMarker}}, uploaded)
+	require.NoFileExists(t, lateMarker)
+
+	// the deadline runs from the first scan, and the marker is reported once
+	time.Sleep(p.completeness.deadline)
+	errs := scan()
+	require.Len(t, errs, 1)
+	var incomplete *matcher.IncompleteError
+	require.ErrorAs(t, errs[0], &incomplete)
+	require.Equal(t, missingMarker, incomplete.Marker)
+	require.Empty(t, scan())
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.ErrorsTotal.WithLabelValues("test-incomplete", metrics.ErrorClassIncomplete)))
+	require.FileExists(t, missingMarker) // incomplete markers are kept on disk
+
+	// without later scans, incomplete markers are reported at once
+	p.deferrals = nil
+	require.Len(t, scan(), 1)
+
+	_, err = NewProcessor("test", "test-pipeline", storages, &config.ProcessorConfig{
+		FileMatcherConfigs: []config.FileMatcherConfig{{MatcherType: matcher.FileMatcherBasic, Requirement: matcher.RequirementAtLeast}},
+	}, Shared{})
+	require.ErrorContains(t, err, "minMatches must be positive")
+}
+
+func TestProcess_Upload_IncompleteOrdered(t *testing.T) {
+	tempDir := t.TempDir()
+	olderData := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd.gz")
+	olderMarker := filepath.Join(tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	newerMarker := filepath.Join(tempDir, "2024-01-01T00_00_02.000000000Z.rcd_sig")
+	require.NoError(t, os.WriteFile(olderMarker, []byte("signature"), 0644))
+	require.NoError(t, os.WriteFile(newerMarker, []byte("signature"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "2024-01-01T00_00_02.000000000Z.rcd.gz"), []byte("data"), 0644))
+
+	var published []string
+	storages := []core.Storage{
+		&mockStorage{id: "mock-storage-1", storageType: "S3",
+			putFunc: func(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+				for _, c := range candidates {
+					if c == item.Path {
+						published = append(published, filepath.Base(c))
+					}
+				}
+				stored <- core.StorageResult{MarkerPath: item.Path, Type: "S3", Handler: "mock-storage-1"}
+			}},
+	}
+
+	fileMatcherConfigs := []config.FileMatcherConfig{
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd.gz"},
+			Requirement: matcher.RequirementRequired,
+		},
+		{
+			MatcherType: matcher.FileMatcherBasic,
+			Patterns:    []string{".rcd_sig"},
+		},
+	}
+	p, err := newProcessor("test-processor", storages, fileMatcherConfigs, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	p.pipeline = "test-incomplete-ordered"
+	p.completeness = completenessConfig{deadline: time.Minute}
+	p.deferrals = NewDeferrals()
+	p.sequencer = sequencer.NewSequencer("test-incomplete-ordered", time.Minute)
+
+	// scan processes the markers that still exist once, oldest first
+	scan := func() {
+		items := make(chan core.ScannerResult, 2)
+		for _, m := range []string{olderMarker, newerMarker} {
+			if info, err := os.Stat(m); err == nil {
+				result := core.ScannerResult{Path: m, Info: info}
+				p.sequencer.Add(result)
+				items <- result
+			}
+		}
+		close(items)
+
+		ech := make(chan error, 10)
+		p.Process(context.Background(), items, ech)
+		close(ech)
+		for err := range ech {
+			require.NoError(t, err)
+		}
+	}
+
+	// the newer marker is complete, but is deferred with the older marker
+	scan()
+	require.Empty(t, published)
+	require.FileExists(t, newerMarker)
+
+	// both markers are published in order once the data file of the older marker is written
+	require.NoError(t, os.WriteFile(olderData, []byte("data"), 0644))
+	scan()
+	require.Equal(t, []string{"2024-01-01T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_02.000000000Z.rcd_sig"},
+		published)
+}
+
+func TestProcessor_FileMatchers(t *testing.T) {
+	defaults := []config.FileMatcherConfig{{MatcherType: matcher.FileMatcherBasic, Patterns: []string{".rcd.gz"}}}
+	own := []config.FileMatcherConfig{{MatcherType: matcher.FileMatcherBasic, Patterns: []string{".evts"}}}
+
+	p, err := newProcessor("test-processor", nil, defaults, 0, 0, markerCheckConfig{})
+	require.NoError(t, err)
+	require.Equal(t, defaults, p.fileMatchers(".evts_sig"), "without marker patterns the processor matchers apply")
+
+	p.markers, err = matcher.NewMarkerPatterns(".rcd_sig", []config.MarkerConfig{
+		{Pattern: ".evts_sig", FileMatcherConfigs: own},
+	}, defaults)
+	require.NoError(t, err)
+	// extension markers pass their extension to the matchers
+	withExt := func(configs []config.FileMatcherConfig, ext string) []config.FileMatcherConfig {
+		configs = append([]config.FileMatcherConfig(nil), configs...)
+		for i := range configs {
+			configs[i].MarkerExt = ext
+		}
+		return configs
+	}
+	require.Equal(t, withExt(defaults, ".rcd_sig"), p.fileMatchers(".rcd_sig"))
+	require.Equal(t, withExt(own, ".evts_sig"), p.fileMatchers(".evts_sig"))
+	require.Equal(t, defaults, p.fileMatchers(""))
+}
@@ -0,0 +1,224 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the default number of parallel downloads.
+const DefaultConcurrency = 8
+
+// partialSuffix is appended to files being downloaded so that partially downloaded files are never mistaken for
+// restored files.
+const partialSuffix = ".part"
+
+// Options holds the options for restoring objects from a storage.
+type Options struct {
+	// DestDir is the local directory where the objects are restored.
+	DestDir string
+	// PathPrefix is the path prefix of the storage (e.g. bucket prefix). It is stripped from the keys to compute the
+	// local paths, reversing core.ComputeDestinationBucketPath.
+	PathPrefix string
+	// Prefix selects the objects under the path prefix whose key starts with it (e.g. "record0.0.3/").
+	Prefix string
+	// Since selects the objects modified at or after the time.
+	Since time.Time
+	// Until selects the objects modified before the time.
+	Until time.Time
+	// Concurrency is the number of parallel downloads. Default is DefaultConcurrency.
+	Concurrency int
+	// Overwrite replaces local files that exist with a different checksum.
+	Overwrite bool
+}
+
+// File describes the outcome of restoring a single object.
+type File struct {
+	Key      string `json:"key"`
+	Dest     string `json:"dest"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report is the result of restoring objects from a storage.
+type Report struct {
+	Storage  string `json:"storage"`
+	Handler  string `json:"handler"`
+	Listed   int    `json:"listed"`
+	Restored []File `json:"restored,omitempty"`
+	Skipped  []File `json:"skipped,omitempty"`
+	Failed   []File `json:"failed,omitempty"`
+}
+
+// Run downloads the selected objects from the storage into the destination directory using parallel downloads.
+// Objects that already exist locally with the same checksum are skipped. Failures of individual objects are reported
+// in the result; an error is returned only if the objects cannot be listed.
+func Run(ctx context.Context, s core.Storage, opts Options) (*Report, error) {
+	if opts.DestDir == "" {
+		return nil, fmt.Errorf("missing destination directory")
+	}
+
+	if !s.Supports(core.CapabilityList) || !s.Supports(core.CapabilityGet) {
+		return nil, fmt.Errorf("%w: %s cannot list or download objects", core.ErrNotSupported, s.Type())
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	objects, err := core.ListAll(ctx, s, core.ComputeListPrefix(opts.PathPrefix, opts.Prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Storage: s.Type(), Handler: s.Info(), Listed: len(objects)}
+
+	work := make(chan core.ObjectInfo)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range work {
+				f, skipped, err := restoreObject(ctx, s, obj, opts)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					f.Error = err.Error()
+					report.Failed = append(report.Failed, f)
+				case skipped:
+					report.Skipped = append(report.Skipped, f)
+				default:
+					report.Restored = append(report.Restored, f)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, obj := range objects {
+		if !opts.Since.IsZero() && obj.LastModified.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && !obj.LastModified.Before(opts.Until) {
+			continue
+		}
+
+		select {
+		case work <- obj:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	for _, files := range [][]File{report.Restored, report.Skipped, report.Failed} {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Key < files[j].Key
+		})
+	}
+
+	logx.As().Info().
+		Str("storage_type", report.Storage).
+		Str("id", report.Handler).
+		Str("dest_dir", opts.DestDir).
+		Int("listed", report.Listed).
+		Int("restored", len(report.Restored)).
+		Int("skipped", len(report.Skipped)).
+		Int("failed", len(report.Failed)).
+		Msg("Restore completed")
+
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+
+	return report, nil
+}
+
+// restoreObject downloads a single object to its local path. It returns true if the object was skipped because the
+// local file already has the same checksum.
+func restoreObject(ctx context.Context, s core.Storage, obj core.ObjectInfo, opts Options) (File, bool, error) {
+	f := File{Key: obj.Key, Size: obj.Size, Checksum: obj.Checksum}
+
+	dest, err := core.ComputeSourcePath(opts.DestDir, obj.Key, opts.PathPrefix)
+	if err != nil {
+		return f, false, err
+	}
+	f.Dest = dest
+
+	verifiable := obj.ChecksumType == "md5" && obj.Checksum != "" && !strings.Contains(obj.Checksum, "-")
+	if _, exists := fsx.PathExists(dest); exists {
+		if verifiable {
+			checksum, err := fsx.FileMD5(dest)
+			if err != nil {
+				return f, false, fmt.Errorf("failed to calculate checksum of %s: %w", dest, err)
+			}
+
+			if checksum == obj.Checksum {
+				return f, true, nil
+			}
+		}
+
+		if !opts.Overwrite {
+			return f, false, fmt.Errorf("local file %s exists with a different content, use overwrite to replace it", dest)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return f, false, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	partial := dest + partialSuffix
+	if _, err := s.Get(ctx, obj.Key, partial); err != nil {
+		fsx.RemoveFile(partial)
+		return f, false, err
+	}
+
+	if verifiable {
+		checksum, err := fsx.FileMD5(partial)
+		if err != nil {
+			fsx.RemoveFile(partial)
+			return f, false, fmt.Errorf("failed to calculate checksum of %s: %w", partial, err)
+		}
+
+		if checksum != obj.Checksum {
+			fsx.RemoveFile(partial)
+			return f, false, fmt.Errorf("checksum mismatch after download: expected %s, got %s", obj.Checksum, checksum)
+		}
+	}
+
+	if err := os.Rename(partial, dest); err != nil {
+		fsx.RemoveFile(partial)
+		return f, false, fmt.Errorf("failed to move downloaded file to %s: %w", dest, err)
+	}
+
+	// keep the modification time of the object, which tools such as the journal and verify rely on
+	if !obj.LastModified.IsZero() {
+		_ = os.Chtimes(dest, obj.LastModified, obj.LastModified)
+	}
+
+	logx.As().Debug().
+		Str("storage_type", s.Type()).
+		Str("key", obj.Key).
+		Str("dest", dest).
+		Str("checksum", obj.Checksum).
+		Msg("Restored object")
+
+	return f, false, nil
+}
@@ -0,0 +1,99 @@
+package restore
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, content string, modTime time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func keys(files []File) []string {
+	var result []string
+	for _, f := range files {
+		result = append(result, f.Key)
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	remoteDir := t.TempDir()
+	destDir := t.TempDir()
+
+	now := time.Now().Truncate(time.Second)
+	writeFile(t, filepath.Join(remoteDir, "rec", "a.rcd"), "data-a", now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(remoteDir, "rec", "b.rcd"), "data-b", now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(remoteDir, "rec", "c.rcd"), "data-c", now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(remoteDir, "rec", "old.rcd"), "data-old", now.Add(-48*time.Hour))
+	writeFile(t, filepath.Join(remoteDir, "evt", "e.evts"), "data-e", now.Add(-2*time.Hour))
+
+	// a.rcd is already restored, b.rcd exists with a different content
+	writeFile(t, filepath.Join(destDir, "rec", "a.rcd"), "data-a", now)
+	writeFile(t, filepath.Join(destDir, "rec", "b.rcd"), "stale", now)
+
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: remoteDir, Mode: 0755}, config.RetryConfig{Limit: 1}, "/unused")
+	require.NoError(t, err)
+
+	t.Run("without overwrite", func(t *testing.T) {
+		report, err := Run(context.Background(), dir, Options{
+			DestDir:     destDir,
+			Prefix:      "rec/",
+			Since:       now.Add(-24 * time.Hour),
+			Concurrency: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, storage.TypeLocalDir, report.Storage)
+		require.Equal(t, 4, report.Listed)
+		require.Equal(t, []string{"rec/c.rcd"}, keys(report.Restored))
+		require.Equal(t, []string{"rec/a.rcd"}, keys(report.Skipped))
+		require.Equal(t, []string{"rec/b.rcd"}, keys(report.Failed))
+		require.Contains(t, report.Failed[0].Error, "exists with a different content")
+
+		content, err := os.ReadFile(filepath.Join(destDir, "rec", "c.rcd"))
+		require.NoError(t, err)
+		require.Equal(t, "data-c", string(content))
+
+		info, err := os.Stat(filepath.Join(destDir, "rec", "c.rcd"))
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(now.Add(-2*time.Hour)))
+
+		_, err = os.Stat(filepath.Join(destDir, "rec", "old.rcd"))
+		require.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(destDir, "evt", "e.evts"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("with overwrite", func(t *testing.T) {
+		report, err := Run(context.Background(), dir, Options{DestDir: destDir, Overwrite: true})
+		require.NoError(t, err)
+		require.Equal(t, 5, report.Listed)
+		require.Equal(t, []string{"evt/e.evts", "rec/b.rcd", "rec/old.rcd"}, keys(report.Restored))
+		require.Equal(t, []string{"rec/a.rcd", "rec/c.rcd"}, keys(report.Skipped))
+		require.Empty(t, report.Failed)
+
+		content, err := os.ReadFile(filepath.Join(destDir, "rec", "b.rcd"))
+		require.NoError(t, err)
+		require.Equal(t, "data-b", string(content))
+
+		matches, err := filepath.Glob(filepath.Join(destDir, "rec", "*"+partialSuffix))
+		require.NoError(t, err)
+		require.Empty(t, matches)
+	})
+}
+
+func TestRun_Validation(t *testing.T) {
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: t.TempDir(), Mode: 0755}, config.RetryConfig{Limit: 1}, "/unused")
+	require.NoError(t, err)
+
+	_, err = Run(context.Background(), dir, Options{})
+	require.ErrorContains(t, err, "missing destination directory")
+}
@@ -0,0 +1,227 @@
+package scanner
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"time"
+)
+
+// Orderings of the marker files queued for processing.
+const (
+	OrderingNone   = ""       // scan order
+	OrderingOldest = "oldest" // oldest marker first
+	OrderingNewest = "newest" // newest marker first
+	OrderingSize   = "size"   // smallest marker first
+)
+
+// DefaultQueueSize is the default maximum number of marker files held by the priority queue.
+const DefaultQueueSize = 1000
+
+// Sources of the time of a marker file.
+const (
+	TimeSourceFileName = "filename"
+	TimeSourceModTime  = "mtime"
+)
+
+// MarkerTime returns the time of a marker file: the Hedera consensus timestamp parsed from the file name if present,
+// otherwise the modification time of the file.
+func MarkerTime(path string, info os.FileInfo) (time.Time, string) {
+	if t, err := hedera.ParseConsensusTimestamp(path); err == nil {
+		return t, TimeSourceFileName
+	}
+
+	return info.ModTime(), TimeSourceModTime
+}
+
+// queuedItem is a marker file held by the priority queue with the values it is ordered by.
+type queuedItem struct {
+	result core.ScannerResult
+	time   time.Time
+	size   int64
+}
+
+// itemHeap implements heap.Interface for the queued marker files.
+type itemHeap struct {
+	items []queuedItem
+	less  func(a, b queuedItem) bool
+}
+
+func (h *itemHeap) Len() int           { return len(h.items) }
+func (h *itemHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *itemHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *itemHeap) Push(x any)         { h.items = append(h.items, x.(queuedItem)) }
+func (h *itemHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// orderedScanner queues the marker files found by a scanner in a bounded priority queue, so that processors receive
+// them in the configured order rather than in the lexical order of the walk.
+type orderedScanner struct {
+	core.Scanner
+	pipeline  string
+	queueSize int
+	less      func(a, b queuedItem) bool
+}
+
+// Scan starts the underlying scan and streams the marker files in priority order.
+//
+// Behavior:
+//   - The marker files ready to be read from the scan are queued before the highest priority one is sent, so that the
+//     backlog found while the processors are busy is processed in order.
+//   - Once the queue is full, the scan is paused until processors take a marker from the queue.
+//   - If the context is canceled, the queued markers are dropped; they are found again by the next scan.
+func (o *orderedScanner) Scan(ctx context.Context, ech chan<- error) <-chan core.ScannerResult {
+	in := o.Scanner.Scan(ctx, ech)
+	out := make(chan core.ScannerResult)
+
+	go func() {
+		defer close(out)
+
+		h := &itemHeap{less: o.less}
+		defer func() {
+			if h.Len() > 0 {
+				metrics.QueueDepth.WithLabelValues(o.pipeline).Sub(float64(h.Len()))
+			}
+		}()
+
+		for {
+			in = o.fill(h, in)
+
+			if h.Len() == 0 {
+				if in == nil {
+					return
+				}
+
+				select {
+				case r, ok := <-in:
+					if !ok {
+						in = nil
+						continue
+					}
+					heap.Push(h, o.item(r))
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			recv := in
+			if h.Len() >= o.queueSize {
+				recv = nil // the queue is full, wait for a processor
+			}
+
+			select {
+			case out <- h.items[0].result:
+				heap.Pop(h)
+			case r, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+				heap.Push(h, o.item(r))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// fill moves the marker files that are ready from the scan to the queue until it is full.
+// It returns nil once the scan is complete.
+func (o *orderedScanner) fill(h *itemHeap, in <-chan core.ScannerResult) <-chan core.ScannerResult {
+	for in != nil && h.Len() < o.queueSize {
+		select {
+		case r, ok := <-in:
+			if !ok {
+				return nil
+			}
+			heap.Push(h, o.item(r))
+		default:
+			return in
+		}
+	}
+
+	return in
+}
+
+func (o *orderedScanner) item(r core.ScannerResult) queuedItem {
+	item := queuedItem{result: r}
+	if r.Info != nil {
+		item.time, _ = MarkerTime(r.Path, r.Info)
+		item.size = r.Info.Size()
+	}
+	return item
+}
+
+// NewOrderedScanner returns a scanner that streams the marker files found by the scanner in the given order, using a
+// priority queue holding up to queueSize marker files. It returns the scanner unchanged if the ordering is empty.
+//
+// Parameters:
+//   - s: The scanner to order the results of.
+//   - pipeline: The name of the pipeline the scanner belongs to, used as metrics label.
+//   - ordering: One of OrderingNone, OrderingOldest, OrderingNewest or OrderingSize.
+//   - queueSize: The maximum number of queued marker files. Default is DefaultQueueSize.
+//
+// Notes:
+//   - Ties are broken by path so that the order is deterministic.
+func NewOrderedScanner(s core.Scanner, pipeline string, ordering string, queueSize int) (core.Scanner, error) {
+	byPath := func(a, b queuedItem) bool { return a.result.Path < b.result.Path }
+
+	var less func(a, b queuedItem) bool
+	switch ordering {
+	case OrderingNone:
+		return s, nil
+	case OrderingOldest:
+		less = func(a, b queuedItem) bool {
+			if !a.time.Equal(b.time) {
+				return a.time.Before(b.time)
+			}
+			return byPath(a, b)
+		}
+	case OrderingNewest:
+		less = func(a, b queuedItem) bool {
+			if !a.time.Equal(b.time) {
+				return a.time.After(b.time)
+			}
+			return byPath(a, b)
+		}
+	case OrderingSize:
+		less = func(a, b queuedItem) bool {
+			if a.size != b.size {
+				return a.size < b.size
+			}
+			return byPath(a, b)
+		}
+	default:
+		return nil, fmt.Errorf("invalid scanner ordering '%s', expected %s, %s or %s",
+			ordering, OrderingOldest, OrderingNewest, OrderingSize)
+	}
+
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	logx.As().Debug().
+		Str("pipeline", pipeline).
+		Str("scanner", s.Info()).
+		Str("ordering", ordering).
+		Int("queue_size", queueSize).
+		Msg("Scanner results are ordered by priority")
+
+	return &orderedScanner{
+		Scanner:   s,
+		pipeline:  pipeline,
+		queueSize: queueSize,
+		less:      less,
+	}, nil
+}
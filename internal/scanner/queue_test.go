@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// staticScanner returns a fixed list of scan results.
+type staticScanner struct {
+	results []core.ScannerResult
+}
+
+func (s *staticScanner) Info() string { return "static" }
+
+func (s *staticScanner) Scan(ctx context.Context, ech chan<- error) <-chan core.ScannerResult {
+	ch := make(chan core.ScannerResult, len(s.results))
+	for _, r := range s.results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
+
+func TestOrderedScanner(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	files := []struct {
+		name    string
+		content string
+		mtime   time.Time
+	}{
+		{name: "a.rcd_sig", content: "aaa", mtime: now},
+		{name: "b.rcd_sig", content: "b", mtime: now.Add(-2 * time.Hour)},
+		{name: "c.rcd_sig", content: "cc", mtime: now.Add(-time.Hour)},
+		{name: "2024-01-02T00_00_00.000000000Z.rcd_sig", content: "dddd", mtime: now},
+		{name: "2024-01-01T00_00_00.000000000Z.rcd_sig", content: "eeeee", mtime: now},
+	}
+
+	var results []core.ScannerResult
+	for _, f := range files {
+		path := filepath.Join(tempDir, f.name)
+		require.NoError(t, os.WriteFile(path, []byte(f.content), 0644))
+		require.NoError(t, os.Chtimes(path, f.mtime, f.mtime))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		results = append(results, core.ScannerResult{Path: path, Info: info})
+	}
+
+	tests := []struct {
+		name      string
+		ordering  string
+		queueSize int
+		expected  []string
+	}{
+		{
+			name:     "scan order",
+			ordering: OrderingNone,
+			expected: []string{"a.rcd_sig", "b.rcd_sig", "c.rcd_sig", "2024-01-02T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_00.000000000Z.rcd_sig"},
+		},
+		{
+			name:     "oldest",
+			ordering: OrderingOldest,
+			expected: []string{"2024-01-01T00_00_00.000000000Z.rcd_sig", "2024-01-02T00_00_00.000000000Z.rcd_sig", "b.rcd_sig", "c.rcd_sig", "a.rcd_sig"},
+		},
+		{
+			name:     "newest",
+			ordering: OrderingNewest,
+			expected: []string{"a.rcd_sig", "c.rcd_sig", "b.rcd_sig", "2024-01-02T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_00.000000000Z.rcd_sig"},
+		},
+		{
+			name:     "size",
+			ordering: OrderingSize,
+			expected: []string{"b.rcd_sig", "c.rcd_sig", "a.rcd_sig", "2024-01-02T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_00.000000000Z.rcd_sig"},
+		},
+		{
+			// the queue only reorders the markers it holds
+			name:      "bounded queue",
+			ordering:  OrderingOldest,
+			queueSize: 2,
+			expected:  []string{"b.rcd_sig", "c.rcd_sig", "2024-01-02T00_00_00.000000000Z.rcd_sig", "2024-01-01T00_00_00.000000000Z.rcd_sig", "a.rcd_sig"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewOrderedScanner(&staticScanner{results: results}, "test-pipeline", tt.ordering, tt.queueSize)
+			require.NoError(t, err)
+
+			errCh := make(chan error, 1)
+			var names []string
+			for r := range s.Scan(context.Background(), errCh) {
+				names = append(names, filepath.Base(r.Path))
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestOrderedScanner_Canceled(t *testing.T) {
+	results := make([]core.ScannerResult, 10)
+	for i := range results {
+		results[i] = core.ScannerResult{Path: filepath.Join("/test", string(rune('a'+i)))}
+	}
+
+	s, err := NewOrderedScanner(&staticScanner{results: results}, "test-pipeline", OrderingSize, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := s.Scan(ctx, make(chan error, 1))
+	<-ch
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		for ok {
+			_, ok = <-ch
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ordered scanner did not stop after the context was canceled")
+	}
+}
+
+func TestNewOrderedScanner_InvalidOrdering(t *testing.T) {
+	_, err := NewOrderedScanner(&staticScanner{}, "test-pipeline", "largest", 0)
+	assert.ErrorContains(t, err, "invalid scanner ordering")
+}
@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gobwas/glob"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Modes of finding the files ready for processing.
+const (
+	ModeMarker     = "marker"     // marker files matching the pattern
+	ModeQuiescence = "quiescence" // files matching the include patterns that stopped changing
+)
+
+// DefaultStablePeriod is the default period a file must be unchanged before it is ready in the quiescence mode.
+const DefaultStablePeriod = 30 * time.Second
+
+// QuiescenceOptions holds the settings of the quiescence mode.
+//
+// Fields:
+//   - Include: Glob patterns, relative to the scanner directory, of the files to queue (e.g., "**/*.log").
+//   - Exclude: Glob patterns, relative to the scanner directory, of the files to skip.
+//   - StablePeriod: How long the size and modification time of a file must be unchanged.
+//   - CheckOpen: Whether files that a process has open for writing are skipped.
+type QuiescenceOptions struct {
+	Include      []string
+	Exclude      []string
+	StablePeriod time.Duration
+	CheckOpen    bool
+}
+
+// observation is the state of a file when it was last scanned.
+type observation struct {
+	size    int64
+	modTime time.Time
+	changed time.Time // time the size or modification time was seen changing, zero if it was not
+}
+
+// quiescence decides which files are ready in the quiescence mode: files matching the include patterns whose size and
+// modification time have not changed for the stable period, and that no process has open for writing.
+//
+// The modification time of a file seen for the first time is trusted, so that files that were written before the
+// scanner started are ready on the first scan. A file whose size changes without a new modification time is ready
+// once the stable period has passed since the change was seen.
+//
+// It is not safe for concurrent scans, like the walker of the scanner.
+type quiescence struct {
+	include      []glob.Glob
+	exclude      []glob.Glob
+	stablePeriod time.Duration
+	checkOpen    bool
+
+	observed map[string]observation
+	seen     map[string]struct{} // files seen during the current scan
+	writers  map[fsx.FileID]struct{}
+
+	openWriters  func() (map[fsx.FileID]struct{}, error)
+	skipOpen     bool // the open files could not be listed during the current scan
+	writersError bool // the open files could not be listed during the last attempt
+}
+
+// begin starts a scan.
+func (q *quiescence) begin() {
+	q.seen = make(map[string]struct{})
+	q.writers = nil
+	q.skipOpen = false
+}
+
+// end finishes a scan and forgets the files that were not seen, e.g. because they were uploaded and removed.
+func (q *quiescence) end() {
+	for path := range q.observed {
+		if _, ok := q.seen[path]; !ok {
+			delete(q.observed, path)
+		}
+	}
+}
+
+// ready returns true if the file matches the include patterns and has stopped changing.
+func (q *quiescence) ready(rel string, path string, info os.FileInfo, now time.Time) bool {
+	if !matchGlobs(q.include, rel) || matchGlobs(q.exclude, rel) {
+		return false
+	}
+	q.seen[path] = struct{}{}
+
+	obs := observation{size: info.Size(), modTime: info.ModTime()}
+	if prev, ok := q.observed[path]; ok {
+		obs.changed = prev.changed
+		if prev.size != obs.size || !prev.modTime.Equal(obs.modTime) {
+			obs.changed = now
+		}
+	}
+	q.observed[path] = obs
+
+	stableSince := obs.modTime
+	if obs.changed.After(stableSince) {
+		stableSince = obs.changed
+	}
+	if now.Sub(stableSince) < q.stablePeriod {
+		logx.As().Trace().
+			Str("path", path).
+			Time("stable_since", stableSince).
+			Dur("stable_period", q.stablePeriod).
+			Msg("File is still changing")
+		return false
+	}
+
+	if q.checkOpen && q.openForWriting(info) {
+		logx.As().Debug().Str("path", path).Msg("File is open for writing, skipping")
+		return false
+	}
+
+	return true
+}
+
+// openForWriting returns true if a process has the file open for writing. The open files are listed once per scan.
+// If the system cannot list them, the check is disabled; if listing them fails, e.g. because /proc is briefly
+// unreadable, the check is skipped for the rest of the scan and retried on the next one. A failure is logged once until
+// listing succeeds again.
+func (q *quiescence) openForWriting(info os.FileInfo) bool {
+	if q.skipOpen {
+		return false
+	}
+
+	if q.writers == nil {
+		writers, err := q.openWriters()
+		if errors.Is(err, fsx.ErrWritersNotSupported) {
+			logx.As().Warn().Err(err).Msg("Files open for writing cannot be listed, relying on the stable period only")
+			q.checkOpen = false
+			return false
+		}
+		if err != nil {
+			if !q.writersError {
+				logx.As().Warn().Err(err).Msg("Failed to list files open for writing, skipping the check for this scan")
+			}
+			q.writersError = true
+			q.skipOpen = true
+			return false
+		}
+		if q.writersError {
+			logx.As().Info().Msg("Listing files open for writing succeeded again")
+		}
+		q.writersError = false
+		q.writers = writers
+	}
+
+	id, ok := fsx.FileIDOf(info)
+	if !ok {
+		return false
+	}
+	_, open := q.writers[id]
+	return open
+}
+
+// matchGlobs returns true if the relative path matches one of the globs. Paths are also matched with a leading
+// separator, so that **/ matches files at the top of the scanner directory too.
+func matchGlobs(globs []glob.Glob, rel string) bool {
+	for _, g := range globs {
+		if g.Match(rel) || g.Match("/"+rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlobs(patterns []string) ([]glob.Glob, error) {
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		g, err := glob.Compile(filepath.ToSlash(pattern), '/')
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile glob pattern '%s': %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// NewQuiescenceScanner creates a scanner that queues the files matching the include patterns once they are no longer
+// written, for directories without marker files. See NewScanner for the other parameters.
+func NewQuiescenceScanner(id string, pipeline string, rootDir string, opts QuiescenceOptions, batchSize int, filters ...Filter) (core.Scanner, error) {
+	if len(opts.Include) == 0 {
+		return nil, fmt.Errorf("quiescence mode requires include patterns")
+	}
+
+	include, err := compileGlobs(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	stablePeriod := opts.StablePeriod
+	if stablePeriod <= 0 {
+		stablePeriod = DefaultStablePeriod
+	}
+
+	return &scanner{
+		id:        id,
+		pipeline:  pipeline,
+		directory: rootDir,
+		walker:    fsx.NewWalker(batchSize),
+		filters:   filters,
+		quiescence: &quiescence{
+			include:      include,
+			exclude:      exclude,
+			stablePeriod: stablePeriod,
+			checkOpen:    opts.CheckOpen,
+			observed:     make(map[string]observation),
+			openWriters:  fsx.OpenWriters,
+		},
+	}, nil
+}
@@ -0,0 +1,183 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file and sets its modification time to the given age.
+func writeAged(t *testing.T, dir string, name string, age time.Duration) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	return path
+}
+
+// scanAll runs a scan and returns the paths of the queued files.
+func scanAll(t *testing.T, s core.Scanner) []string {
+	errCh := make(chan error, 1)
+	var paths []string
+	for result := range s.Scan(context.Background(), errCh) {
+		paths = append(paths, result.Path)
+	}
+	require.Empty(t, errCh)
+	return paths
+}
+
+func TestQuiescenceScanner_Scan(t *testing.T) {
+	tempDir := t.TempDir()
+	stable := writeAged(t, tempDir, "app.log", time.Minute)
+	nested := writeAged(t, tempDir, "node1/state/round-1.bin", time.Minute)
+	writeAged(t, tempDir, "node1/current.log", time.Second) // still being written
+	writeAged(t, tempDir, "node1/debug.tmp", time.Minute)   // excluded
+	writeAged(t, tempDir, "node1/readme.md", time.Minute)   // not included
+
+	s, err := NewQuiescenceScanner("test-scanner", "test-pipeline", tempDir, QuiescenceOptions{
+		Include:      []string{"**/*.log", "**/state/*.bin", "**/*.tmp"},
+		Exclude:      []string{"**/*.tmp"},
+		StablePeriod: 30 * time.Second,
+		CheckOpen:    true,
+	}, 10)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{stable, nested}, scanAll(t, s))
+}
+
+func TestQuiescenceScanner_OpenForWriting(t *testing.T) {
+	if _, err := fsx.OpenWriters(); err != nil {
+		t.Skip("files open for writing cannot be listed on this system")
+	}
+
+	tempDir := t.TempDir()
+	path := writeAged(t, tempDir, "app.log", time.Minute)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	modTime := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	opts := QuiescenceOptions{Include: []string{"*.log"}, StablePeriod: time.Second, CheckOpen: true}
+	s, err := NewQuiescenceScanner("test-scanner", "test-pipeline", tempDir, opts, 10)
+	require.NoError(t, err)
+	assert.Empty(t, scanAll(t, s), "the file is open for writing")
+
+	require.NoError(t, f.Close())
+	assert.Equal(t, []string{path}, scanAll(t, s))
+
+	// without the check, the file is ready while open
+	opts.CheckOpen = false
+	s, err = NewQuiescenceScanner("test-scanner", "test-pipeline", tempDir, opts, 10)
+	require.NoError(t, err)
+	f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{path}, scanAll(t, s))
+}
+
+func TestQuiescence_Ready(t *testing.T) {
+	tempDir := t.TempDir()
+	path := writeAged(t, tempDir, "app.log", time.Hour)
+	include, err := compileGlobs([]string{"*.log"})
+	require.NoError(t, err)
+
+	q := &quiescence{include: include, stablePeriod: 10 * time.Second, observed: make(map[string]observation)}
+	now := time.Now()
+	info := func() os.FileInfo {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info
+	}
+
+	// an old file is ready on the first scan
+	q.begin()
+	assert.True(t, q.ready("app.log", path, info(), now))
+	q.end()
+
+	// a size change without a new modification time restarts the stable period
+	require.NoError(t, os.WriteFile(path, []byte("appended"), 0644))
+	old := now.Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+	q.begin()
+	assert.False(t, q.ready("app.log", path, info(), now))
+	q.end()
+
+	q.begin()
+	assert.False(t, q.ready("app.log", path, info(), now.Add(5*time.Second)))
+	q.end()
+
+	q.begin()
+	assert.True(t, q.ready("app.log", path, info(), now.Add(10*time.Second)))
+	q.end()
+
+	// files not seen during a scan are forgotten
+	q.begin()
+	q.end()
+	assert.Empty(t, q.observed)
+}
+
+func TestQuiescence_OpenWritersFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	path := writeAged(t, tempDir, "app.log", time.Hour)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	include, err := compileGlobs([]string{"*.log"})
+	require.NoError(t, err)
+
+	calls := 0
+	listErr := errors.New("proc is unreadable")
+	q := &quiescence{include: include, checkOpen: true, observed: make(map[string]observation),
+		openWriters: func() (map[fsx.FileID]struct{}, error) {
+			calls++
+			return map[fsx.FileID]struct{}{}, listErr
+		},
+	}
+	scan := func() bool {
+		q.begin()
+		defer q.end()
+		ready := q.ready("app.log", path, info, time.Now())
+		assert.True(t, q.ready("app.log", path, info, time.Now()))
+		return ready
+	}
+
+	// a failed listing skips the check for the scan only, and is retried once per scan
+	assert.True(t, scan())
+	assert.Equal(t, 1, calls)
+	assert.True(t, q.checkOpen)
+	assert.True(t, scan())
+	assert.Equal(t, 2, calls)
+
+	listErr = nil
+	assert.True(t, scan())
+	assert.Equal(t, 3, calls)
+	assert.False(t, q.writersError)
+
+	// systems that cannot list open files disable the check
+	listErr = fsx.ErrWritersNotSupported
+	assert.True(t, scan())
+	assert.False(t, q.checkOpen)
+	assert.True(t, scan())
+	assert.Equal(t, 4, calls)
+}
+
+func TestNewQuiescenceScanner(t *testing.T) {
+	_, err := NewQuiescenceScanner("test-scanner", "test-pipeline", "/test/dir", QuiescenceOptions{}, 10)
+	assert.ErrorContains(t, err, "requires include patterns")
+
+	_, err = NewQuiescenceScanner("test-scanner", "test-pipeline", "/test/dir", QuiescenceOptions{Include: []string{"[.log"}}, 10)
+	assert.ErrorContains(t, err, "failed to compile glob pattern")
+
+	s, err := NewQuiescenceScanner("test-scanner", "test-pipeline", "/test/dir", QuiescenceOptions{Include: []string{"*.log"}}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStablePeriod, s.(*scanner).quiescence.stablePeriod)
+}
@@ -4,18 +4,32 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+// Filter decides whether a marker file found by the scanner is queued for processing.
+// It returns false to skip the marker; skipped markers are left untouched on disk.
+type Filter func(path string, info os.FileInfo) bool
+
 type scanner struct {
 	id        string
+	pipeline  string // name of the pipeline the scanner belongs to, used as metrics label
 	directory string
-	pattern   string
+	markers   matcher.MarkerPatterns
 	walker    *fsx.Walker
+	filters   []Filter
+	// quiescence, if set, queues the files that stopped changing instead of the marker files matching the patterns
+	quiescence *quiescence
 }
 
 // Info returns a unique identifier for the scanner or processor instance.
@@ -29,7 +43,7 @@ func (s *scanner) Info() string {
 	return s.id
 }
 
-// Scan traverses the specified directory to find files matching the configured pattern.
+// Scan traverses the specified directory to find files matching the configured patterns.
 // It streams the results of the scan through a channel and sends any errors encountered to the provided error channel.
 //
 // Parameters:
@@ -37,11 +51,12 @@ func (s *scanner) Info() string {
 //   - ech: A channel to which errors encountered during the scan are sent.
 //
 // Returns:
-//   - A channel of ScannerResult, which streams the details of the files that match the configured pattern.
+//   - A channel of ScannerResult, which streams the details of the files that match the configured patterns.
 //
 // Behavior:
 //   - The method uses `filepath.Walk` to recursively traverse the directory tree.
-//   - Files that are not regular or do not match the specified pattern are ignored.
+//   - Files that are not regular or do not match any of the marker patterns are ignored; all the marker patterns are
+//     evaluated in the same walk, the first matching pattern is reported in the result.
 //   - If a file is deleted during the scan, the error is logged and ignored.
 //   - If the context is canceled, the scan stops immediately.
 //
@@ -52,9 +67,24 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 	counter := 0
 	items := make(chan core.ScannerResult)
 	go func() {
+		var err error
+		start := time.Now()
+		scanCtx, span := tracing.StartSpan(ctx, "scanner.scan",
+			tracing.AttrPipeline.String(s.pipeline),
+			attribute.String("scanner", s.Info()),
+			attribute.String("directory", s.directory))
+		defer func() {
+			metrics.ScanDuration.WithLabelValues(s.pipeline).Observe(time.Since(start).Seconds())
+			span.SetAttributes(attribute.Int("markers", counter))
+			tracing.EndSpan(span, err)
+		}()
 		defer s.walker.End()
 		defer close(items)
-		err := s.walker.Start(s.directory, func(path string, info os.FileInfo, err error) error {
+		if s.quiescence != nil {
+			s.quiescence.begin()
+			defer s.quiescence.end()
+		}
+		err = s.walker.Start(s.directory, func(path string, info os.FileInfo, err error) error {
 			logx.As().Trace().Str("path", path).Msg("scanning path")
 
 			if err != nil {
@@ -74,16 +104,33 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 				return err
 			}
 
-			ext := filepath.Ext(path)
-			if !info.Mode().IsRegular() || ext != s.pattern {
+			if !info.Mode().IsRegular() {
+				logx.As().Trace().
+					Str("path", path).
+					Str("mode", info.Mode().String()).
+					Msg("skipping path")
+				return nil // ignore non-regular files
+			}
+
+			marker, ok := s.ready(path, info)
+			if !ok {
 				logx.As().Trace().
 					Str("path", path).
-					Str("ext", ext).
-					Str("marker_pattern", s.pattern).
+					Strs("marker_patterns", s.markers.Patterns()).
 					Str("mode", info.Mode().String()).
 					Bool("is_regular", info.Mode().IsRegular()).
 					Msg("skipping path")
-				return nil // ignore non-regular files and non-matching extensions
+				return nil // ignore files not matching any marker pattern
+			}
+
+			for _, filter := range s.filters {
+				if !filter(path, info) {
+					logx.As().Debug().
+						Str("path", path).
+						Str("scanner", s.Info()).
+						Msg("Scanner filtered out marker file")
+					return nil
+				}
 			}
 
 			counter++
@@ -93,18 +140,29 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 				Str("scanner", s.Info()).
 				Str("trace_id", traceId).
 				Str("ext", filepath.Ext(path)).
-				Str("pattern", s.pattern).
+				Str("pattern", marker).
 				Int64("size", info.Size()).
 				Msg("Scanner found marker file")
+			metrics.MarkersDiscovered.WithLabelValues(s.pipeline).Inc()
 
+			// record the discovery within the trace of the marker, linked to the scan it was found in
+			_, discoverSpan := tracing.StartMarkerSpan(ctx, "scanner.discover", traceId,
+				tracing.AttrPipeline.String(s.pipeline),
+				tracing.AttrMarker.String(path),
+				attribute.Int64("size", info.Size()))
+			discoverSpan.AddLink(trace.LinkFromContext(scanCtx))
+			discoverSpan.End()
+
+			metrics.QueueDepth.WithLabelValues(s.pipeline).Inc()
 			select {
-			case items <- core.ScannerResult{Path: path, Info: info, TraceId: traceId}:
+			case items <- core.ScannerResult{Path: path, Info: info, TraceId: traceId, Marker: marker}:
 				logx.As().Trace().
 					Str("marker", path).
 					Str("trace_id", traceId).
 					Str("scanner", s.Info()).
 					Msg("Scanner added marker file to the queue")
 			case <-ctx.Done():
+				metrics.QueueDepth.WithLabelValues(s.pipeline).Dec()
 				return nil
 			}
 
@@ -112,6 +170,7 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 		})
 
 		if err != nil {
+			metrics.ErrorsTotal.WithLabelValues(s.pipeline, metrics.ErrorClassScan).Inc()
 			logx.As().Err(err).
 				Str("directory", s.directory).
 				Str("scanner", s.Info()).
@@ -126,13 +185,33 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 	return items
 }
 
+// ready returns the marker pattern of the regular file and true if it is to be queued: a marker file matching one of
+// the marker patterns, or in the quiescence mode a file that stopped changing.
+func (s *scanner) ready(path string, info os.FileInfo) (string, bool) {
+	rel, err := filepath.Rel(s.directory, path)
+	if err != nil {
+		return "", false
+	}
+
+	if s.quiescence == nil {
+		if m := s.markers.Match(rel); m != nil {
+			return m.Pattern, true
+		}
+		return "", false
+	}
+
+	return "", s.quiescence.ready(filepath.ToSlash(rel), path, info, time.Now())
+}
+
 // NewScanner creates and initializes a new scanner instance.
 //
 // Parameters:
 //   - id: A unique identifier for the scanner instance.
+//   - pipeline: The name of the pipeline the scanner belongs to.
 //   - directory: The root directory directory to scan.
 //   - pattern: The file extension pattern to match (e.g., ".txt").
 //   - batchSize: The maximum number of directory entries to read at once.
+//   - filters: Optional filters that a marker file must pass to be queued (e.g. a time range for backfills).
 //
 // Returns:
 //   - A Scanner instance configured with the provided parameters.
@@ -141,20 +220,39 @@ func (s *scanner) Scan(ctx context.Context, ech chan<- error) <-chan core.Scanne
 // Notes:
 //   - The scanner uses a Walker to traverse the directory tree.
 //   - The batchSize parameter controls how many directory entries are read in a single operation.
-func NewScanner(id string, rootDir string, pattern string, batchSize int) (core.Scanner, error) {
-	return newScanner(id, rootDir, pattern, batchSize)
+func NewScanner(id string, pipeline string, rootDir string, pattern string, batchSize int, filters ...Filter) (core.Scanner, error) {
+	return newScanner(id, pipeline, rootDir, pattern, batchSize, filters...)
+}
+
+// NewMarkerScanner creates a scanner that queues the marker files matching any of the marker patterns in a single walk
+// of the directory. See NewScanner for the other parameters.
+func NewMarkerScanner(id string, pipeline string, rootDir string, markers matcher.MarkerPatterns, batchSize int, filters ...Filter) (core.Scanner, error) {
+	if len(markers) == 0 {
+		return nil, fmt.Errorf("no marker pattern configured")
+	}
+
+	return &scanner{
+		id:        id,
+		pipeline:  pipeline,
+		directory: rootDir,
+		markers:   markers,
+		walker:    fsx.NewWalker(batchSize),
+		filters:   filters,
+	}, nil
 }
 
-func newScanner(id string, rootDir string, pattern string, batchSize int) (*scanner, error) {
-	// if pattern contains '*' or '?', it is not a supported pattern. We only allow extension like .rcd_sig
-	if !core.IsFileExtension(pattern) {
-		return nil, fmt.Errorf("invalid file extension '%s'. use file extension without * or regex characters; i.e. '.rcd.gz'", pattern)
+func newScanner(id string, pipeline string, rootDir string, pattern string, batchSize int, filters ...Filter) (*scanner, error) {
+	markers, err := matcher.NewMarkerPatterns(pattern, nil, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	return &scanner{
 		id:        id,
+		pipeline:  pipeline,
 		directory: rootDir,
-		pattern:   pattern,
+		markers:   markers,
 		walker:    fsx.NewWalker(batchSize),
+		filters:   filters,
 	}, nil
 }
@@ -3,6 +3,8 @@ package scanner
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,12 +12,12 @@ import (
 
 func TestNewScanner(t *testing.T) {
 	// Test valid scanner creation
-	s, err := newScanner("test-scanner", "/test/dir", ".txt", 10)
+	s, err := newScanner("test-scanner", "test-pipeline", "/test/dir", ".txt", 10)
 	assert.NoError(t, err)
 	assert.NotNil(t, s)
 
 	// Test invalid scanner creation with unsupported pattern
-	s, err = newScanner("test-scanner", "/test/dir", "*.txt", 10)
+	s, err = newScanner("test-scanner", "test-pipeline", "/test/dir", "*.txt", 10)
 	assert.Error(t, err)
 	assert.Nil(t, s)
 }
@@ -30,7 +32,7 @@ func TestScan(t *testing.T) {
 	}
 
 	// Initialize the scanner
-	s, err := newScanner("test-scanner", tempDir, ".txt", 3)
+	s, err := newScanner("test-scanner", "test-pipeline", tempDir, ".txt", 3)
 	assert.NoError(t, err)
 
 	// Create a context and error channel
@@ -55,12 +57,88 @@ func TestScan(t *testing.T) {
 	assert.ElementsMatch(t, expectedFiles, scannedFiles)
 }
 
+func TestScan_MarkerPatterns(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"record/a.rcd_sig":       ".rcd_sig",
+		"record/a.rcd.gz":        "",
+		"events/b.evts.gz":       ".evts.gz",
+		"events/b.gz":            "",
+		"balance/c_sig.json":     "balance/*_sig.json",
+		"balance/sub/c_sig.json": "",
+	}
+	for file := range files {
+		path := filepath.Join(tempDir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("test content"), 0644))
+	}
+
+	markers, err := matcher.NewMarkerPatterns(".rcd_sig", []config.MarkerConfig{
+		{Pattern: ".evts.gz"},
+		{Type: matcher.MarkerGlob, Pattern: "balance/*_sig.json"},
+	}, nil)
+	assert.NoError(t, err)
+
+	s, err := NewMarkerScanner("test-scanner", "test-pipeline", tempDir, markers, 3)
+	assert.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	defer close(errCh)
+
+	// all the marker patterns are matched in the same walk
+	scanned := map[string]string{}
+	for result := range s.Scan(context.Background(), errCh) {
+		rel, err := filepath.Rel(tempDir, result.Path)
+		assert.NoError(t, err)
+		scanned[filepath.ToSlash(rel)] = result.Marker
+	}
+
+	expected := map[string]string{}
+	for file, marker := range files {
+		if marker != "" {
+			expected[file] = marker
+		}
+	}
+	assert.Equal(t, expected, scanned)
+
+	_, err = NewMarkerScanner("test-scanner", "test-pipeline", tempDir, nil, 3)
+	assert.Error(t, err)
+}
+
+func TestScan_Filters(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"keep1.txt", "skip.txt", "keep2.txt"} {
+		err := os.WriteFile(filepath.Join(tempDir, file), []byte("test content"), 0644)
+		assert.NoError(t, err)
+	}
+
+	var filtered []string
+	filter := func(path string, info os.FileInfo) bool {
+		filtered = append(filtered, filepath.Base(path))
+		return filepath.Base(path) != "skip.txt"
+	}
+
+	s, err := newScanner("test-scanner", "test-pipeline", tempDir, ".txt", 3, filter)
+	assert.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	defer close(errCh)
+
+	var scannedFiles []string
+	for result := range s.Scan(context.Background(), errCh) {
+		scannedFiles = append(scannedFiles, filepath.Base(result.Path))
+	}
+
+	assert.ElementsMatch(t, []string{"keep1.txt", "keep2.txt"}, scannedFiles)
+	assert.ElementsMatch(t, []string{"keep1.txt", "skip.txt", "keep2.txt"}, filtered)
+}
+
 func TestScan_EmptyDirectory(t *testing.T) {
 	// Setup: Create an empty temporary directory
 	tempDir := t.TempDir()
 
 	// Initialize the scanner
-	s, err := NewScanner("test-scanner", tempDir, ".txt", 3)
+	s, err := NewScanner("test-scanner", "test-pipeline", tempDir, ".txt", 3)
 	assert.NoError(t, err)
 
 	// Create a context and error channel
@@ -81,7 +159,7 @@ func TestScan_EmptyDirectory(t *testing.T) {
 
 func TestScan_InvalidDirectory(t *testing.T) {
 	// Initialize the scanner with a non-existent directory
-	s, err := NewScanner("test-scanner", "/invalid/path", ".txt", 3)
+	s, err := NewScanner("test-scanner", "test-pipeline", "/invalid/path", ".txt", 3)
 	assert.NoError(t, err)
 
 	// Create a context and error channel
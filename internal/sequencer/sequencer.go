@@ -0,0 +1,211 @@
+package sequencer
+
+import (
+	"context"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultHeadOfLineTimeout is the default time a marker waits for older markers of its directory to be published.
+const DefaultHeadOfLineTimeout = 5 * time.Minute
+
+// entry is a marker file in flight.
+type entry struct {
+	time time.Time
+	refs int // number of times the marker is in flight, e.g. found again by a scan while being processed
+}
+
+// Sequencer publishes the marker files of a pipeline in timestamp order per directory.
+//
+// Marker files are tracked from the moment the scanner emits them until the processor is done with them. A processor
+// uploads the data files of a marker in parallel with other processors, then calls Wait before publishing the marker
+// file itself, so that a marker is never published while an older marker of the same directory is still in flight.
+//
+// Notes:
+//   - The time of a marker is the consensus timestamp in its file name, or its modification time (see scanner.MarkerTime).
+//   - Failed markers are released like published ones, so that a single failing marker does not stall the pipeline;
+//     they are published out of order once they are retried.
+//   - A marker waiting longer than the head-of-line timeout is published anyway, and the stall is reported.
+type Sequencer struct {
+	pipeline string
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]map[string]*entry // directory -> marker path -> entry
+	changed  chan struct{}                // closed and replaced whenever a marker is released
+}
+
+// Track returns a scanner that registers the marker files emitted by the scanner before they are sent to processors.
+func (s *Sequencer) Track(sc core.Scanner) core.Scanner {
+	return &trackingScanner{Scanner: sc, sequencer: s}
+}
+
+// Add registers a marker file as in flight.
+func (s *Sequencer) Add(marker core.ScannerResult) {
+	var t time.Time
+	if marker.Info != nil {
+		t, _ = scanner.MarkerTime(marker.Path, marker.Info)
+	}
+
+	dir := filepath.Dir(marker.Path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	markers, ok := s.inFlight[dir]
+	if !ok {
+		markers = make(map[string]*entry)
+		s.inFlight[dir] = markers
+	}
+
+	if e, ok := markers[marker.Path]; ok {
+		e.refs++
+		return
+	}
+
+	markers[marker.Path] = &entry{time: t, refs: 1}
+}
+
+// Done releases a marker file once it has been published or has failed, unblocking the newer markers of its directory.
+func (s *Sequencer) Done(path string) {
+	dir := filepath.Dir(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.inFlight[dir][path]
+	if !ok {
+		return
+	}
+
+	e.refs--
+	if e.refs > 0 {
+		return
+	}
+
+	delete(s.inFlight[dir], path)
+	if len(s.inFlight[dir]) == 0 {
+		delete(s.inFlight, dir)
+	}
+
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Wait blocks until no marker file older than the given one is in flight in the same directory.
+//
+// It returns nil once the marker can be published, including after the head-of-line timeout, and an error only if
+// the context is canceled. Markers that are not tracked are not ordered.
+func (s *Sequencer) Wait(ctx context.Context, path string) error {
+	started := time.Now()
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		blocker, blocked := s.oldest(path)
+		changed := s.changed
+		s.mu.Unlock()
+
+		if !blocked {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			metrics.CommitStalls.WithLabelValues(s.pipeline).Inc()
+			logx.As().Error().
+				Str("pipeline", s.pipeline).
+				Str("marker", path).
+				Str("blocked_by", blocker).
+				Dur("waited", time.Since(started)).
+				Msg("Older marker file is stuck, publishing marker file out of order")
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// oldest returns the oldest marker in flight in the directory of the marker if it is older than the marker.
+// Ties are broken by path. It must be called with the lock held.
+func (s *Sequencer) oldest(path string) (string, bool) {
+	markers := s.inFlight[filepath.Dir(path)]
+	own, ok := markers[path]
+	if !ok {
+		return "", false
+	}
+
+	oldest := ""
+	var oldestTime time.Time
+	for p, e := range markers {
+		if !before(p, e.time, path, own.time) {
+			continue
+		}
+
+		if oldest == "" || before(p, e.time, oldest, oldestTime) {
+			oldest, oldestTime = p, e.time
+		}
+	}
+
+	return oldest, oldest != ""
+}
+
+func before(pathA string, a time.Time, pathB string, b time.Time) bool {
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
+	return pathA < pathB
+}
+
+// trackingScanner registers the marker files emitted by a scanner with the sequencer.
+type trackingScanner struct {
+	core.Scanner
+	sequencer *Sequencer
+}
+
+// Scan registers each marker file before it is sent, so that it is known to be in flight before any newer marker of
+// its directory can be published.
+func (t *trackingScanner) Scan(ctx context.Context, ech chan<- error) <-chan core.ScannerResult {
+	in := t.Scanner.Scan(ctx, ech)
+	out := make(chan core.ScannerResult)
+
+	go func() {
+		defer close(out)
+		for r := range in {
+			t.sequencer.Add(r)
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				t.sequencer.Done(r.Path)
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// NewSequencer creates a sequencer for the marker files of the pipeline.
+//
+// Parameters:
+//   - pipeline: The name of the pipeline, used in logs and as metrics label.
+//   - timeout: The maximum time a marker waits for older markers. Default is DefaultHeadOfLineTimeout.
+func NewSequencer(pipeline string, timeout time.Duration) *Sequencer {
+	if timeout <= 0 {
+		timeout = DefaultHeadOfLineTimeout
+	}
+
+	return &Sequencer{
+		pipeline: pipeline,
+		timeout:  timeout,
+		inFlight: make(map[string]map[string]*entry),
+		changed:  make(chan struct{}),
+	}
+}
@@ -0,0 +1,154 @@
+package sequencer
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/internal/scanner"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// staticScanner returns a fixed list of scan results.
+type staticScanner struct {
+	results []core.ScannerResult
+}
+
+func (s *staticScanner) Info() string { return "static" }
+
+func (s *staticScanner) Scan(ctx context.Context, ech chan<- error) <-chan core.ScannerResult {
+	ch := make(chan core.ScannerResult, len(s.results))
+	for _, r := range s.results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
+
+func marker(t *testing.T, dir string, name string) core.ScannerResult {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(path, []byte("sig"), 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return core.ScannerResult{Path: path, Info: info}
+}
+
+func TestSequencer_Wait(t *testing.T) {
+	tempDir := t.TempDir()
+	older := marker(t, filepath.Join(tempDir, "node1"), "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	newer := marker(t, filepath.Join(tempDir, "node1"), "2024-01-01T00_00_02.000000000Z.rcd_sig")
+	other := marker(t, filepath.Join(tempDir, "node2"), "2024-01-01T00_00_04.000000000Z.rcd_sig")
+
+	s := NewSequencer("test-pipeline", time.Minute)
+	for _, m := range []core.ScannerResult{newer, older, other} {
+		s.Add(m)
+	}
+
+	// the oldest marker of a directory and markers of other directories are not blocked
+	require.NoError(t, s.Wait(context.Background(), older.Path))
+	require.NoError(t, s.Wait(context.Background(), other.Path))
+	require.NoError(t, s.Wait(context.Background(), "/untracked/marker.rcd_sig"))
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- s.Wait(context.Background(), newer.Path)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("newer marker was not blocked by the older marker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.Done(older.Path)
+	select {
+	case err := <-waited:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("newer marker was not released after the older marker was done")
+	}
+
+	// a marker found again while in flight is released once both are done
+	s.Add(newer)
+	s.Done(newer.Path)
+	_, blocked := s.oldest(newer.Path)
+	require.False(t, blocked)
+	require.Contains(t, s.inFlight[filepath.Dir(newer.Path)], newer.Path)
+	s.Done(newer.Path)
+	require.NotContains(t, s.inFlight, filepath.Dir(newer.Path))
+}
+
+func TestSequencer_WaitTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+	older := marker(t, tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	newer := marker(t, tempDir, "2024-01-01T00_00_02.000000000Z.rcd_sig")
+
+	s := NewSequencer("test-sequencer-timeout", 20*time.Millisecond)
+	s.Add(older)
+	s.Add(newer)
+
+	// the newer marker is published anyway after the head-of-line timeout
+	stalls := testutil.ToFloat64(metrics.CommitStalls.WithLabelValues("test-sequencer-timeout"))
+	require.NoError(t, s.Wait(context.Background(), newer.Path))
+	require.Equal(t, stalls+1, testutil.ToFloat64(metrics.CommitStalls.WithLabelValues("test-sequencer-timeout")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.timeout = time.Minute
+	require.ErrorIs(t, s.Wait(ctx, newer.Path), context.Canceled)
+}
+
+func TestSequencer_Track(t *testing.T) {
+	tempDir := t.TempDir()
+	results := []core.ScannerResult{
+		marker(t, tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig"),
+		marker(t, tempDir, "2024-01-01T00_00_02.000000000Z.rcd_sig"),
+	}
+
+	s := NewSequencer("test-pipeline", time.Minute)
+	var scanned []string
+	for r := range s.Track(&staticScanner{results: results}).Scan(context.Background(), make(chan error, 1)) {
+		scanned = append(scanned, r.Path)
+	}
+
+	require.Equal(t, []string{results[0].Path, results[1].Path}, scanned)
+	require.Len(t, s.inFlight[tempDir], 2)
+
+	blocker, blocked := s.oldest(results[1].Path)
+	require.True(t, blocked)
+	require.Equal(t, results[0].Path, blocker)
+}
+
+func TestSequencer_TrackBeforeQueue(t *testing.T) {
+	tempDir := t.TempDir()
+	older := marker(t, tempDir, "2024-01-01T00_00_00.000000000Z.rcd_sig")
+	newer := marker(t, tempDir, "2024-01-01T00_00_02.000000000Z.rcd_sig")
+
+	// the scan finds the newer marker first, the queue emits the older one first
+	s := NewSequencer("test-pipeline", time.Minute)
+	sc, err := scanner.NewOrderedScanner(s.Track(&staticScanner{results: []core.ScannerResult{newer, older}}),
+		"test-pipeline", scanner.OrderingOldest, 10)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := sc.Scan(ctx, make(chan error, 1))
+	first := <-results
+	require.Equal(t, older.Path, first.Path)
+
+	// markers still in the queue are tracked, so the emitted marker is not blocked by them and they wait for it
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.inFlight[tempDir]) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Wait(context.Background(), older.Path))
+	blocker, blocked := s.oldest(newer.Path)
+	require.True(t, blocked)
+	require.Equal(t, older.Path, blocker)
+}
@@ -0,0 +1,119 @@
+package signature
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Keys holds the RSA public keys of the nodes by node account ID (e.g. 0.0.3).
+type Keys map[string]*rsa.PublicKey
+
+// LoadPEMDir loads the public keys of the PEM files (.pem) of a directory.
+// The node account ID is taken from the file name, e.g. node0.0.3.pem; a file may hold a public key or a certificate.
+func LoadPEMDir(dir string) (Keys, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	keys := make(Keys)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+
+		node := hedera.ParseNodeAccountId(entry.Name())
+		if node == "" {
+			return nil, fmt.Errorf("no node account ID in key file name %s", entry.Name())
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+
+		key, err := parsePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", entry.Name(), err)
+		}
+
+		keys[node] = key
+	}
+
+	return keys, nil
+}
+
+func parsePEM(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return parsePublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("certificate key is not an RSA key")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+}
+
+func parsePublicKey(der []byte) (*rsa.PublicKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return key, nil
+}
+
+// LoadAddressBook loads the public keys of the nodes of an address book file, i.e. a serialized NodeAddressBook
+// protobuf message such as the content of file 0.0.102.
+func LoadAddressBook(file string) (Keys, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address book: %w", err)
+	}
+
+	addresses, err := hedera.ReadAddressBook(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address book %s: %w", file, err)
+	}
+
+	keys := make(Keys)
+	for _, address := range addresses {
+		if address.AccountId == "" || address.RSAPublicKey == nil {
+			continue // entries without account ID or key cannot verify signatures
+		}
+
+		key, err := parsePublicKey(address.RSAPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address book %s: invalid RSA public key of node %s: %w", file, address.AccountId, err)
+		}
+
+		keys[address.AccountId] = key
+	}
+
+	return keys, nil
+}
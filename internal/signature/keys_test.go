@@ -0,0 +1,111 @@
+package signature
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func writePublicKey(t *testing.T, dir string, name string, key *rsa.PrivateKey) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+}
+
+func writeCertificate(t *testing.T, dir string, name string, key *rsa.PrivateKey) {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "s-node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+}
+
+// nodeAddress encodes a NodeAddress message with the account ID in nodeAccountId, or in the memo if legacy is set.
+func nodeAddress(t *testing.T, shard, realm, num uint64, key *rsa.PrivateKey, legacy bool) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return hederatest.NodeAddress(shard, realm, num, der, legacy)
+}
+
+func writeAddressBook(t *testing.T, path string, addresses ...[]byte) {
+	require.NoError(t, os.WriteFile(path, hederatest.AddressBook(addresses...), 0644))
+}
+
+func TestLoadPEMDir(t *testing.T) {
+	key3 := generateKey(t)
+	key4 := generateKey(t)
+
+	dir := t.TempDir()
+	writePublicKey(t, dir, "node0.0.3.pem", key3)
+	writeCertificate(t, dir, "s-public-0.0.4.pem", key4)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0644))
+
+	keys, err := LoadPEMDir(dir)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, key3.PublicKey.Equal(keys["0.0.3"]))
+	require.True(t, key4.PublicKey.Equal(keys["0.0.4"]))
+
+	t.Run("file name without account ID", func(t *testing.T) {
+		dir := t.TempDir()
+		writePublicKey(t, dir, "node.pem", key3)
+		_, err := LoadPEMDir(dir)
+		require.ErrorContains(t, err, "no node account ID in key file name node.pem")
+	})
+
+	t.Run("invalid PEM", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "0.0.3.pem"), []byte("not a key"), 0644))
+		_, err := LoadPEMDir(dir)
+		require.ErrorContains(t, err, "no PEM block found")
+	})
+}
+
+func TestLoadAddressBook(t *testing.T) {
+	key3 := generateKey(t)
+	key4 := generateKey(t)
+
+	file := filepath.Join(t.TempDir(), "0.0.102")
+	writeAddressBook(t, file, nodeAddress(t, 0, 0, 3, key3, true), nodeAddress(t, 0, 0, 4, key4, false))
+
+	keys, err := LoadAddressBook(file)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, key3.PublicKey.Equal(keys["0.0.3"]))
+	require.True(t, key4.PublicKey.Equal(keys["0.0.4"]))
+
+	t.Run("invalid key encoding", func(t *testing.T) {
+		writeAddressBook(t, file, hederatest.NodeAddressRSAPubKey("zz"))
+
+		_, err := LoadAddressBook(file)
+		require.ErrorContains(t, err, "invalid RSA public key encoding")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		writeAddressBook(t, file, hederatest.NodeAddress(0, 0, 3, []byte("not a key"), false))
+
+		_, err := LoadAddressBook(file)
+		require.ErrorContains(t, err, "invalid RSA public key of node 0.0.3")
+	})
+}
@@ -0,0 +1,216 @@
+package signature
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// signatureSuffix is the suffix of signature files, e.g. .rcd_sig and .evts_sig.
+const signatureSuffix = "_sig"
+
+// recordFileSuffix is the suffix of uncompressed record stream files.
+const recordFileSuffix = ".rcd"
+
+// Verifier verifies the signature files of stream files against the public keys of the nodes.
+//
+// For a signature marker such as 2024-01-01T00_00_00.000000000Z.rcd_sig, it finds the signed stream file among the
+// candidate files (.rcd or .rcd.gz), recomputes the file hash of its uncompressed content (the SHA-384 hash of the
+// content, or of the header and the hash of the body for version 2 record files), checks it against the file hash of
+// the signature file and verifies the SHA384withRSA signature over the hash with the public key of the node. The node
+// account ID (e.g. 0.0.3) is taken from the directory of the marker, e.g. record0.0.3; if no ID is found and a single
+// key is loaded, that key is used.
+//
+// Notes:
+//   - Markers that are not signature files are not verified.
+//   - Only the file signature is verified; the metadata hash of version 5 and 6 signature files is not recomputed.
+type Verifier struct {
+	pipeline string
+	keys     Keys
+}
+
+// Verify verifies the signature file marker against the signed stream file among its candidate files.
+func (v *Verifier) Verify(marker string, candidates []string) error {
+	if !strings.HasSuffix(marker, signatureSuffix) {
+		return nil
+	}
+
+	if err := v.verify(marker, candidates); err != nil {
+		metrics.SignatureFailures.WithLabelValues(v.pipeline).Inc()
+		logx.As().Error().
+			Err(err).
+			Str("pipeline", v.pipeline).
+			Str("marker", marker).
+			Msg("Signature verification failed")
+		return err
+	}
+
+	logx.As().Debug().Str("pipeline", v.pipeline).Str("marker", marker).Msg("Signature verified")
+	return nil
+}
+
+func (v *Verifier) verify(marker string, candidates []string) error {
+	key, node, err := v.key(marker)
+	if err != nil {
+		return err
+	}
+
+	dataFile := signedFile(marker, candidates)
+	if dataFile == "" {
+		return fmt.Errorf("signed stream file of %s not found among the candidate files", marker)
+	}
+
+	sf, err := readSignatureFile(marker)
+	if err != nil {
+		return fmt.Errorf("failed to read signature file %s: %w", marker, err)
+	}
+
+	hash, err := fileHash(dataFile)
+	if err != nil {
+		return fmt.Errorf("failed to hash stream file %s: %w", dataFile, err)
+	}
+
+	if !bytes.Equal(hash, sf.FileHash) {
+		return fmt.Errorf("hash %s of stream file %s does not match the signed file hash %s",
+			hex.EncodeToString(hash), dataFile, hex.EncodeToString(sf.FileHash))
+	}
+
+	digest := sha512.Sum384(sf.FileHash)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA384, digest[:], sf.FileSignature); err != nil {
+		return fmt.Errorf("invalid signature of node %s over stream file %s: %w", node, dataFile, err)
+	}
+
+	return nil
+}
+
+// key returns the public key of the node that signed the marker.
+func (v *Verifier) key(marker string) (*rsa.PublicKey, string, error) {
+	node := hedera.ParseNodeAccountId(filepath.Dir(marker))
+	if node == "" {
+		if len(v.keys) == 1 {
+			for id, key := range v.keys {
+				return key, id, nil
+			}
+		}
+		return nil, "", fmt.Errorf("no node account ID in the directory of %s", marker)
+	}
+
+	key, ok := v.keys[node]
+	if !ok {
+		return nil, "", fmt.Errorf("no public key for node %s", node)
+	}
+
+	return key, node, nil
+}
+
+// signedFile returns the candidate file signed by the signature file, or an empty string if there is none.
+func signedFile(marker string, candidates []string) string {
+	name := strings.TrimSuffix(marker, signatureSuffix)
+	for _, candidate := range candidates {
+		if candidate != marker && strings.TrimSuffix(candidate, ".gz") == name {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func readSignatureFile(path string) (*hedera.SignatureFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return hedera.ReadSignatureFile(f)
+}
+
+// fileHash returns the hash of the uncompressed content of a stream file signed by the nodes: the file hash of record
+// files (see hedera.RecordFileHash), or the SHA-384 hash of the content of event files.
+func fileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip header: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(strings.TrimSuffix(path, ".gz"), recordFileSuffix) {
+		return hedera.RecordFileHash(data)
+	}
+
+	sum := sha512.Sum384(data)
+	return sum[:], nil
+}
+
+// NewVerifier creates a signature verifier for the marker files of the pipeline.
+//
+// Parameters:
+//   - pipeline: The name of the pipeline, used in logs and as metrics label.
+//   - keyDir: The directory of the PEM files of the node public keys, named after the node account IDs.
+//   - addressBook: The address book file holding the node public keys.
+//
+// Keys of both sources are loaded if both are set; keys of the key directory take precedence.
+func NewVerifier(pipeline string, keyDir string, addressBook string) (*Verifier, error) {
+	if keyDir == "" && addressBook == "" {
+		return nil, fmt.Errorf("signature verification requires a key directory or an address book")
+	}
+
+	keys := make(Keys)
+	if addressBook != "" {
+		loaded, err := LoadAddressBook(addressBook)
+		if err != nil {
+			return nil, err
+		}
+		for node, key := range loaded {
+			keys[node] = key
+		}
+	}
+
+	if keyDir != "" {
+		loaded, err := LoadPEMDir(keyDir)
+		if err != nil {
+			return nil, err
+		}
+		for node, key := range loaded {
+			keys[node] = key
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no node public keys found")
+	}
+
+	logx.As().Info().
+		Str("pipeline", pipeline).
+		Str("key_dir", keyDir).
+		Str("address_book", addressBook).
+		Int("keys", len(keys)).
+		Msg("Signature verification enabled")
+
+	return &Verifier{pipeline: pipeline, keys: keys}, nil
+}
@@ -0,0 +1,152 @@
+package signature
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/metrics"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sign(t *testing.T, key *rsa.PrivateKey, hash []byte) []byte {
+	digest := sha512.Sum384(hash)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA384, digest[:])
+	require.NoError(t, err)
+	return sig
+}
+
+func writeGzip(t *testing.T, path string, content []byte) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+// writeStreamFile writes a gzip compressed stream file and its version 6 signature file signed with the key, and
+// returns the paths of the signature file and the stream file.
+func writeStreamFile(t *testing.T, dir string, name string, content []byte, key *rsa.PrivateKey) (string, string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	dataFile := filepath.Join(dir, name+".gz")
+	writeGzip(t, dataFile, content)
+
+	fileHash := sha512.Sum384(content)
+	metaHash := sha512.Sum384([]byte("metadata"))
+	sig := hederatest.SignatureFile(hedera.SignatureFileVersion6, fileHash[:], sign(t, key, fileHash[:]), metaHash[:],
+		sign(t, key, metaHash[:]))
+
+	sigFile := filepath.Join(dir, name+"_sig")
+	require.NoError(t, os.WriteFile(sigFile, sig, 0644))
+
+	return sigFile, dataFile
+}
+
+func TestVerifier(t *testing.T) {
+	key3 := generateKey(t)
+	key4 := generateKey(t)
+
+	keyDir := t.TempDir()
+	writePublicKey(t, keyDir, "node0.0.3.pem", key3)
+	writePublicKey(t, keyDir, "node0.0.4.pem", key4)
+
+	pipeline := "signature-test"
+	v, err := NewVerifier(pipeline, keyDir, "")
+	require.NoError(t, err)
+
+	root := filepath.Join(t.TempDir(), "recordStreams")
+	name := "2024-01-01T00_00_00.000000000Z.rcd"
+
+	t.Run("valid signature", func(t *testing.T) {
+		sigFile, dataFile := writeStreamFile(t, filepath.Join(root, "record0.0.3"), name, []byte("records"), key3)
+		require.NoError(t, v.Verify(sigFile, []string{sigFile, dataFile}))
+	})
+
+	t.Run("version 2 record file", func(t *testing.T) {
+		// the nodes sign the hash of the header followed by the hash of the body, not the hash of the whole file
+		header := append(append(hederatest.AppendInt32(nil, hedera.RecordStreamVersion2, 3), 1), hederatest.Hash(2)...)
+		body := sha512.Sum384([]byte("records"))
+		fileHash := sha512.Sum384(append(append([]byte(nil), header...), body[:]...))
+		fileSig := sign(t, key3, fileHash[:])
+
+		dir := filepath.Join(root, "record0.0.3")
+		dataFile := filepath.Join(dir, "2019-09-13T21_53_51.396440Z.rcd")
+		require.NoError(t, os.WriteFile(dataFile, append(header, []byte("records")...), 0644))
+
+		// a version 2 signature file is the file hash and the signature, each after its type byte
+		sig := append([]byte{4}, fileHash[:]...)
+		sig = hederatest.AppendInt32(append(sig, 3), int32(len(fileSig)))
+		sigFile := dataFile + "_sig"
+		require.NoError(t, os.WriteFile(sigFile, append(sig, fileSig...), 0644))
+
+		require.NoError(t, v.Verify(sigFile, []string{sigFile, dataFile}))
+	})
+
+	t.Run("marker is not a signature file", func(t *testing.T) {
+		require.NoError(t, v.Verify(filepath.Join(root, "record0.0.3", name+".gz"), nil))
+	})
+
+	failures := []struct {
+		name   string
+		dir    string
+		key    *rsa.PrivateKey
+		tamper bool
+		skip   bool
+		errMsg string
+	}{
+		{name: "signed by another node", dir: "record0.0.4", key: key3, errMsg: "invalid signature of node 0.0.4"},
+		{name: "tampered stream file", dir: "record0.0.3", key: key3, tamper: true, errMsg: "does not match the signed file hash"},
+		{name: "unknown node", dir: "record0.0.5", key: key3, errMsg: "no public key for node 0.0.5"},
+		{name: "missing stream file", dir: "record0.0.3", key: key3, skip: true, errMsg: "not found among the candidate files"},
+		{name: "directory without node account ID", dir: "records", key: key3, errMsg: "no node account ID"},
+	}
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := testutil.ToFloat64(metrics.SignatureFailures.WithLabelValues(pipeline))
+
+			sigFile, dataFile := writeStreamFile(t, filepath.Join(t.TempDir(), tt.dir), name, []byte("records"), tt.key)
+			if tt.tamper {
+				writeGzip(t, dataFile, []byte("tampered records"))
+			}
+
+			candidates := []string{sigFile, dataFile}
+			if tt.skip {
+				candidates = []string{sigFile}
+			}
+
+			require.ErrorContains(t, v.Verify(sigFile, candidates), tt.errMsg)
+			require.Equal(t, failures+1, testutil.ToFloat64(metrics.SignatureFailures.WithLabelValues(pipeline)))
+		})
+	}
+}
+
+func TestVerifier_SingleKey(t *testing.T) {
+	key := generateKey(t)
+	book := filepath.Join(t.TempDir(), "0.0.102")
+	writeAddressBook(t, book, nodeAddress(t, 0, 0, 3, key, false))
+
+	v, err := NewVerifier("signature-single-key", "", book)
+	require.NoError(t, err)
+
+	// without a node account ID in the directory, the only key is used
+	sigFile, dataFile := writeStreamFile(t, filepath.Join(t.TempDir(), "records"), "2024-01-01T00_00_00.000000000Z.evts", []byte("events"), key)
+	require.NoError(t, v.Verify(sigFile, []string{sigFile, dataFile}))
+}
+
+func TestNewVerifier(t *testing.T) {
+	_, err := NewVerifier("signature-config", "", "")
+	require.ErrorContains(t, err, "requires a key directory or an address book")
+
+	_, err = NewVerifier("signature-config", t.TempDir(), "")
+	require.ErrorContains(t, err, "no node public keys found")
+}
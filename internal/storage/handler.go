@@ -3,10 +3,15 @@ package storage
 import (
 	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/tracing"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // handler is a base struct for managing file storage operations.
@@ -17,13 +22,25 @@ import (
 //   - pathPrefix: The prefix for the destination path.
 //   - preSync: A function to validate or prepare the destination before syncing.
 //   - syncFile: A function to handle the actual file synchronization.
+//   - statObject: A function to get the metadata of a stored object.
+//   - listObjects: A function to list a page of the stored objects.
+//   - getObject: A function to download a stored object to a local file.
+//   - deleteObject: A function to remove a stored object.
+//   - copyObject: A function to copy an object of another storage without downloading it.
+//
+// Optional operations that are not set are reported as not supported.
 type handler struct {
-	id          string
-	storageType string
-	rootDir     string
-	pathPrefix  string
-	preSync     func(ctx context.Context) error
-	syncFile    func(ctx context.Context, src string, dest string) (*core.UploadInfo, error)
+	id           string
+	storageType  string
+	rootDir      string
+	pathPrefix   string
+	preSync      func(ctx context.Context) error
+	syncFile     func(ctx context.Context, src string, dest string, meta *core.ObjectMetadata) (*core.UploadInfo, error)
+	statObject   func(ctx context.Context, key string) (*core.ObjectInfo, error)
+	listObjects  func(ctx context.Context, opts core.ListOptions) (*core.ListResult, error)
+	getObject    func(ctx context.Context, key string, dest string) (*core.ObjectInfo, error)
+	deleteObject func(ctx context.Context, key string) error
+	copyObject   func(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error)
 }
 
 // Info returns the unique identifier of the handler.
@@ -36,6 +53,131 @@ func (h *handler) Type() string {
 	return h.storageType
 }
 
+// Key returns the key that the local file is stored as, without a leading separator.
+func (h *handler) Key(src string) string {
+	return strings.TrimPrefix(core.ComputeDestinationBucketPath(h.rootDir, src, h.pathPrefix), "/")
+}
+
+// Upload uploads the local file to the key. The metadata may be nil for the defaults of the storage.
+func (h *handler) Upload(ctx context.Context, src string, key string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
+	if h.preSync != nil {
+		if err := h.preSync(ctx); err != nil {
+			return nil, fmt.Errorf("pre-sync validation failed: %w", err)
+		}
+	}
+
+	start := time.Now()
+	key = strings.TrimPrefix(key, "/")
+	result, err := h.syncFile(ctx, src, key, meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file %s in %s: %w", src, h.Type(), err)
+	}
+	result.Key = key
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+// Supports returns whether the handler supports the optional operation.
+func (h *handler) Supports(c core.Capability) bool {
+	switch c {
+	case core.CapabilityStat:
+		return h.statObject != nil
+	case core.CapabilityList:
+		return h.listObjects != nil
+	case core.CapabilityGet:
+		return h.getObject != nil
+	case core.CapabilityDelete:
+		return h.deleteObject != nil
+	case core.CapabilityCopy:
+		return h.copyObject != nil
+	default:
+		return false
+	}
+}
+
+// Stat returns the metadata of the object stored with the key.
+func (h *handler) Stat(ctx context.Context, key string) (*core.ObjectInfo, error) {
+	if h.statObject == nil {
+		return nil, h.notSupported(core.CapabilityStat)
+	}
+
+	return h.statObject(ctx, strings.TrimPrefix(key, "/"))
+}
+
+// List returns a page of the objects whose key starts with the prefix.
+// If the prefix is empty, the objects under the path prefix of the handler are listed.
+func (h *handler) List(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	if h.listObjects == nil {
+		return nil, h.notSupported(core.CapabilityList)
+	}
+
+	if opts.Prefix == "" {
+		opts.Prefix = strings.TrimPrefix(h.pathPrefix, "/")
+		if opts.Prefix != "" && !strings.HasSuffix(opts.Prefix, "/") {
+			opts.Prefix += "/"
+		}
+	}
+
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = core.DefaultListMaxKeys
+	}
+
+	return h.listObjects(ctx, opts)
+}
+
+// Get downloads the object stored with the key to the local file.
+func (h *handler) Get(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	if h.getObject == nil {
+		return nil, h.notSupported(core.CapabilityGet)
+	}
+
+	return h.getObject(ctx, strings.TrimPrefix(key, "/"), dest)
+}
+
+// Delete removes the object stored with the key.
+func (h *handler) Delete(ctx context.Context, key string) error {
+	if h.deleteObject == nil {
+		return h.notSupported(core.CapabilityDelete)
+	}
+
+	return h.deleteObject(ctx, strings.TrimPrefix(key, "/"))
+}
+
+// Copy copies the object stored with the key in the source storage to the key in this storage.
+func (h *handler) Copy(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error) {
+	if h.copyObject == nil {
+		return nil, h.notSupported(core.CapabilityCopy)
+	}
+
+	return h.copyObject(ctx, src, strings.TrimPrefix(srcKey, "/"), strings.TrimPrefix(destKey, "/"))
+}
+
+func (h *handler) notSupported(c core.Capability) error {
+	return fmt.Errorf("%w: %s by %s", core.ErrNotSupported, c, h.Type())
+}
+
+// paginate returns the page of objects after opts.StartAfter from objects sorted by key.
+func paginate(objects []core.ObjectInfo, opts core.ListOptions) *core.ListResult {
+	start := 0
+	if opts.StartAfter != "" {
+		start = sort.Search(len(objects), func(i int) bool {
+			return objects[i].Key > opts.StartAfter
+		})
+	}
+
+	page := objects[start:]
+	result := &core.ListResult{}
+	if opts.MaxKeys > 0 && len(page) > opts.MaxKeys {
+		page = page[:opts.MaxKeys]
+		result.Truncated = true
+		result.NextStartAfter = page[len(page)-1].Key
+	}
+
+	result.Objects = page
+	return result
+}
+
 // Put uploads a file to the storage and sends the result to the provided channel.
 //
 // Parameters:
@@ -54,7 +196,14 @@ func (h *handler) Put(ctx context.Context, marker core.ScannerResult, candidates
 		Str("candidates", fmt.Sprintf("%v", candidates)).
 		Msg("Identified candidate files to be uploaded")
 
-	uploadResults, err := h.runParallel(ctx, candidates)
+	ctx, span := tracing.StartSpan(ctx, "storage.put",
+		tracing.AttrTraceId.String(marker.TraceId),
+		tracing.AttrMarker.String(marker.Path),
+		tracing.AttrStorage.String(h.Type()),
+		tracing.AttrHandler.String(h.Info()),
+		attribute.Int("candidates", len(candidates)))
+	uploadResults, err := h.runParallel(ctx, candidates, marker.Metadata)
+	tracing.EndSpan(span, err)
 	result := core.StorageResult{
 		Error:         err,
 		MarkerPath:    marker.Path,
@@ -86,11 +235,12 @@ func (h *handler) Put(ctx context.Context, marker core.ScannerResult, candidates
 //
 // Parameters:
 //   - ctx: The context for managing request deadlines and cancellations.
+//   - meta: The metadata stored with the files, nil for the defaults of the storage.
 //
 // Returns:
 //   - A slice of UploadInfo containing details of the uploaded files.
 //   - An error if any file fails to upload.
-func (h *handler) runParallel(ctx context.Context, candidates []string) ([]*core.UploadInfo, error) {
+func (h *handler) runParallel(ctx context.Context, candidates []string, meta *core.ObjectMetadata) ([]*core.UploadInfo, error) {
 	if h.preSync != nil {
 		if err := h.preSync(ctx); err != nil {
 			return nil, fmt.Errorf("pre-sync validation failed: %w", err)
@@ -112,11 +262,22 @@ func (h *handler) runParallel(ctx context.Context, candidates []string) ([]*core
 		wg.Add(1)
 		go func(src string, dst string) {
 			defer wg.Done()
-			result, err := h.syncFile(ctx, src, dst)
+			syncCtx, span := tracing.StartSpan(ctx, "storage.sync_file",
+				tracing.AttrStorage.String(h.Type()),
+				tracing.AttrSrc.String(src),
+				tracing.AttrDest.String(dst))
+			start := time.Now()
+			result, err := h.syncFile(syncCtx, src, dst, meta)
+			if result != nil {
+				span.SetAttributes(attribute.Int64("size", result.Size), attribute.Bool("skipped", result.Skipped))
+			}
+			tracing.EndSpan(span, err)
 			if err != nil {
 				errChan <- fmt.Errorf("failed to upload file %s in %s: %w", src, h.Type(), err)
 				return
 			}
+			result.Key = strings.TrimPrefix(dst, "/")
+			result.Duration = time.Since(start)
 
 			mu.Lock()
 			results = append(results, result)
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,7 +29,7 @@ func Test_handler_Put(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Mock syncFile function
-	mockSyncFile := func(ctx context.Context, src string, dest string) (*core.UploadInfo, error) {
+	mockSyncFile := func(ctx context.Context, src string, dest string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
 		return &core.UploadInfo{Src: src, Dest: dest}, nil
 	}
 
@@ -69,7 +70,7 @@ func Test_handler_Put_Missing_File(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Mock syncFile function
-	mockSyncFile := func(ctx context.Context, src string, dest string) (*core.UploadInfo, error) {
+	mockSyncFile := func(ctx context.Context, src string, dest string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
 		return &core.UploadInfo{Src: src, Dest: dest}, nil
 	}
 
@@ -114,7 +115,7 @@ func Test_handler_Put_Upload_Failed(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Mock syncFile function
-	mockSyncFile := func(ctx context.Context, src string, dest string) (*core.UploadInfo, error) {
+	mockSyncFile := func(ctx context.Context, src string, dest string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
 		if strings.HasSuffix(src, ".mf") {
 			return &core.UploadInfo{Src: src, Dest: dest}, nil
 		}
@@ -147,3 +148,33 @@ func Test_handler_Put_Upload_Failed(t *testing.T) {
 		assert.Contains(t, result.MarkerPath, "file.mf")
 	}
 }
+
+func Test_handler_Upload(t *testing.T) {
+	var dests []string
+	h := &handler{
+		id:          "test-handler",
+		storageType: "local",
+		rootDir:     "/data",
+		pathPrefix:  "uploads",
+		syncFile: func(ctx context.Context, src string, dest string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
+			dests = append(dests, dest)
+			if src == "/tmp/broken" {
+				return nil, errors.New("upload failed")
+			}
+			return &core.UploadInfo{Src: src, Dest: dest}, nil
+		},
+	}
+
+	// the key is used as is instead of the key derived from the path of the file
+	info, err := h.Upload(context.Background(), "/tmp/download-1", "/host1/record0.0.3/file.rcd.gz", nil)
+	require.NoError(t, err)
+	require.Equal(t, "host1/record0.0.3/file.rcd.gz", info.Dest)
+
+	_, err = h.Upload(context.Background(), "/tmp/broken", "record0.0.3/file.rcd.gz", nil)
+	require.ErrorContains(t, err, "upload failed")
+	require.Equal(t, []string{"host1/record0.0.3/file.rcd.gz", "record0.0.3/file.rcd.gz"}, dests)
+
+	h.preSync = func(ctx context.Context) error { return errors.New("bucket is gone") }
+	_, err = h.Upload(context.Background(), "/tmp/download-1", "record0.0.3/file.rcd.gz", nil)
+	require.ErrorContains(t, err, "pre-sync validation failed")
+}
@@ -9,6 +9,8 @@ import (
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 type localDirectoryHandler struct {
@@ -49,7 +51,8 @@ func (d *localDirectoryHandler) ensureDirExists(ctx context.Context) error {
 }
 
 // syncWithDir copies a file to the local directory. It skips copying if the file already exists with the same checksum.
-func (d *localDirectoryHandler) syncWithDir(ctx context.Context, src string, dest string) (*core.UploadInfo, error) {
+// Local directories do not keep metadata, so the metadata of the file is ignored.
+func (d *localDirectoryHandler) syncWithDir(ctx context.Context, src string, dest string, _ *core.ObjectMetadata) (*core.UploadInfo, error) {
 	var err error
 	var localChecksum, remoteChecksum string
 
@@ -111,7 +114,12 @@ func (d *localDirectoryHandler) syncWithDir(ctx context.Context, src string, des
 				Str("storage_type", d.Type()).
 				Str("id", d.Info()).
 				Msg("File already exists in the local directory, skipping copy")
-			return d.prepareUploadInfo(src, dest, remoteChecksum, destInfo)
+			uploadInfo, err := d.prepareUploadInfo(src, dest, remoteChecksum, destInfo)
+			if err != nil {
+				return nil, err
+			}
+			uploadInfo.Skipped = true
+			return uploadInfo, nil
 		}
 	}
 
@@ -175,11 +183,180 @@ func (d *localDirectoryHandler) prepareUploadInfo(src string, dest string, check
 	}, nil
 }
 
+// listDirObjects lists a page of the files with the prefix in the local directory.
+// Checksums are only calculated for the files in the page.
+func (d *localDirectoryHandler) listDirObjects(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	if _, exists := fsx.PathExists(d.dirConfig.Path); !exists {
+		return &core.ListResult{}, nil
+	}
+
+	var objects []core.ObjectInfo
+	err := filepath.Walk(d.dirConfig.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.dirConfig.Path, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, opts.Prefix) {
+			objects = append(objects, core.ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory %s: %w", d.dirConfig.Path, err)
+	}
+
+	// walk order is not the key order since path separators sort differently
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+
+	result := paginate(objects, opts)
+	for i := range result.Objects {
+		checksum, err := fsx.FileMD5(filepath.Join(d.dirConfig.Path, result.Objects[i].Key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate checksum of %s: %w", result.Objects[i].Key, err)
+		}
+		result.Objects[i].ChecksumType = "md5"
+		result.Objects[i].Checksum = checksum
+	}
+
+	return result, nil
+}
+
+// statDirObject returns the metadata of a file in the local directory.
+func (d *localDirectoryHandler) statDirObject(ctx context.Context, key string) (*core.ObjectInfo, error) {
+	path := filepath.Join(d.dirConfig.Path, key)
+	info, exists := fsx.PathExists(path)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", core.ErrObjectNotFound, key)
+	}
+
+	return d.objectInfo(key, path, info)
+}
+
+// getDirObject copies a file from the local directory to the destination file.
+func (d *localDirectoryHandler) getDirObject(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	obj, err := d.statDirObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), d.dirConfig.Mode); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	if err := fsx.Copy(filepath.Join(d.dirConfig.Path, key), dest, 0644); err != nil {
+		return nil, fmt.Errorf("failed to copy file %s: %w", key, err)
+	}
+
+	return obj, nil
+}
+
+// copyDirObject copies a file of another local directory storage into the local directory, keeping its modification
+// time, and verifies that the copy has the size and checksum of the source file.
+func (d *localDirectoryHandler) copyDirObject(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error) {
+	srcDir, ok := src.(*localDirectoryHandler)
+	if !ok {
+		return nil, fmt.Errorf("%w: copy from %s to %s", core.ErrNotSupported, src.Type(), d.Type())
+	}
+
+	srcObj, err := srcDir.statDirObject(ctx, srcKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := filepath.Join(d.dirConfig.Path, destKey)
+	if err := os.MkdirAll(filepath.Dir(dest), d.dirConfig.Mode); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	if err := fsx.Copy(filepath.Join(srcDir.dirConfig.Path, srcKey), dest, d.dirConfig.Mode); err != nil {
+		return nil, fmt.Errorf("failed to copy file %s: %w", srcKey, err)
+	}
+
+	if err := os.Chtimes(dest, srcObj.LastModified, srcObj.LastModified); err != nil {
+		return nil, fmt.Errorf("failed to set modification time of %s: %w", dest, err)
+	}
+
+	obj, err := d.statDirObject(ctx, destKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.Size != srcObj.Size || obj.Checksum != srcObj.Checksum {
+		return nil, fmt.Errorf("checksum mismatch after copy of %s: expected %s (%d bytes), got %s (%d bytes)",
+			srcKey, srcObj.Checksum, srcObj.Size, obj.Checksum, obj.Size)
+	}
+
+	return obj, nil
+}
+
+// deleteDirObject removes a file from the local directory.
+func (d *localDirectoryHandler) deleteDirObject(ctx context.Context, key string) error {
+	path := filepath.Join(d.dirConfig.Path, key)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", core.ErrObjectNotFound, key)
+		}
+		return fmt.Errorf("failed to remove file %s: %w", path, err)
+	}
+
+	logx.As().Info().
+		Str("id", d.Info()).
+		Str("path", path).
+		Msg("Removed file from the local directory")
+
+	return nil
+}
+
+// objectInfo prepares the object information for a file in the local directory.
+func (d *localDirectoryHandler) objectInfo(key string, path string, info os.FileInfo) (*core.ObjectInfo, error) {
+	checksum, err := fsx.FileMD5(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate checksum of %s: %w", path, err)
+	}
+
+	return &core.ObjectInfo{
+		Key:          filepath.ToSlash(key),
+		ChecksumType: "md5",
+		Checksum:     checksum,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
 // NewLocalDir creates a new local directory storage handler.
 func NewLocalDir(id string, config config.LocalDirConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
 	return newLocalDir(id, config, retryConfig, rootDir)
 }
 
+// OpenLocalDir creates a new local directory storage handler of an existing directory, without creating it.
+func OpenLocalDir(id string, config config.LocalDirConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
+	info, err := os.Stat(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory %s: %w", config.Path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", config.Path)
+	}
+
+	return newLocalDir(id, config, retryConfig, rootDir)
+}
+
 func newLocalDir(id string, config config.LocalDirConfig, retryConfig config.RetryConfig, rootDir string) (*localDirectoryHandler, error) {
 	l := &localDirectoryHandler{
 		handler: &handler{
@@ -194,6 +371,11 @@ func newLocalDir(id string, config config.LocalDirConfig, retryConfig config.Ret
 	// Initialize the handler functions
 	l.handler.preSync = l.ensureDirExists
 	l.handler.syncFile = l.syncWithDir
+	l.handler.statObject = l.statDirObject
+	l.handler.listObjects = l.listDirObjects
+	l.handler.getObject = l.getDirObject
+	l.handler.deleteObject = l.deleteDirObject
+	l.handler.copyObject = l.copyDirObject
 
 	logx.As().Trace().
 		Str("id", l.Info()).
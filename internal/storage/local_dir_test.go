@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"os"
 	"path/filepath"
@@ -34,6 +36,24 @@ func TestLocalDirectoryHandler_EnsureDirExists(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestOpenLocalDir(t *testing.T) {
+	tempDir := t.TempDir()
+
+	_, err := OpenLocalDir("test", config.LocalDirConfig{Path: tempDir}, config.RetryConfig{Limit: 1}, tempDir)
+	require.NoError(t, err)
+
+	// a missing directory is not created
+	missing := filepath.Join(tempDir, "missing")
+	_, err = OpenLocalDir("test", config.LocalDirConfig{Path: missing}, config.RetryConfig{Limit: 1}, tempDir)
+	require.ErrorContains(t, err, "failed to open directory")
+	require.NoDirExists(t, missing)
+
+	file := filepath.Join(tempDir, "file.txt")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	_, err = OpenLocalDir("test", config.LocalDirConfig{Path: file}, config.RetryConfig{Limit: 1}, tempDir)
+	require.ErrorContains(t, err, "is not a directory")
+}
+
 func TestLocalDirectoryHandler_SyncWithDir(t *testing.T) {
 	tempDir := t.TempDir()
 	destDir := filepath.Join(tempDir, "dest")
@@ -55,7 +75,7 @@ func TestLocalDirectoryHandler_SyncWithDir(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test file synchronization
-	uploadInfo, err := h.syncWithDir(context.Background(), srcFile, destFile)
+	uploadInfo, err := h.syncWithDir(context.Background(), srcFile, destFile, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, uploadInfo)
 
@@ -65,9 +85,105 @@ func TestLocalDirectoryHandler_SyncWithDir(t *testing.T) {
 	assert.True(t, exists)
 
 	// Test skipping copy if file already exists with the same checksum
-	uploadInfo, err = h.syncWithDir(context.Background(), srcFile, destFile)
+	uploadInfo, err = h.syncWithDir(context.Background(), srcFile, destFile, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, uploadInfo)
 	assert.Equal(t, srcFile, uploadInfo.Src)
 	assert.Equal(t, destPath, uploadInfo.Dest)
 }
+
+func TestLocalDirectoryHandler_ObjectOperations(t *testing.T) {
+	ctx := context.Background()
+	rootDir := t.TempDir()
+	destDir := filepath.Join(t.TempDir(), "dest")
+
+	h, err := newLocalDir("test", config.LocalDirConfig{Path: destDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+
+	for _, c := range []core.Capability{core.CapabilityStat, core.CapabilityList, core.CapabilityGet, core.CapabilityDelete, core.CapabilityCopy} {
+		require.True(t, h.Supports(c))
+	}
+
+	// nothing stored yet
+	page, err := h.List(ctx, core.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, page.Objects)
+
+	// "sub.txt" sorts before "sub/..." by key even though the walk visits the sub directory first
+	var srcFiles []string
+	for _, name := range []string{"sub/b.rcd", "sub/a.rcd", "sub.txt", "other/c.rcd"} {
+		srcFile := filepath.Join(rootDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(srcFile), 0755))
+		require.NoError(t, os.WriteFile(srcFile, []byte(name), 0644))
+		srcFiles = append(srcFiles, srcFile)
+	}
+
+	key := h.Key(srcFiles[0])
+	require.Equal(t, "sub/b.rcd", key)
+
+	_, err = h.Stat(ctx, key)
+	require.ErrorIs(t, err, core.ErrObjectNotFound)
+
+	stored := make(chan core.StorageResult, 1)
+	h.Put(ctx, core.ScannerResult{Path: srcFiles[0]}, srcFiles, stored)
+	require.NoError(t, (<-stored).Error)
+
+	// paginated listing of all objects
+	page, err = h.List(ctx, core.ListOptions{MaxKeys: 2})
+	require.NoError(t, err)
+	require.True(t, page.Truncated)
+	require.Equal(t, []string{"other/c.rcd", "sub.txt"}, []string{page.Objects[0].Key, page.Objects[1].Key})
+
+	page, err = h.List(ctx, core.ListOptions{MaxKeys: 2, StartAfter: page.NextStartAfter})
+	require.NoError(t, err)
+	require.False(t, page.Truncated)
+	require.Equal(t, []string{"sub/a.rcd", "sub/b.rcd"}, []string{page.Objects[0].Key, page.Objects[1].Key})
+
+	checksum, err := fsx.FileMD5(srcFiles[0])
+	require.NoError(t, err)
+	require.Equal(t, checksum, page.Objects[1].Checksum)
+
+	// listing by prefix
+	objects, err := core.ListAll(ctx, h, "sub/")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	obj, err := h.Stat(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, page.Objects[1], *obj)
+
+	// download
+	downloaded := filepath.Join(t.TempDir(), "restore", "b.rcd")
+	obj, err = h.Get(ctx, key, downloaded)
+	require.NoError(t, err)
+	require.Equal(t, checksum, obj.Checksum)
+	data, err := os.ReadFile(downloaded)
+	require.NoError(t, err)
+	require.Equal(t, "sub/b.rcd", string(data))
+
+	_, err = h.Get(ctx, "missing.rcd", downloaded)
+	require.ErrorIs(t, err, core.ErrObjectNotFound)
+
+	// copy to another directory keeps the modification time and uses the mode of the directory
+	otherDir := t.TempDir()
+	other, err := newLocalDir("other", config.LocalDirConfig{Path: otherDir, Mode: 0750}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+	copied, err := other.Copy(ctx, h, key, "copy/b.rcd")
+	require.NoError(t, err)
+	require.Equal(t, "copy/b.rcd", copied.Key)
+	require.Equal(t, checksum, copied.Checksum)
+	require.True(t, obj.LastModified.Equal(copied.LastModified))
+	info, err := os.Stat(filepath.Join(otherDir, "copy", "b.rcd"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	_, err = other.Copy(ctx, h, "missing.rcd", "missing.rcd")
+	require.ErrorIs(t, err, core.ErrObjectNotFound)
+
+	// delete
+	require.NoError(t, h.Delete(ctx, key))
+	require.ErrorIs(t, h.Delete(ctx, key), core.ErrObjectNotFound)
+	objects, err = core.ListAll(ctx, h, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+}
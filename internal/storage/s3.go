@@ -10,6 +10,7 @@ import (
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"golang.hedera.com/solo-cheetah/pkg/logx"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -31,6 +32,14 @@ type s3Client interface {
 	StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
 
 	FPutObject(ctx context.Context, bucketName, objectName, filePath string, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+
+	FGetObject(ctx context.Context, bucketName, objectName, filePath string, opts minio.GetObjectOptions) error
+
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
+
+	CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error)
 }
 
 // minioClientWrapper is a wrapper around the MinIO client to implement the s3Client interface.
@@ -54,6 +63,22 @@ func (m *minioClientWrapper) FPutObject(ctx context.Context, bucketName, objectN
 	return m.client.FPutObject(ctx, bucketName, objectName, filePath, opts)
 }
 
+func (m *minioClientWrapper) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	return m.client.ListObjects(ctx, bucketName, opts)
+}
+
+func (m *minioClientWrapper) FGetObject(ctx context.Context, bucketName, objectName, filePath string, opts minio.GetObjectOptions) error {
+	return m.client.FGetObject(ctx, bucketName, objectName, filePath, opts)
+}
+
+func (m *minioClientWrapper) RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error {
+	return m.client.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+func (m *minioClientWrapper) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	return m.client.CopyObject(ctx, dst, src)
+}
+
 // ensureBucketExists checks if the bucket exists in S3. If it doesn't exist, it creates the bucket.
 func (s *s3Handler) ensureBucketExists(ctx context.Context) error {
 	if _, exists := s.bucketExists[s.bucketConfig.Bucket]; exists {
@@ -97,8 +122,9 @@ func (s *s3Handler) ensureBucketExists(ctx context.Context) error {
 	return nil
 }
 
-// syncWithBucket uploads a file to the S3 bucket. It skips the upload if the file already exists with the same checksum.
-func (s *s3Handler) syncWithBucket(ctx context.Context, src, objectName string) (*core.UploadInfo, error) {
+// syncWithBucket uploads a file to the S3 bucket with the metadata, if set. It skips the upload if the file already
+// exists with the same checksum.
+func (s *s3Handler) syncWithBucket(ctx context.Context, src, objectName string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
 	logx.As().Info().
 		Str("id", s.Info()).
 		Str("src", src).
@@ -133,6 +159,7 @@ func (s *s3Handler) syncWithBucket(ctx context.Context, src, objectName string)
 			Checksum:     attr.ETag,
 			Size:         attr.Size,
 			LastModified: attr.LastModified,
+			Skipped:      true,
 		}, nil
 	}
 
@@ -144,10 +171,16 @@ func (s *s3Handler) syncWithBucket(ctx context.Context, src, objectName string)
 		Str("bucket", s.bucketConfig.Bucket).
 		Msg("Uploading file to bucket")
 
-	info, err := s.client.FPutObject(ctx, s.bucketConfig.Bucket, objectName, src, minio.PutObjectOptions{
+	opts := minio.PutObjectOptions{
 		SendContentMd5:        true,
 		ConcurrentStreamParts: false,
-	})
+	}
+	if meta != nil {
+		opts.ContentType = meta.ContentType
+		opts.UserMetadata = meta.UserMetadata
+	}
+
+	info, err := s.client.FPutObject(ctx, s.bucketConfig.Bucket, objectName, src, opts)
 	if err != nil {
 		logx.As().Error().
 			Str("id", s.Info()).
@@ -219,8 +252,181 @@ func (s *s3Handler) syncWithBucket(ctx context.Context, src, objectName string)
 	}, nil
 }
 
-// newS3Handler initializes a new S3 handler with the provided configuration and retry settings.
-func newS3Handler(id string, storageType string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (*s3Handler, error) {
+// listBucketObjects lists a page of the objects with the prefix in the bucket.
+// Listing stops as soon as the page is full, so that only the pages needed are fetched from the bucket.
+func (s *s3Handler) listBucketObjects(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel() // stop fetching further pages
+
+	result := &core.ListResult{}
+	for obj := range s.client.ListObjects(listCtx, s.bucketConfig.Bucket, minio.ListObjectsOptions{
+		Prefix:     opts.Prefix,
+		StartAfter: opts.StartAfter,
+		MaxKeys:    opts.MaxKeys,
+		Recursive:  true,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list objects in bucket %s: %w", s.bucketConfig.Bucket, obj.Err)
+		}
+
+		if len(result.Objects) == opts.MaxKeys {
+			result.Truncated = true
+			result.NextStartAfter = result.Objects[len(result.Objects)-1].Key
+			break
+		}
+
+		result.Objects = append(result.Objects, core.ObjectInfo{
+			Key:          obj.Key,
+			ChecksumType: "md5",
+			Checksum:     obj.ETag,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+		})
+	}
+
+	logx.As().Debug().
+		Str("id", s.Info()).
+		Str("bucket", s.bucketConfig.Bucket).
+		Str("prefix", opts.Prefix).
+		Str("start_after", opts.StartAfter).
+		Int("objects", len(result.Objects)).
+		Bool("truncated", result.Truncated).
+		Msg("Listed objects in bucket")
+
+	return result, nil
+}
+
+// statBucketObject returns the metadata of an object in the bucket.
+func (s *s3Handler) statBucketObject(ctx context.Context, key string) (*core.ObjectInfo, error) {
+	attr, err := s.client.StatObject(ctx, s.bucketConfig.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: %s", core.ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get object info %s: %w", key, err)
+	}
+
+	return &core.ObjectInfo{
+		Key:          key,
+		ChecksumType: "md5",
+		Checksum:     attr.ETag,
+		Size:         attr.Size,
+		LastModified: attr.LastModified,
+		Metadata: core.ObjectMetadata{
+			ContentType:  attr.ContentType,
+			UserMetadata: attr.UserMetadata,
+		},
+	}, nil
+}
+
+// getBucketObject downloads an object from the bucket to the local file and verifies its checksum.
+func (s *s3Handler) getBucketObject(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	obj, err := s.statBucketObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.FGetObject(ctx, s.bucketConfig.Bucket, key, dest, minio.GetObjectOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to download object %s: %w", key, err)
+	}
+
+	// ETag of multipart uploads is not the MD5 of the content, so it can't be verified
+	if !strings.Contains(obj.Checksum, "-") {
+		checksum, err := fsx.FileMD5(dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate checksum of %s: %w", dest, err)
+		}
+
+		if checksum != obj.Checksum {
+			return nil, fmt.Errorf("checksum mismatch after download of %s: expected %s, got %s", key, obj.Checksum, checksum)
+		}
+	}
+
+	logx.As().Debug().
+		Str("id", s.Info()).
+		Str("bucket", s.bucketConfig.Bucket).
+		Str("object", key).
+		Str("dest", dest).
+		Msg("Downloaded object from bucket")
+
+	return obj, nil
+}
+
+// deleteBucketObject removes an object from the bucket.
+// S3 doesn't report missing objects on removal, so the object is checked first to return core.ErrObjectNotFound.
+func (s *s3Handler) deleteBucketObject(ctx context.Context, key string) error {
+	if _, err := s.statBucketObject(ctx, key); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(ctx, s.bucketConfig.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to remove object %s: %w", key, err)
+	}
+
+	logx.As().Info().
+		Str("id", s.Info()).
+		Str("bucket", s.bucketConfig.Bucket).
+		Str("object", key).
+		Msg("Removed object from bucket")
+
+	return nil
+}
+
+// copyBucketObject copies an object of another bucket into the bucket using a server-side copy, which keeps the
+// metadata of the object. It is only supported if both buckets are on the same endpoint with the same credentials.
+func (s *s3Handler) copyBucketObject(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error) {
+	srcBucket, ok := src.(*s3Handler)
+	if !ok || !s.sameEndpoint(srcBucket.bucketConfig) {
+		return nil, fmt.Errorf("%w: server-side copy from %s (%s) to %s (%s)", core.ErrNotSupported,
+			src.Type(), src.Info(), s.Type(), s.Info())
+	}
+
+	srcObj, err := srcBucket.statBucketObject(ctx, srcKey)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucketConfig.Bucket, Object: destKey},
+		minio.CopySrcOptions{Bucket: srcBucket.bucketConfig.Bucket, Object: srcKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy object %s/%s to %s/%s: %w", srcBucket.bucketConfig.Bucket, srcKey,
+			s.bucketConfig.Bucket, destKey, err)
+	}
+
+	obj, err := s.statBucketObject(ctx, destKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// ETag of multipart uploads is not the MD5 of the content and may change on copy, so only the size is compared
+	if obj.Size != srcObj.Size || (!strings.Contains(srcObj.Checksum, "-") && obj.Checksum != srcObj.Checksum) {
+		return nil, fmt.Errorf("checksum mismatch after copy of %s: expected %s (%d bytes), got %s (%d bytes)",
+			srcKey, srcObj.Checksum, srcObj.Size, obj.Checksum, obj.Size)
+	}
+
+	logx.As().Debug().
+		Str("id", s.Info()).
+		Str("src_bucket", srcBucket.bucketConfig.Bucket).
+		Str("src_object", srcKey).
+		Str("bucket", s.bucketConfig.Bucket).
+		Str("object", destKey).
+		Msg("Copied object between buckets")
+
+	return obj, nil
+}
+
+// sameEndpoint returns true if the bucket is reachable with the client of the handler.
+func (s *s3Handler) sameEndpoint(other config.BucketConfig) bool {
+	return s.bucketConfig.Endpoint == other.Endpoint &&
+		s.bucketConfig.AccessKey == other.AccessKey &&
+		s.bucketConfig.SecretKey == other.SecretKey &&
+		s.bucketConfig.UseSSL == other.UseSSL
+}
+
+// newS3Client initializes a new S3 handler with the provided configuration and retry settings, without checking the
+// bucket.
+func newS3Client(id string, storageType string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (*s3Handler, error) {
 	if err := config.ValidateBucketConfig(bucketConfig); err != nil {
 		logx.As().Error().
 			Str("storage_type", storageType).
@@ -261,6 +467,22 @@ func newS3Handler(id string, storageType string, bucketConfig config.BucketConfi
 	}
 
 	s3.handler.syncFile = s3.syncWithBucket
+	s3.handler.statObject = s3.statBucketObject
+	s3.handler.listObjects = s3.listBucketObjects
+	s3.handler.getObject = s3.getBucketObject
+	s3.handler.deleteObject = s3.deleteBucketObject
+	s3.handler.copyObject = s3.copyBucketObject
+
+	return s3, nil
+}
+
+// newS3Handler initializes a new S3 handler with the provided configuration and retry settings, creating the bucket if
+// it does not exist.
+func newS3Handler(id string, storageType string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (*s3Handler, error) {
+	s3, err := newS3Client(id, storageType, bucketConfig, retryConfig, rootDir)
+	if err != nil {
+		return nil, err
+	}
 
 	// create bucket so that multiple goroutines do not compete to create the same bucket
 	// try up to 5 minutes rather than failing immediately, as S3 api (minio) may take some time to be ready in a k8s cluster
@@ -304,6 +526,35 @@ func newS3Handler(id string, storageType string, bucketConfig config.BucketConfi
 	return s3, nil
 }
 
+// checkBucketExists returns an error if the bucket does not exist, without creating it.
+func (s *s3Handler) checkBucketExists(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucketConfig.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket %s: %w", s.bucketConfig.Bucket, err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucketConfig.Bucket)
+	}
+
+	s.bucketExists[s.bucketConfig.Bucket] = true
+	return nil
+}
+
+// openS3Handler initializes a new S3 handler of an existing bucket. Unlike newS3Handler, it checks the bucket once
+// and never creates it.
+func openS3Handler(ctx context.Context, id string, storageType string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (*s3Handler, error) {
+	s3, err := newS3Client(id, storageType, bucketConfig, retryConfig, rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s3.checkBucketExists(ctx); err != nil {
+		return nil, err
+	}
+
+	return s3, nil
+}
+
 // NewS3 creates a new S3 storage handler.
 func NewS3(id string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
 	return newS3Handler(id, TypeS3, bucketConfig, retryConfig, rootDir)
@@ -313,3 +564,14 @@ func NewS3(id string, bucketConfig config.BucketConfig, retryConfig config.Retry
 func NewGCSWithS3(id string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
 	return newS3Handler(id, TypeGCS, bucketConfig, retryConfig, rootDir)
 }
+
+// OpenS3 creates a new S3 storage handler of an existing bucket, without creating it.
+func OpenS3(ctx context.Context, id string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
+	return openS3Handler(ctx, id, TypeS3, bucketConfig, retryConfig, rootDir)
+}
+
+// OpenGCSWithS3 creates a new GCS storage handler of an existing bucket using the S3-compatible API, without creating
+// it.
+func OpenGCSWithS3(ctx context.Context, id string, bucketConfig config.BucketConfig, retryConfig config.RetryConfig, rootDir string) (core.Storage, error) {
+	return openS3Handler(ctx, id, TypeGCS, bucketConfig, retryConfig, rootDir)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"crypto/md5"
 	"errors"
 	"fmt"
 	"github.com/minio/minio-go/v7"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
 	"golang.hedera.com/solo-cheetah/pkg/fsx"
 	"os"
 	"path/filepath"
@@ -40,6 +42,26 @@ func (m *mockS3Client) FPutObject(ctx context.Context, bucketName, objectName, f
 	return args.Get(0).(minio.UploadInfo), args.Error(1)
 }
 
+func (m *mockS3Client) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	args := m.Called(ctx, bucketName, opts)
+	return args.Get(0).(<-chan minio.ObjectInfo)
+}
+
+func (m *mockS3Client) FGetObject(ctx context.Context, bucketName, objectName, filePath string, opts minio.GetObjectOptions) error {
+	args := m.Called(ctx, bucketName, objectName, filePath, opts)
+	return args.Error(0)
+}
+
+func (m *mockS3Client) RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Error(0)
+}
+
+func (m *mockS3Client) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	args := m.Called(ctx, dst, src)
+	return args.Get(0).(minio.UploadInfo), args.Error(1)
+}
+
 func TestS3Handler_EnsureBucketExists(t *testing.T) {
 	tempDir := t.TempDir()
 	defer func() {
@@ -86,6 +108,31 @@ func TestS3Handler_EnsureBucketExists(t *testing.T) {
 	assert.False(t, h.bucketExists[bucketName])
 }
 
+func TestS3Handler_CheckBucketExists(t *testing.T) {
+	mockClient := new(mockS3Client)
+	bucketName := "test-bucket"
+	h := &s3Handler{
+		handler:      &handler{id: "s3-handler", storageType: TypeS3},
+		client:       mockClient,
+		bucketConfig: config.BucketConfig{Bucket: bucketName},
+		bucketExists: make(map[string]bool),
+	}
+
+	mockClient.On("BucketExists", mock.Anything, bucketName).Return(true, nil).Once()
+	require.NoError(t, h.checkBucketExists(context.Background()))
+	require.True(t, h.bucketExists[bucketName])
+
+	// a missing bucket is not created
+	mockClient.On("BucketExists", mock.Anything, bucketName).Return(false, nil).Once()
+	require.ErrorContains(t, h.checkBucketExists(context.Background()), "bucket test-bucket does not exist")
+
+	mockClient.On("BucketExists", mock.Anything, bucketName).Return(false, errors.New("connection refused")).Once()
+	require.ErrorContains(t, h.checkBucketExists(context.Background()), "connection refused")
+
+	mockClient.AssertNotCalled(t, "MakeBucket", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
 func TestS3Handler_SyncWithBucket(t *testing.T) {
 	tempDir := t.TempDir()
 	defer func() {
@@ -123,7 +170,7 @@ func TestS3Handler_SyncWithBucket(t *testing.T) {
 		ETag: localChecksum,
 		Key:  objectName,
 	}, nil).Once()
-	info, err := h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err := h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, info)
 	assert.Equal(t, objectName, info.Dest)
@@ -134,7 +181,7 @@ func TestS3Handler_SyncWithBucket(t *testing.T) {
 		ETag: localChecksum,
 		Key:  objectName,
 	}, nil).Once()
-	info, err = h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err = h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, info)
 	assert.Equal(t, objectName, info.Dest)
@@ -142,7 +189,7 @@ func TestS3Handler_SyncWithBucket(t *testing.T) {
 	// Test case: File upload fails
 	mockClient.On("StatObject", mock.Anything, bucketName, objectName, mock.Anything).Return(minio.ObjectInfo{}, fmt.Errorf("not found")).Once()
 	mockClient.On("FPutObject", mock.Anything, bucketName, objectName, srcFile, mock.Anything).Return(minio.UploadInfo{}, errors.New("upload failed")).Once()
-	info, err = h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err = h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.Error(t, err)
 	assert.Nil(t, info)
 
@@ -152,7 +199,7 @@ func TestS3Handler_SyncWithBucket(t *testing.T) {
 		ETag: "invalid",
 		Key:  objectName,
 	}, nil).Once()
-	info, err = h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err = h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.Error(t, err)
 	assert.Nil(t, info)
 }
@@ -197,7 +244,7 @@ func TestChecksumRecalculation(t *testing.T) {
 		Key:  objectName,
 	}, nil).Once()
 
-	info, err := h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err := h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, info)
 	assert.Equal(t, objectName, info.Dest)
@@ -207,7 +254,167 @@ func TestChecksumRecalculation(t *testing.T) {
 		ETag: "invalid-checksum", // Simulating a checksum mismatch
 		Key:  objectName,
 	}, nil).Once()
-	info, err = h.syncWithBucket(context.Background(), srcFile, objectName)
+	info, err = h.syncWithBucket(context.Background(), srcFile, objectName, nil)
 	assert.Error(t, err)
 	assert.Nil(t, info)
 }
+
+func TestS3Handler_ObjectOperations(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockS3Client)
+	bucketName := "test-bucket"
+	bucketConfig := config.BucketConfig{Bucket: bucketName, Prefix: "streams"}
+	h := &s3Handler{
+		handler: &handler{
+			id:          "s3-handler",
+			storageType: TypeS3,
+			pathPrefix:  bucketConfig.Prefix,
+			rootDir:     "/data",
+		},
+		client:       mockClient,
+		bucketConfig: bucketConfig,
+		retryConfig:  config.RetryConfig{Limit: 1},
+		bucketExists: make(map[string]bool),
+	}
+
+	// operations are not supported until they are set up
+	require.False(t, h.Supports(core.CapabilityList))
+	_, err := h.List(ctx, core.ListOptions{})
+	require.ErrorIs(t, err, core.ErrNotSupported)
+
+	h.handler.statObject = h.statBucketObject
+	h.handler.listObjects = h.listBucketObjects
+	h.handler.getObject = h.getBucketObject
+	h.handler.deleteObject = h.deleteBucketObject
+	require.True(t, h.Supports(core.CapabilityList))
+
+	require.Equal(t, "streams/record0.0.3/file.rcd.gz", h.Key("/data/record0.0.3/file.rcd.gz"))
+
+	// the first page is truncated; listing stops once the page is full
+	objects := make(chan minio.ObjectInfo, 3)
+	objects <- minio.ObjectInfo{Key: "streams/record0.0.3/a.rcd.gz", ETag: "aa", Size: 10}
+	objects <- minio.ObjectInfo{Key: "streams/record0.0.3/b.rcd.gz", ETag: "bb", Size: 20}
+	objects <- minio.ObjectInfo{Key: "streams/record0.0.3/c.rcd.gz", ETag: "cc", Size: 30}
+	close(objects)
+	mockClient.On("ListObjects", mock.Anything, bucketName,
+		minio.ListObjectsOptions{Prefix: "streams/", MaxKeys: 2, Recursive: true}).
+		Return((<-chan minio.ObjectInfo)(objects)).Once()
+
+	page, err := h.List(ctx, core.ListOptions{MaxKeys: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Objects, 2)
+	require.True(t, page.Truncated)
+	require.Equal(t, "streams/record0.0.3/b.rcd.gz", page.NextStartAfter)
+	require.Equal(t, "aa", page.Objects[0].Checksum)
+
+	last := make(chan minio.ObjectInfo, 1)
+	last <- minio.ObjectInfo{Key: "streams/record0.0.3/c.rcd.gz", ETag: "cc", Size: 30}
+	close(last)
+	mockClient.On("ListObjects", mock.Anything, bucketName,
+		minio.ListObjectsOptions{Prefix: "streams/", StartAfter: page.NextStartAfter, MaxKeys: 2, Recursive: true}).
+		Return((<-chan minio.ObjectInfo)(last)).Once()
+
+	page, err = h.List(ctx, core.ListOptions{MaxKeys: 2, StartAfter: page.NextStartAfter})
+	require.NoError(t, err)
+	require.Len(t, page.Objects, 1)
+	require.False(t, page.Truncated)
+
+	// listing error
+	failed := make(chan minio.ObjectInfo, 1)
+	failed <- minio.ObjectInfo{Err: errors.New("access denied")}
+	close(failed)
+	mockClient.On("ListObjects", mock.Anything, bucketName, mock.Anything).Return((<-chan minio.ObjectInfo)(failed)).Once()
+	_, err = h.List(ctx, core.ListOptions{Prefix: "other/"})
+	require.Error(t, err)
+
+	// stat
+	content := []byte("test content")
+	checksum := fmt.Sprintf("%x", md5.Sum(content))
+	mockClient.On("StatObject", mock.Anything, bucketName, "streams/a", mock.Anything).
+		Return(minio.ObjectInfo{Key: "streams/a", ETag: checksum, Size: 12}, nil)
+	obj, err := h.Stat(ctx, "streams/a")
+	require.NoError(t, err)
+	require.Equal(t, checksum, obj.Checksum)
+
+	mockClient.On("StatObject", mock.Anything, bucketName, "streams/missing", mock.Anything).
+		Return(minio.ObjectInfo{}, minio.ErrorResponse{Code: "NoSuchKey"})
+	_, err = h.Stat(ctx, "streams/missing")
+	require.ErrorIs(t, err, core.ErrObjectNotFound)
+
+	// get verifies the checksum of the downloaded file
+	dest := filepath.Join(t.TempDir(), "a")
+	mockClient.On("FGetObject", mock.Anything, bucketName, "streams/a", dest, mock.Anything).
+		Run(func(args mock.Arguments) {
+			require.NoError(t, os.WriteFile(dest, content, 0644))
+		}).Return(nil).Once()
+	obj, err = h.Get(ctx, "streams/a", dest)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), obj.Size)
+
+	mockClient.On("FGetObject", mock.Anything, bucketName, "streams/a", dest, mock.Anything).
+		Run(func(args mock.Arguments) {
+			require.NoError(t, os.WriteFile(dest, []byte("corrupted"), 0644))
+		}).Return(nil).Once()
+	_, err = h.Get(ctx, "streams/a", dest)
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	// delete
+	mockClient.On("RemoveObject", mock.Anything, bucketName, "streams/a", mock.Anything).Return(nil).Once()
+	require.NoError(t, h.Delete(ctx, "streams/a"))
+	require.ErrorIs(t, h.Delete(ctx, "streams/missing"), core.ErrObjectNotFound)
+	mockClient.AssertExpectations(t)
+}
+
+func TestS3Handler_Copy(t *testing.T) {
+	ctx := context.Background()
+	newHandler := func(client s3Client, bucketConfig config.BucketConfig) *s3Handler {
+		h := &s3Handler{
+			handler:      &handler{id: "s3-" + bucketConfig.Bucket, storageType: TypeS3, pathPrefix: bucketConfig.Prefix},
+			client:       client,
+			bucketConfig: bucketConfig,
+			bucketExists: make(map[string]bool),
+		}
+		h.handler.statObject = h.statBucketObject
+		h.handler.copyObject = h.copyBucketObject
+		return h
+	}
+
+	srcClient := new(mockS3Client)
+	destClient := new(mockS3Client)
+	src := newHandler(srcClient, config.BucketConfig{Bucket: "src", Endpoint: "minio:9000", AccessKey: "key"})
+	dest := newHandler(destClient, config.BucketConfig{Bucket: "dest", Endpoint: "minio:9000", AccessKey: "key"})
+	require.True(t, dest.Supports(core.CapabilityCopy))
+
+	srcClient.On("StatObject", mock.Anything, "src", "a", mock.Anything).
+		Return(minio.ObjectInfo{Key: "a", ETag: "aa", Size: 10}, nil)
+	destClient.On("CopyObject", mock.Anything,
+		minio.CopyDestOptions{Bucket: "dest", Object: "b/a"}, minio.CopySrcOptions{Bucket: "src", Object: "a"}).
+		Return(minio.UploadInfo{}, nil).Once()
+	destClient.On("StatObject", mock.Anything, "dest", "b/a", mock.Anything).
+		Return(minio.ObjectInfo{Key: "b/a", ETag: "aa", Size: 10}, nil).Once()
+
+	obj, err := dest.Copy(ctx, src, "a", "/b/a")
+	require.NoError(t, err)
+	require.Equal(t, "b/a", obj.Key)
+	require.Equal(t, "aa", obj.Checksum)
+
+	// the copy is verified against the source checksum
+	destClient.On("CopyObject", mock.Anything, mock.Anything, mock.Anything).Return(minio.UploadInfo{}, nil).Once()
+	destClient.On("StatObject", mock.Anything, "dest", "b/a", mock.Anything).
+		Return(minio.ObjectInfo{Key: "b/a", ETag: "bb", Size: 10}, nil).Once()
+	_, err = dest.Copy(ctx, src, "a", "b/a")
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	// buckets on another endpoint or of another storage type can't be copied server-side
+	other := newHandler(new(mockS3Client), config.BucketConfig{Bucket: "other", Endpoint: "storage.googleapis.com", AccessKey: "key"})
+	_, err = dest.Copy(ctx, other, "a", "a")
+	require.ErrorIs(t, err, core.ErrNotSupported)
+
+	dir, err := NewLocalDir("dir", config.LocalDirConfig{Path: t.TempDir(), Mode: 0755}, config.RetryConfig{}, "/data")
+	require.NoError(t, err)
+	_, err = dest.Copy(ctx, dir, "a", "a")
+	require.ErrorIs(t, err, core.ErrNotSupported)
+
+	srcClient.AssertExpectations(t)
+	destClient.AssertExpectations(t)
+}
@@ -0,0 +1,166 @@
+package summary
+
+import (
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Failure describes a marker file that failed to be uploaded to a storage.
+type Failure struct {
+	Marker  string `json:"marker"`
+	TraceId string `json:"trace_id,omitempty"`
+	Storage string `json:"storage,omitempty"`
+	Error   string `json:"error"`
+}
+
+// PipelineReport holds the counts of a single pipeline run.
+type PipelineReport struct {
+	Name             string    `json:"name"`
+	Markers          int       `json:"markers"`
+	Succeeded        int       `json:"succeeded"`
+	Failed           int       `json:"failed"`
+	Uploaded         int       `json:"uploaded"`
+	Skipped          int       `json:"skipped"`
+	Bytes            int64     `json:"bytes"`
+	UploadDurationMs int64     `json:"upload_duration_ms"`
+	DurationMs       int64     `json:"duration_ms"`
+	Error            string    `json:"error,omitempty"`
+	Failures         []Failure `json:"failures,omitempty"`
+}
+
+// OK returns true if the pipeline stopped without error and no marker failed.
+func (p PipelineReport) OK() bool {
+	return p.Error == "" && p.Failed == 0
+}
+
+// Report is the summary of a run of the pipelines.
+type Report struct {
+	Started    time.Time        `json:"started"`
+	Finished   time.Time        `json:"finished"`
+	DurationMs int64            `json:"duration_ms"`
+	OK         bool             `json:"ok"`
+	Pipelines  []PipelineReport `json:"pipelines"`
+}
+
+// Collector collects the results of the processed marker files per pipeline. It implements core.Recorder.
+type Collector struct {
+	mu        sync.Mutex
+	started   time.Time
+	pipelines map[string]*PipelineReport
+}
+
+// NewCollector creates a collector for a run starting now.
+func NewCollector() *Collector {
+	return &Collector{
+		started:   time.Now(),
+		pipelines: map[string]*PipelineReport{},
+	}
+}
+
+// pipeline returns the report of the pipeline, creating it if needed. The caller must hold the lock.
+func (c *Collector) pipeline(name string) *PipelineReport {
+	p, exists := c.pipelines[name]
+	if !exists {
+		p = &PipelineReport{Name: name}
+		c.pipelines[name] = p
+	}
+	return p
+}
+
+// Record counts the marker file and its uploads in the report of its pipeline.
+func (c *Collector) Record(result core.ProcessorResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.pipeline(result.Pipeline)
+	p.Markers++
+
+	storages := make([]string, 0, len(result.Result))
+	for storageType := range result.Result {
+		storages = append(storages, storageType)
+	}
+	sort.Strings(storages)
+
+	var failures []Failure
+	for _, storageType := range storages {
+		sr := result.Result[storageType]
+		if sr == nil {
+			continue
+		}
+
+		if sr.Error != nil {
+			failures = append(failures, Failure{
+				Marker:  result.Path,
+				TraceId: result.TraceId,
+				Storage: storageType,
+				Error:   sr.Error.Error(),
+			})
+		}
+
+		for _, u := range sr.UploadResults {
+			if u.Skipped {
+				p.Skipped++
+				continue
+			}
+
+			p.Uploaded++
+			p.Bytes += u.Size
+			p.UploadDurationMs += u.Duration.Milliseconds()
+		}
+	}
+
+	if result.Error != nil && len(failures) == 0 {
+		failures = append(failures, Failure{Marker: result.Path, TraceId: result.TraceId, Error: result.Error.Error()})
+	}
+
+	if result.Error != nil || len(failures) > 0 {
+		p.Failed++
+		p.Failures = append(p.Failures, failures...)
+	} else {
+		p.Succeeded++
+	}
+
+	return nil
+}
+
+// PipelineStopped records the duration of a pipeline and the error it stopped with, if any.
+func (c *Collector) PipelineStopped(name string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.pipeline(name)
+	p.DurationMs = duration.Milliseconds()
+	if err != nil {
+		p.Error = err.Error()
+	}
+}
+
+// Report returns the summary of the run, with the pipelines sorted by name.
+func (c *Collector) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	finished := time.Now()
+	r := Report{
+		Started:    c.started,
+		Finished:   finished,
+		DurationMs: finished.Sub(c.started).Milliseconds(),
+		OK:         true,
+		Pipelines:  make([]PipelineReport, 0, len(c.pipelines)),
+	}
+
+	for _, p := range c.pipelines {
+		pr := *p
+		pr.Failures = append([]Failure(nil), p.Failures...)
+		r.Pipelines = append(r.Pipelines, pr)
+		r.OK = r.OK && pr.OK()
+	}
+
+	sort.Slice(r.Pipelines, func(i, j int) bool {
+		return r.Pipelines[i].Name < r.Pipelines[j].Name
+	})
+
+	return r
+}
@@ -0,0 +1,77 @@
+package summary
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+
+	require.NoError(t, c.Record(core.ProcessorResult{
+		Path:     "a.rcd_sig",
+		TraceId:  "trace-a",
+		Pipeline: "records",
+		Result: map[string]*core.StorageResult{
+			"S3": {UploadResults: []*core.UploadInfo{
+				{Src: "a.rcd_sig", Size: 10, Duration: 20 * time.Millisecond},
+				{Src: "a.rcd.gz", Size: 100, Duration: 30 * time.Millisecond},
+			}},
+			"GCS": {UploadResults: []*core.UploadInfo{
+				{Src: "a.rcd_sig", Size: 10, Skipped: true},
+			}},
+		},
+	}))
+	require.NoError(t, c.Record(core.ProcessorResult{
+		Path:     "b.rcd_sig",
+		TraceId:  "trace-b",
+		Pipeline: "records",
+		Error:    errors.New("failed to upload"),
+		Result: map[string]*core.StorageResult{
+			"S3":  {UploadResults: []*core.UploadInfo{{Src: "b.rcd_sig", Size: 5}}},
+			"GCS": {Error: errors.New("access denied")},
+		},
+	}))
+	require.NoError(t, c.Record(core.ProcessorResult{
+		Path:     "c.evts_sig",
+		Pipeline: "events",
+		Error:    errors.New("marker not ready"),
+	}))
+
+	c.PipelineStopped("records", 2*time.Second, nil)
+	c.PipelineStopped("events", time.Second, errors.New("pipeline 'events' encountered error"))
+	c.PipelineStopped("blocks", time.Millisecond, nil)
+
+	r := c.Report()
+	require.False(t, r.OK)
+	require.False(t, r.Finished.Before(r.Started))
+	require.Len(t, r.Pipelines, 3)
+
+	require.Equal(t, PipelineReport{Name: "blocks", DurationMs: 1}, r.Pipelines[0])
+	require.True(t, r.Pipelines[0].OK())
+
+	require.Equal(t, PipelineReport{
+		Name:       "events",
+		Markers:    1,
+		Failed:     1,
+		DurationMs: 1000,
+		Error:      "pipeline 'events' encountered error",
+		Failures:   []Failure{{Marker: "c.evts_sig", Error: "marker not ready"}},
+	}, r.Pipelines[1])
+
+	require.Equal(t, PipelineReport{
+		Name:             "records",
+		Markers:          2,
+		Succeeded:        1,
+		Failed:           1,
+		Uploaded:         3,
+		Skipped:          1,
+		Bytes:            115,
+		UploadDurationMs: 50,
+		DurationMs:       2000,
+		Failures:         []Failure{{Marker: "b.rcd_sig", TraceId: "trace-b", Storage: "GCS", Error: "access denied"}},
+	}, r.Pipelines[2])
+}
@@ -0,0 +1,283 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// InstrumentationName is the name of the tracer used by cheetah components.
+const InstrumentationName = "golang.hedera.com/solo-cheetah"
+
+// DefaultServiceName is the service name reported in traces if none is configured.
+const DefaultServiceName = "solo-cheetah"
+
+// Supported span exporters.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Attribute keys used on cheetah spans.
+const (
+	AttrTraceId  = attribute.Key("cheetah.trace_id")
+	AttrPipeline = attribute.Key("cheetah.pipeline")
+	AttrMarker   = attribute.Key("cheetah.marker")
+	AttrStorage  = attribute.Key("cheetah.storage")
+	AttrHandler  = attribute.Key("cheetah.handler")
+	AttrSrc      = attribute.Key("cheetah.src")
+	AttrDest     = attribute.Key("cheetah.dest")
+)
+
+// TracingConfig holds the configuration for OpenTelemetry tracing.
+type TracingConfig struct {
+	// Enabled indicates whether tracing is enabled.
+	Enabled bool
+	// Exporter is the span exporter to use: otlp, stdout or file.
+	Exporter string
+	// Endpoint is the OTLP HTTP endpoint (e.g. "localhost:4318"). Used by the otlp exporter.
+	Endpoint string
+	// Insecure disables TLS for the OTLP exporter.
+	Insecure bool
+	// File is the path of the file where spans are written as JSON. Used by the file exporter.
+	File string
+	// ServiceName is the service name reported in traces. Default is solo-cheetah.
+	ServiceName string
+	// SampleRatio is the ratio of markers to be traced, between 0 and 1. Default (0) traces all markers.
+	SampleRatio float64
+}
+
+var (
+	mu          sync.Mutex
+	provider    *sdktrace.TracerProvider
+	output      io.Closer
+	sampleRatio = 1.0
+)
+
+// Start initializes the global tracer provider using the configured exporter.
+// If tracing is disabled, the default no-op tracer provider is left in place.
+func Start(ctx context.Context, cfg TracingConfig) error {
+	if !cfg.Enabled {
+		logx.As().Debug().Msg("Tracing is disabled")
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider != nil {
+		return fmt.Errorf("tracing is already started")
+	}
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	sampleRatio = 1.0
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampleRatio = cfg.SampleRatio
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	output = closer
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	logx.As().Info().
+		Str("exporter", cfg.Exporter).
+		Str("endpoint", cfg.Endpoint).
+		Str("file", cfg.File).
+		Str("service_name", serviceName).
+		Float64("sample_ratio", sampleRatio).
+		Msg("Tracing started")
+
+	return nil
+}
+
+// Stop flushes any pending spans and shuts down the tracer provider.
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		logx.As().Error().Err(err).Msg("Failed to shut down tracer provider")
+	}
+
+	if output != nil {
+		if err := output.Close(); err != nil {
+			logx.As().Error().Err(err).Msg("Failed to close tracing output")
+		}
+	}
+
+	provider = nil
+	output = nil
+	sampleRatio = 1.0
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+// newExporter creates the span exporter for the configuration. The returned io.Closer, if not nil, must be closed
+// after the exporter is shut down.
+func newExporter(ctx context.Context, cfg TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterStdout, "":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterFile:
+		if cfg.File == "" {
+			return nil, nil, fmt.Errorf("missing File in tracing configuration")
+		}
+
+		if err := os.MkdirAll(filepath.Dir(cfg.File), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create tracing output directory: %w", err)
+		}
+
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open tracing output file: %w", err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			fsx.CloseFile(f)
+			return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter '%s'", cfg.Exporter)
+	}
+}
+
+// Tracer returns the tracer used by cheetah components.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// ContextWithMarker returns a context carrying a remote parent span context derived from the marker trace ID, so
+// that spans started from the returned context belong to the trace of the marker. The trace ID is deterministic, which
+// allows spans created in different goroutines (e.g. upload and removal) to be correlated without passing the span
+// context along with the marker.
+func ContextWithMarker(ctx context.Context, traceId string) context.Context {
+	if traceId == "" {
+		return ctx
+	}
+
+	sum := sha256.Sum256([]byte(traceId))
+	var tid trace.TraceID
+	var sid trace.SpanID
+	copy(tid[:], sum[:16])
+	copy(sid[:], sum[16:24])
+
+	var flags trace.TraceFlags
+	if isSampled(tid) {
+		flags = trace.FlagsSampled
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// isSampled applies the same decision as sdktrace.TraceIDRatioBased so that the sampling of a marker trace is
+// deterministic across all of its spans.
+func isSampled(tid trace.TraceID) bool {
+	mu.Lock()
+	ratio := sampleRatio
+	mu.Unlock()
+
+	if ratio >= 1 {
+		return true
+	}
+
+	bound := uint64(ratio * (1 << 63))
+	x := binary.BigEndian.Uint64(tid[8:16]) >> 1
+	return x < bound
+}
+
+// StartSpan starts a span with the cheetah tracer.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartMarkerSpan starts a span within the trace of the marker identified by the trace ID.
+func StartMarkerSpan(ctx context.Context, name string, traceId string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = ContextWithMarker(ctx, traceId)
+	attrs = append(attrs, AttrTraceId.String(traceId))
+	return StartSpan(ctx, name, attrs...)
+}
+
+// EndSpan records the error, if any, and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// markerTraceID returns the trace ID that ContextWithMarker derives from a marker trace ID.
+func markerTraceID(traceId string) trace.TraceID {
+	return trace.SpanContextFromContext(ContextWithMarker(context.Background(), traceId)).TraceID()
+}
+
+func TestStartMarkerSpan_SharesTraceId(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+	defer func() {
+		_ = tp.Shutdown(context.Background())
+	}()
+
+	traceId := "scanner-test-0001-0b0e4f6e-8d62-4b4a-9d57-3f7a0a1b2c3d"
+
+	// spans started from independent contexts must belong to the same trace
+	_, upload := StartMarkerSpan(context.Background(), "processor.upload", traceId)
+	_, remove := StartMarkerSpan(context.Background(), "processor.remove", traceId)
+	EndSpan(upload, nil)
+	EndSpan(remove, errors.New("failed to remove"))
+
+	_, other := StartMarkerSpan(context.Background(), "processor.upload", "another-trace-id")
+	EndSpan(other, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, markerTraceID(traceId), spans[0].SpanContext().TraceID())
+	require.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	require.NotEqual(t, spans[0].SpanContext().TraceID(), spans[2].SpanContext().TraceID())
+	require.Equal(t, spans[0].Parent().SpanID(), spans[1].Parent().SpanID())
+
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+
+	found := false
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == AttrTraceId {
+			found = true
+			require.Equal(t, traceId, attr.Value.AsString())
+		}
+	}
+	require.True(t, found)
+}
+
+func TestContextWithMarker_EmptyTraceId(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ctx, ContextWithMarker(ctx, ""))
+}
+
+func TestStart_Disabled(t *testing.T) {
+	require.NoError(t, Start(context.Background(), TracingConfig{Enabled: false}))
+	Stop() // no-op
+}
+
+func TestStart_InvalidExporter(t *testing.T) {
+	err := Start(context.Background(), TracingConfig{Enabled: true, Exporter: "invalid"})
+	require.Error(t, err)
+
+	err = Start(context.Background(), TracingConfig{Enabled: true, Exporter: ExporterFile})
+	require.Error(t, err)
+}
+
+func TestStart_FileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces", "spans.json")
+	require.NoError(t, Start(context.Background(), TracingConfig{
+		Enabled:  true,
+		Exporter: ExporterFile,
+		File:     file,
+	}))
+
+	// a second start should fail
+	require.Error(t, Start(context.Background(), TracingConfig{Enabled: true, Exporter: ExporterFile, File: file}))
+
+	_, span := StartMarkerSpan(context.Background(), "storage.put", "file-trace-id", AttrStorage.String("S3"))
+	EndSpan(span, nil)
+	Stop() // flushes spans to the file
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	line := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)[0]
+	require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+	require.Equal(t, "storage.put", decoded["Name"])
+	require.Contains(t, string(data), markerTraceID("file-trace-id").String())
+}
+
+func TestIsSampled(t *testing.T) {
+	sampleRatio = 0.5
+	defer func() {
+		sampleRatio = 1.0
+	}()
+
+	sampled := 0
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p"} {
+		tid := markerTraceID(id)
+		// the decision must be deterministic for a trace ID
+		require.Equal(t, isSampled(tid), isSampled(tid))
+		if isSampled(tid) {
+			sampled++
+		}
+	}
+	require.Greater(t, sampled, 0)
+	require.Less(t, sampled, 16)
+}
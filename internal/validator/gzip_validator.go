@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ValidatorGzip = "gzip"
+
+// gzipValidator verifies the integrity of gzip compressed files by decompressing them entirely, which checks the
+// CRC-32 and size recorded at the end of the file, so that truncated files are rejected.
+type gzipValidator struct {
+	*defaultValidator
+}
+
+func (gv *gzipValidator) Validate(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid gzip header: %w", err)
+	}
+	defer zr.Close()
+
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return fmt.Errorf("corrupted gzip data: %w", err)
+	}
+
+	return nil
+}
+
+func NewGzipValidator() Validator {
+	return &gzipValidator{
+		defaultValidator: &defaultValidator{
+			validatorType: ValidatorGzip,
+			patterns:      []string{".gz"},
+		},
+	}
+}
@@ -0,0 +1,13 @@
+package validator
+
+import "sync"
+
+var registerOnce sync.Once
+
+func init() {
+	registerOnce.Do(func() {
+		RegisterValidator(NewGzipValidator())
+		RegisterValidator(NewRecordStreamValidator())
+		RegisterValidator(NewEventStreamValidator())
+	})
+}
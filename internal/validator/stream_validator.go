@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"compress/gzip"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	ValidatorRecordStream = "recordStream"
+	ValidatorEventStream  = "eventStream"
+)
+
+// streamValidator parses the header of record or event stream files, decompressing them first if their name ends
+// with .gz, and rejects the files whose header cannot be read. Sidecar record files have no such header and are not
+// validated.
+type streamValidator struct {
+	*defaultValidator
+	readHeader func(r io.Reader) (*hedera.StreamHeader, error)
+}
+
+func (sv *streamValidator) Validate(path string) error {
+	if hedera.IsSidecarFile(path) {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("invalid gzip header: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	header, err := sv.readHeader(r)
+	if err != nil {
+		return fmt.Errorf("invalid stream file header: %w", err)
+	}
+
+	logx.As().Trace().
+		Str("validator", sv.Type()).
+		Str("path", path).
+		Int32("version", header.Version).
+		Str("hapi_version", header.HapiVersion.String()).
+		Hex("start_running_hash", header.StartRunningHash).
+		Msg("Parsed stream file header")
+
+	return nil
+}
+
+func NewRecordStreamValidator() Validator {
+	return &streamValidator{
+		defaultValidator: &defaultValidator{
+			validatorType: ValidatorRecordStream,
+			patterns:      []string{".rcd", ".rcd.gz"},
+		},
+		readHeader: hedera.ReadRecordStreamHeader,
+	}
+}
+
+func NewEventStreamValidator() Validator {
+	return &streamValidator{
+		defaultValidator: &defaultValidator{
+			validatorType: ValidatorEventStream,
+			patterns:      []string{".evts", ".evts.gz"},
+		},
+		readHeader: hedera.ReadEventStreamHeader,
+	}
+}
@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"fmt"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"strings"
+)
+
+// Validator validates a candidate file before it is uploaded.
+//
+// Methods:
+//   - Type: Returns the type of the validator used in the configuration.
+//   - Patterns: Returns the default file name suffixes of the files the validator applies to.
+//   - Validate: Returns an error if the file is invalid, e.g. truncated or corrupted.
+type Validator interface {
+	Type() string
+	Patterns() []string
+	Validate(path string) error
+}
+
+var validators map[string]Validator
+
+// RegisterValidator registers a validator so that it can be referenced by its type in the configuration.
+func RegisterValidator(v Validator) {
+	if validators == nil {
+		validators = map[string]Validator{}
+	}
+
+	validators[v.Type()] = v
+}
+
+// GetValidator returns the validator registered with the type.
+func GetValidator(validatorType string) (Validator, error) {
+	if v, ok := validators[validatorType]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("validator %s not found", validatorType)
+}
+
+// ValidationError reports the candidate files of a marker file that failed validation.
+type ValidationError struct {
+	Marker string
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("candidate files of marker %s failed validation: %s", e.Marker, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// ValidateCandidates validates the candidate files of the marker with all the validator configurations.
+// Every candidate is validated, and the failures are reported together in a ValidationError.
+func ValidateCandidates(marker string, candidates []string, configs []config.ValidatorConfig) error {
+	var errs []error
+	for _, vc := range configs {
+		v, err := GetValidator(vc.ValidatorType)
+		if err != nil {
+			return fmt.Errorf("unknown validator type: %s", vc.ValidatorType)
+		}
+
+		patterns := vc.Patterns
+		if len(patterns) == 0 {
+			patterns = v.Patterns()
+		}
+
+		for _, candidate := range candidates {
+			if !hasSuffix(candidate, patterns) {
+				continue
+			}
+
+			if err := v.Validate(candidate); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s validation failed: %w", candidate, v.Type(), err))
+				continue
+			}
+
+			logx.As().Debug().
+				Str("marker", marker).
+				Str("validator", v.Type()).
+				Str("candidate_file", candidate).
+				Msg("Candidate file is valid")
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Marker: marker, Errors: errs}
+	}
+
+	return nil
+}
+
+func hasSuffix(path string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+type defaultValidator struct {
+	validatorType string
+	patterns      []string
+}
+
+func (dv *defaultValidator) Type() string {
+	return dv.validatorType
+}
+
+func (dv *defaultValidator) Patterns() []string {
+	return dv.patterns
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func writeFile(t *testing.T, dir string, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestValidators(t *testing.T) {
+	tempDir := t.TempDir()
+	header := hederatest.RecordFileV5(hederatest.Hash(7), nil)
+	record := gzipped(t, header)
+
+	tests := []struct {
+		name      string
+		validator Validator
+		file      string
+		data      []byte
+		errMsg    string
+	}{
+		{name: "valid gzip", validator: NewGzipValidator(), file: "a.rcd.gz", data: record},
+		{name: "truncated gzip", validator: NewGzipValidator(), file: "b.rcd.gz", data: record[:len(record)-4], errMsg: "corrupted gzip data"},
+		{name: "not gzip", validator: NewGzipValidator(), file: "c.rcd.gz", data: []byte("plain"), errMsg: "invalid gzip header"},
+		{name: "valid record file", validator: NewRecordStreamValidator(), file: "d.rcd.gz", data: record},
+		{name: "valid uncompressed record file", validator: NewRecordStreamValidator(), file: "e.rcd", data: header},
+		{name: "truncated record file", validator: NewRecordStreamValidator(), file: "f.rcd", data: header[:30], errMsg: "invalid stream file header"},
+		{name: "record file as event file", validator: NewEventStreamValidator(), file: "g.evts", data: header, errMsg: "invalid stream file header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Validate(writeFile(t, tempDir, tt.file, tt.data))
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateCandidates(t *testing.T) {
+	tempDir := t.TempDir()
+	header := hederatest.RecordFileV5(hederatest.Hash(7), nil)
+	marker := writeFile(t, tempDir, "a.rcd_sig", []byte("signature"))
+	valid := writeFile(t, tempDir, "a.rcd.gz", gzipped(t, header))
+	truncated := writeFile(t, tempDir, "b.rcd.gz", gzipped(t, header[:30]))
+	sidecar := writeFile(t, tempDir, "2024-01-01T00_00_00.000000000Z_01.rcd.gz", []byte("not gzip"))
+
+	configs := []config.ValidatorConfig{
+		{ValidatorType: ValidatorGzip, Patterns: []string{".rcd.gz"}},
+		{ValidatorType: ValidatorRecordStream},
+	}
+
+	require.NoError(t, ValidateCandidates(marker, []string{marker, valid}, configs))
+
+	err := ValidateCandidates(marker, []string{marker, valid, truncated, sidecar}, configs)
+	require.ErrorContains(t, err, "candidate files of marker "+marker+" failed validation")
+
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 2)
+	require.ErrorContains(t, ve.Errors[0], sidecar+": gzip validation failed")
+	require.ErrorContains(t, ve.Errors[1], truncated+": recordStream validation failed")
+
+	_, err = GetValidator("unknown")
+	require.Error(t, err)
+	require.ErrorContains(t, ValidateCandidates(marker, []string{valid}, []config.ValidatorConfig{{ValidatorType: "unknown"}}),
+		"unknown validator type")
+}
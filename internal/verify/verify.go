@@ -0,0 +1,528 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/pkg/fsx"
+	"golang.hedera.com/solo-cheetah/pkg/logx"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMinAge is the default minimum age of local files to be verified. Younger files are likely still being
+// uploaded by a running pipeline.
+const DefaultMinAge = 5 * time.Minute
+
+// Options holds the options for verifying the storages of a pipeline.
+type Options struct {
+	// Pipeline is the name of the pipeline being verified. Only journal entries of the pipeline are considered.
+	Pipeline string
+	// RootDir is the directory scanned by the pipeline.
+	RootDir string
+	// Markers are the marker patterns of the scanner with the matchers used to find the files uploaded for each marker.
+	Markers matcher.MarkerPatterns
+	// Entries are the past uploads recorded in the upload journal.
+	Entries []journal.Entry
+	// MinAge is the minimum age of the local files to be verified.
+	MinAge time.Duration
+	// Repair re-uploads missing and mismatched objects from the surviving local copies, or copies them from another
+	// storage of the pipeline that has the object with the expected checksum.
+	Repair bool
+	// Recorder, if set, records the results of the repairs (e.g. upload journal).
+	Recorder core.Recorder
+}
+
+// Finding describes an object that is missing, unexpected or has a different checksum in a storage.
+type Finding struct {
+	Key              string `json:"key"`
+	Src              string `json:"src,omitempty"`
+	ExpectedChecksum string `json:"expected_checksum,omitempty"`
+	ActualChecksum   string `json:"actual_checksum,omitempty"`
+	Repaired         bool   `json:"repaired,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// Report is the result of verifying a single storage of a pipeline.
+type Report struct {
+	Pipeline   string    `json:"pipeline"`
+	Storage    string    `json:"storage"`
+	Handler    string    `json:"handler"`
+	Expected   int       `json:"expected"`
+	Remote     int       `json:"remote"`
+	Verified   int       `json:"verified"`
+	Missing    []Finding `json:"missing,omitempty"`
+	Extra      []Finding `json:"extra,omitempty"`
+	Mismatched []Finding `json:"mismatched,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// OK returns true if the storage has all the expected objects with the right checksums, or the discrepancies were
+// repaired. Extra objects are reported but are not considered a failure since the journal may have been pruned.
+func (r Report) OK() bool {
+	if r.Error != "" {
+		return false
+	}
+
+	for _, findings := range [][]Finding{r.Missing, r.Mismatched} {
+		for _, f := range findings {
+			if !f.Repaired {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// expectedObject is an object that is expected to exist in a storage.
+type expectedObject struct {
+	key      string
+	src      string
+	checksum string // md5 checksum; empty if unknown
+}
+
+// Run verifies each storage against the expected objects derived from the remaining local files and the journal.
+// Storages that do not support listing objects are reported with an error.
+func Run(ctx context.Context, storages []core.Storage, opts Options) ([]Report, error) {
+	localFiles, err := collectLocalFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+	journaled := journaledObjects(opts)
+
+	var reports []Report
+	for _, s := range storages {
+		report := Report{Pipeline: opts.Pipeline, Storage: s.Type(), Handler: s.Info()}
+
+		if !s.Supports(core.CapabilityList) {
+			report.Error = fmt.Sprintf("%s does not support listing objects", s.Type())
+			reports = append(reports, report)
+			continue
+		}
+
+		expected, err := expectedObjects(s, localFiles, journaled[s.Type()])
+		if err != nil {
+			return nil, err
+		}
+
+		objects, err := core.ListAll(ctx, s, "")
+		if err != nil {
+			report.Error = err.Error()
+			reports = append(reports, report)
+			continue
+		}
+
+		compare(&report, expected, objects)
+
+		if opts.Repair {
+			repair(ctx, s, peers(storages, s), journaled, &report, opts)
+		}
+
+		logx.As().Info().
+			Str("pipeline", report.Pipeline).
+			Str("storage_type", report.Storage).
+			Str("id", report.Handler).
+			Int("expected", report.Expected).
+			Int("remote", report.Remote).
+			Int("verified", report.Verified).
+			Int("missing", len(report.Missing)).
+			Int("extra", len(report.Extra)).
+			Int("mismatched", len(report.Mismatched)).
+			Msg("Verified storage")
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// collectLocalFiles finds the markers in the root directory and returns the files uploaded for them that are older
+// than the minimum age. Each marker's own candidates are determined by the file matchers of its marker pattern.
+func collectLocalFiles(opts Options) ([]string, error) {
+	if opts.RootDir == "" {
+		return nil, nil
+	}
+
+	if _, exists := fsx.PathExists(opts.RootDir); !exists {
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-opts.MinAge)
+
+	markers := map[string]*matcher.MarkerPattern{}
+	err := filepath.Walk(opts.RootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(opts.RootDir, path)
+		if err != nil {
+			return nil
+		}
+		if m := opts.Markers.Match(rel); m != nil {
+			markers[path] = m
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory %s: %w", opts.RootDir, err)
+	}
+
+	unique := map[string]struct{}{}
+	for marker, m := range markers {
+		candidates, err := matcher.MatchCandidates(marker, m.Matchers)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candidate := range candidates {
+			if info, exists := fsx.PathExists(candidate); exists && info.ModTime().Before(cutoff) {
+				unique[candidate] = struct{}{}
+			}
+		}
+	}
+
+	files := make([]string, 0, len(unique))
+	for file := range unique {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// journaledObjects returns the objects recorded in the journal for each storage type, keyed by object key. The keys
+// are the ones the objects were stored as, e.g. under the host ID of a backfill, rather than the keys derived from
+// the source paths. Uploads recorded without a key are ignored.
+func journaledObjects(opts Options) map[string]map[string]*expectedObject {
+	journaled := map[string]map[string]*expectedObject{}
+	for _, e := range opts.Entries {
+		if opts.Pipeline != "" && e.Pipeline != opts.Pipeline {
+			continue
+		}
+
+		for _, u := range e.Uploads {
+			if u.Src == "" || u.Key == "" {
+				continue
+			}
+
+			obj := &expectedObject{key: u.Key, src: u.Src}
+			if u.ChecksumType == "md5" {
+				obj.checksum = u.Checksum
+			}
+			if journaled[u.Storage] == nil {
+				journaled[u.Storage] = map[string]*expectedObject{}
+			}
+			journaled[u.Storage][u.Key] = obj // later entries override earlier ones
+		}
+	}
+	return journaled
+}
+
+// expectedObjects builds the set of objects expected in the storage keyed by object key.
+// Local files take precedence over the journal since they are the source of truth for a re-upload. The keys of local
+// files come from the journal if they were recorded, and are derived from their paths otherwise.
+func expectedObjects(s core.Storage, localFiles []string, journaled map[string]*expectedObject) (map[string]*expectedObject, error) {
+	expected := make(map[string]*expectedObject, len(journaled))
+	keys := map[string][]string{} // source path -> journaled keys
+	for key, obj := range journaled {
+		expected[key] = &expectedObject{key: key, src: obj.src, checksum: obj.checksum}
+		keys[obj.src] = append(keys[obj.src], key)
+	}
+
+	for _, src := range localFiles {
+		checksum, err := fsx.FileMD5(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate checksum of %s: %w", src, err)
+		}
+
+		srcKeys := keys[src]
+		if len(srcKeys) == 0 {
+			srcKeys = []string{s.Key(src)}
+		}
+		for _, key := range srcKeys {
+			expected[key] = &expectedObject{key: key, src: src, checksum: checksum}
+		}
+	}
+
+	return expected, nil
+}
+
+// compare fills the report with the differences between the expected objects and the objects in the storage.
+func compare(report *Report, expected map[string]*expectedObject, objects []core.ObjectInfo) {
+	remote := make(map[string]core.ObjectInfo, len(objects))
+	for _, obj := range objects {
+		remote[obj.Key] = obj
+	}
+
+	report.Expected = len(expected)
+	report.Remote = len(remote)
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		exp := expected[key]
+		obj, exists := remote[key]
+		if !exists {
+			report.Missing = append(report.Missing, Finding{Key: key, Src: exp.src, ExpectedChecksum: exp.checksum})
+			continue
+		}
+
+		if exp.checksum != "" && obj.ChecksumType == "md5" && !strings.EqualFold(exp.checksum, obj.Checksum) {
+			report.Mismatched = append(report.Mismatched, Finding{
+				Key:              key,
+				Src:              exp.src,
+				ExpectedChecksum: exp.checksum,
+				ActualChecksum:   obj.Checksum,
+			})
+			continue
+		}
+
+		report.Verified++
+	}
+
+	remoteKeys := make([]string, 0, len(remote))
+	for key := range remote {
+		if _, exists := expected[key]; !exists {
+			remoteKeys = append(remoteKeys, key)
+		}
+	}
+	sort.Strings(remoteKeys)
+
+	for _, key := range remoteKeys {
+		report.Extra = append(report.Extra, Finding{Key: key, ActualChecksum: remote[key].Checksum})
+	}
+}
+
+// repair re-uploads the missing and mismatched objects that still have a local copy, and copies the others from a
+// peer storage that has the object with the expected checksum.
+func repair(ctx context.Context, s core.Storage, peers []core.Storage, journaled map[string]map[string]*expectedObject,
+	report *Report, opts Options) {
+	for _, findings := range [][]Finding{report.Missing, report.Mismatched} {
+		for i := range findings {
+			f := &findings[i]
+			f.Error = ""
+
+			source := "local copy"
+			var err error
+			if _, exists := fsx.PathExists(f.Src); exists {
+				err = repairFile(ctx, s, f, opts)
+			} else {
+				source, err = repairFromPeer(ctx, s, peers, journaled, f, opts)
+			}
+			if err != nil {
+				f.Error = err.Error()
+				logx.As().Error().
+					Err(err).
+					Str("storage_type", s.Type()).
+					Str("src", f.Src).
+					Str("key", f.Key).
+					Msg("Failed to repair object")
+				continue
+			}
+
+			f.Repaired = true
+			logx.As().Info().
+				Str("storage_type", s.Type()).
+				Str("src", f.Src).
+				Str("key", f.Key).
+				Str("source", source).
+				Msg("Repaired object")
+		}
+	}
+}
+
+// peers returns the storages other than s.
+func peers(storages []core.Storage, s core.Storage) []core.Storage {
+	var others []core.Storage
+	for _, other := range storages {
+		if other != s {
+			others = append(others, other)
+		}
+	}
+	return others
+}
+
+// repairFromPeer repairs an object without local copy from the first peer storage that has it with the expected
+// checksum, under the key recorded in the journal for the peer. The object is copied server-side if the storage supports it; otherwise it is downloaded to a temporary
+// file and uploaded from there. It returns the peer the object was copied from.
+func repairFromPeer(ctx context.Context, s core.Storage, peers []core.Storage, journaled map[string]map[string]*expectedObject,
+	f *Finding, opts Options) (string, error) {
+	if f.ExpectedChecksum == "" {
+		return "", errors.New("no surviving local copy and no known checksum to find it in another storage")
+	}
+
+	for _, peer := range peers {
+		if !peer.Supports(core.CapabilityStat) {
+			continue
+		}
+
+		peerKey, ok := journaledKey(journaled[peer.Type()], f.Src)
+		if !ok {
+			continue
+		}
+		obj, err := peer.Stat(ctx, peerKey)
+		if err != nil || obj.ChecksumType != "md5" || !strings.EqualFold(obj.Checksum, f.ExpectedChecksum) {
+			continue
+		}
+
+		if s.Supports(core.CapabilityCopy) {
+			copied, err := s.Copy(ctx, peer, peerKey, f.Key)
+			if err == nil {
+				f.ActualChecksum = copied.Checksum
+				record(s, f, fmt.Sprintf("verify-%s", uuid.NewString()), core.StorageResult{
+					MarkerPath: f.Src,
+					UploadResults: []*core.UploadInfo{{
+						Src:          f.Src,
+						Dest:         copied.Key,
+						Key:          copied.Key,
+						ChecksumType: copied.ChecksumType,
+						Checksum:     copied.Checksum,
+						Size:         copied.Size,
+						LastModified: copied.LastModified,
+					}},
+					Type:    s.Type(),
+					Handler: s.Info(),
+				}, opts)
+				return peer.Type(), nil
+			}
+			if !errors.Is(err, core.ErrNotSupported) {
+				return "", fmt.Errorf("failed to copy from %s: %w", peer.Type(), err)
+			}
+		}
+
+		if !peer.Supports(core.CapabilityGet) {
+			continue
+		}
+
+		if err := downloadAndRepair(ctx, s, peer, peerKey, f, opts); err != nil {
+			return "", fmt.Errorf("failed to repair from %s: %w", peer.Type(), err)
+		}
+		return peer.Type(), nil
+	}
+
+	return "", errors.New("no surviving local copy and no other storage has the object with the expected checksum")
+}
+
+// journaledKey returns the key of the journaled object of the source path.
+func journaledKey(journaled map[string]*expectedObject, src string) (string, bool) {
+	for key, obj := range journaled {
+		if obj.src == src {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// downloadAndRepair downloads the object of the peer storage to a temporary file, uploads it to the key of the finding
+// and removes the temporary file. The original path of the file is never touched, so that a running pipeline does not
+// pick up the downloaded copy and a file written there in the meantime is kept.
+func downloadAndRepair(ctx context.Context, s core.Storage, peer core.Storage, peerKey string, f *Finding, opts Options) error {
+	tmp, err := os.CreateTemp("", "cheetah-verify-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	fsx.CloseFile(tmp)
+	defer fsx.RemoveFile(tmp.Name())
+
+	downloaded, err := peer.Get(ctx, peerKey, tmp.Name())
+	if err != nil {
+		return err
+	}
+
+	checksum, err := fsx.FileMD5(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum of %s: %w", tmp.Name(), err)
+	}
+	if !strings.EqualFold(checksum, f.ExpectedChecksum) {
+		return fmt.Errorf("checksum mismatch after download: expected %s, got %s", f.ExpectedChecksum, checksum)
+	}
+
+	var meta *core.ObjectMetadata
+	if downloaded != nil {
+		meta = &downloaded.Metadata
+	}
+	return uploadFile(ctx, s, tmp.Name(), meta, f, opts)
+}
+
+// repairFile uploads the local copy of the file to the key of the finding.
+func repairFile(ctx context.Context, s core.Storage, f *Finding, opts Options) error {
+	return uploadFile(ctx, s, f.Src, nil, f, opts)
+}
+
+// uploadFile uploads a local file to the key of the finding and checks that the object has the checksum of the file.
+// The upload is recorded with the original path of the file as source.
+func uploadFile(ctx context.Context, s core.Storage, src string, meta *core.ObjectMetadata, f *Finding, opts Options) error {
+	checksum, err := fsx.FileMD5(src)
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum of %s: %w", src, err)
+	}
+
+	traceId := fmt.Sprintf("verify-%s", uuid.NewString())
+	info, err := s.Upload(ctx, src, f.Key, meta)
+	result := core.StorageResult{Error: err, MarkerPath: f.Src, Type: s.Type(), Handler: s.Info()}
+	if err == nil {
+		info.Src = f.Src
+		result.UploadResults = []*core.UploadInfo{info}
+	}
+
+	record(s, f, traceId, result, opts)
+
+	if err != nil {
+		return err
+	}
+
+	if !s.Supports(core.CapabilityStat) {
+		return nil
+	}
+
+	obj, err := s.Stat(ctx, f.Key)
+	if err != nil {
+		return err
+	}
+
+	if obj.ChecksumType == "md5" && !strings.EqualFold(obj.Checksum, checksum) {
+		return fmt.Errorf("checksum mismatch after repair: expected %s, got %s", checksum, obj.Checksum)
+	}
+
+	f.ActualChecksum = obj.Checksum
+	return nil
+}
+
+// record passes the result of a repair to the recorder, if set.
+func record(s core.Storage, f *Finding, traceId string, result core.StorageResult, opts Options) {
+	if opts.Recorder == nil {
+		return
+	}
+
+	pr := core.ProcessorResult{
+		Error:    result.Error,
+		Path:     f.Src,
+		TraceId:  traceId,
+		Pipeline: opts.Pipeline,
+		Result:   map[string]*core.StorageResult{s.Type(): &result},
+	}
+	if err := opts.Recorder.Record(pr); err != nil {
+		logx.As().Warn().Err(err).Str("src", f.Src).Msg("Failed to record repaired object")
+	}
+}
@@ -0,0 +1,285 @@
+package verify
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/internal/config"
+	"golang.hedera.com/solo-cheetah/internal/core"
+	"golang.hedera.com/solo-cheetah/internal/journal"
+	"golang.hedera.com/solo-cheetah/internal/matcher"
+	"golang.hedera.com/solo-cheetah/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type mockRecorder struct {
+	results []core.ProcessorResult
+}
+
+func (m *mockRecorder) Record(result core.ProcessorResult) error {
+	m.results = append(m.results, result)
+	return nil
+}
+
+// unlistedStorage is a storage without the core.Lister capability.
+type unlistedStorage struct{}
+
+func (u *unlistedStorage) Info() string { return "unlisted" }
+func (u *unlistedStorage) Type() string { return "Unlisted" }
+func (u *unlistedStorage) Put(ctx context.Context, item core.ScannerResult, candidates []string, stored chan<- core.StorageResult) {
+}
+func (u *unlistedStorage) Upload(ctx context.Context, src string, key string, meta *core.ObjectMetadata) (*core.UploadInfo, error) {
+	return nil, core.ErrNotSupported
+}
+func (u *unlistedStorage) Supports(c core.Capability) bool { return false }
+func (u *unlistedStorage) Key(src string) string           { return src }
+func (u *unlistedStorage) Stat(ctx context.Context, key string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+func (u *unlistedStorage) List(ctx context.Context, opts core.ListOptions) (*core.ListResult, error) {
+	return nil, core.ErrNotSupported
+}
+func (u *unlistedStorage) Get(ctx context.Context, key string, dest string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+func (u *unlistedStorage) Delete(ctx context.Context, key string) error { return core.ErrNotSupported }
+func (u *unlistedStorage) Copy(ctx context.Context, src core.Storage, srcKey string, destKey string) (*core.ObjectInfo, error) {
+	return nil, core.ErrNotSupported
+}
+
+func writeFile(t *testing.T, path string, content string, age time.Duration) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestRun(t *testing.T) {
+	rootDir := t.TempDir()
+	destDir := t.TempDir()
+
+	// local files that survived: a.rcd_sig and a.rcd; c.rcd_sig is too young to be verified
+	writeFile(t, filepath.Join(rootDir, "rec", "a.rcd_sig"), "sig-a", time.Hour)
+	writeFile(t, filepath.Join(rootDir, "rec", "a.rcd"), "data-a", time.Hour)
+	writeFile(t, filepath.Join(rootDir, "rec", "c.rcd_sig"), "sig-c", 0)
+
+	// remote: a.rcd_sig is correct, a.rcd is corrupted, b.rcd is missing and x.rcd is unexpected
+	writeFile(t, filepath.Join(destDir, "rec", "a.rcd_sig"), "sig-a", 0)
+	writeFile(t, filepath.Join(destDir, "rec", "a.rcd"), "corrupted", 0)
+	writeFile(t, filepath.Join(destDir, "rec", "x.rcd"), "unexpected", 0)
+
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: destDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+
+	// b.rcd was uploaded earlier and removed locally; entries of other pipelines and storages are ignored
+	entries := []journal.Entry{
+		{Pipeline: "records", Uploads: []journal.Upload{
+			{Storage: storage.TypeLocalDir, Src: filepath.Join(rootDir, "rec", "b.rcd"), Key: "rec/b.rcd", ChecksumType: "md5", Checksum: "bbb"},
+			{Storage: storage.TypeS3, Src: filepath.Join(rootDir, "rec", "s3-only.rcd"), Key: "rec/s3-only.rcd"},
+		}},
+		{Pipeline: "events", Uploads: []journal.Upload{
+			{Storage: storage.TypeLocalDir, Src: filepath.Join(rootDir, "rec", "other.rcd"), Key: "rec/other.rcd"},
+		}},
+	}
+
+	markers, err := matcher.NewMarkerPatterns(".rcd_sig", nil, []config.FileMatcherConfig{
+		{MatcherType: matcher.FileMatcherBasic, Patterns: []string{".rcd", ".rcd_sig"}},
+	})
+	require.NoError(t, err)
+
+	opts := Options{
+		Pipeline: "records",
+		RootDir:  rootDir,
+		Markers:  markers,
+		Entries:  entries,
+		MinAge:   time.Minute,
+	}
+
+	reports, err := Run(context.Background(), []core.Storage{dir, &unlistedStorage{}}, opts)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	r := reports[0]
+	require.Equal(t, storage.TypeLocalDir, r.Storage)
+	require.Empty(t, r.Error)
+	require.Equal(t, 3, r.Expected)
+	require.Equal(t, 3, r.Remote)
+	require.Equal(t, 1, r.Verified)
+	require.Len(t, r.Missing, 1)
+	require.Equal(t, "rec/b.rcd", r.Missing[0].Key)
+	require.Len(t, r.Mismatched, 1)
+	require.Equal(t, "rec/a.rcd", r.Mismatched[0].Key)
+	require.Len(t, r.Extra, 1)
+	require.Equal(t, "rec/x.rcd", r.Extra[0].Key)
+	require.False(t, r.OK())
+
+	require.NotEmpty(t, reports[1].Error)
+	require.False(t, reports[1].OK())
+
+	// repair re-uploads the corrupted file from the local copy
+	recorder := &mockRecorder{}
+	opts.Repair = true
+	opts.Recorder = recorder
+	reports, err = Run(context.Background(), []core.Storage{dir}, opts)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	r = reports[0]
+	require.True(t, r.Mismatched[0].Repaired)
+	require.Empty(t, r.Mismatched[0].Error)
+	require.False(t, r.Missing[0].Repaired)
+	require.Contains(t, r.Missing[0].Error, "no surviving local copy")
+	require.False(t, r.OK())
+	require.Len(t, recorder.results, 1)
+	require.Equal(t, "records", recorder.results[0].Pipeline)
+
+	data, err := os.ReadFile(filepath.Join(destDir, "rec", "a.rcd"))
+	require.NoError(t, err)
+	require.Equal(t, "data-a", string(data))
+
+	// once the missing file is no longer expected, the storage is consistent
+	opts.Entries = nil
+	opts.Repair = false
+	reports, err = Run(context.Background(), []core.Storage{dir}, opts)
+	require.NoError(t, err)
+	require.Equal(t, 2, reports[0].Verified)
+	require.True(t, reports[0].OK())
+}
+
+func TestRun_JournaledKeys(t *testing.T) {
+	rootDir := t.TempDir()
+	destDir := t.TempDir()
+	peerDir := t.TempDir()
+
+	// a.rcd was backfilled under the host ID and is still on disk, b.rcd was swept as an orphan and removed
+	local := filepath.Join(rootDir, "rec", "a.rcd")
+	writeFile(t, local, "data-a", time.Hour)
+	writeFile(t, filepath.Join(rootDir, "rec", "a.rcd_sig"), "sig-a", time.Hour)
+	writeFile(t, filepath.Join(destDir, "rec", "a.rcd_sig"), "sig-a", 0)
+	writeFile(t, filepath.Join(destDir, "0.0.3", "rec", "a.rcd"), "data-a", 0)
+	writeFile(t, filepath.Join(peerDir, "orphans", "rec", "b.rcd"), "data-b", 0)
+
+	sum := md5.Sum([]byte("data-b"))
+	removed := filepath.Join(rootDir, "rec", "b.rcd")
+	entries := []journal.Entry{{Pipeline: "records", Uploads: []journal.Upload{
+		{Storage: storage.TypeLocalDir, Src: local, Key: "0.0.3/rec/a.rcd"},
+		{Storage: storage.TypeLocalDir, Src: removed, Key: "orphans/rec/b.rcd", ChecksumType: "md5", Checksum: hex.EncodeToString(sum[:])},
+	}}}
+
+	markers, err := matcher.NewMarkerPatterns(".rcd_sig", nil, []config.FileMatcherConfig{
+		{MatcherType: matcher.FileMatcherBasic, Patterns: []string{".rcd", ".rcd_sig"}},
+	})
+	require.NoError(t, err)
+
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: destDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+	peer, err := storage.NewLocalDir("dir-1", config.LocalDirConfig{Path: peerDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+
+	reports, err := Run(context.Background(), []core.Storage{dir, &remoteStorage{Storage: peer}}, Options{
+		Pipeline: "records",
+		RootDir:  rootDir,
+		Markers:  markers,
+		Entries:  entries,
+		MinAge:   time.Minute,
+		Repair:   true,
+	})
+	require.NoError(t, err)
+
+	// the journaled keys are expected instead of the keys derived from the source paths
+	r := reports[0]
+	require.Equal(t, 3, r.Expected)
+	require.Equal(t, 2, r.Verified)
+	require.Empty(t, r.Extra)
+	require.Len(t, r.Missing, 1)
+	require.Equal(t, "orphans/rec/b.rcd", r.Missing[0].Key)
+	require.True(t, r.Missing[0].Repaired, r.Missing[0].Error)
+	require.True(t, r.OK())
+
+	// the object is repaired from the journaled key of the peer to its own journaled key
+	data, err := os.ReadFile(filepath.Join(destDir, "orphans", "rec", "b.rcd"))
+	require.NoError(t, err)
+	require.Equal(t, "data-b", string(data))
+	require.NoFileExists(t, filepath.Join(destDir, "rec", "b.rcd"))
+}
+
+// remoteStorage hides the type of a storage, so that server-side copies from it are not supported.
+type remoteStorage struct {
+	core.Storage
+}
+
+func TestRun_RepairFromPeer(t *testing.T) {
+	rootDir := t.TempDir()
+	src := filepath.Join(rootDir, "rec", "b.rcd")
+	sum := md5.Sum([]byte("data-b"))
+	checksum := hex.EncodeToString(sum[:])
+
+	entries := []journal.Entry{{Pipeline: "records", Uploads: []journal.Upload{
+		{Storage: storage.TypeLocalDir, Src: src, Key: "rec/b.rcd", ChecksumType: "md5", Checksum: checksum},
+	}}}
+
+	tests := []struct {
+		name string
+		peer func(core.Storage) core.Storage
+	}{
+		{"copy", func(s core.Storage) core.Storage { return s }},
+		{"download", func(s core.Storage) core.Storage { return &remoteStorage{Storage: s} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir := t.TempDir()
+			peerDir := t.TempDir()
+			writeFile(t, filepath.Join(peerDir, "rec", "b.rcd"), "data-b", 0)
+
+			dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: destDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+			require.NoError(t, err)
+			peerStorage, err := storage.NewLocalDir("dir-1", config.LocalDirConfig{Path: peerDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+			require.NoError(t, err)
+			peer := tt.peer(peerStorage)
+
+			recorder := &mockRecorder{}
+			reports, err := Run(context.Background(), []core.Storage{dir, peer}, Options{
+				Pipeline: "records",
+				RootDir:  rootDir,
+				Entries:  entries,
+				Repair:   true,
+				Recorder: recorder,
+			})
+			require.NoError(t, err)
+			require.Len(t, reports, 2)
+
+			r := reports[0]
+			require.Len(t, r.Missing, 1)
+			require.True(t, r.Missing[0].Repaired, r.Missing[0].Error)
+			require.True(t, r.OK())
+			require.True(t, reports[1].OK())
+			require.Len(t, recorder.results, 1)
+			require.Equal(t, src, recorder.results[0].Path)
+			require.NoError(t, recorder.results[0].Error)
+
+			data, err := os.ReadFile(filepath.Join(destDir, "rec", "b.rcd"))
+			require.NoError(t, err)
+			require.Equal(t, "data-b", string(data))
+			require.NoDirExists(t, filepath.Dir(src), "the original path of the file is never touched")
+		})
+	}
+
+	// a peer with a different content is not used
+	destDir := t.TempDir()
+	peerDir := t.TempDir()
+	writeFile(t, filepath.Join(peerDir, "rec", "b.rcd"), "corrupted", 0)
+	dir, err := storage.NewLocalDir("dir-0", config.LocalDirConfig{Path: destDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+	peer, err := storage.NewLocalDir("dir-1", config.LocalDirConfig{Path: peerDir, Mode: 0755}, config.RetryConfig{Limit: 1}, rootDir)
+	require.NoError(t, err)
+
+	reports, err := Run(context.Background(), []core.Storage{dir, peer}, Options{Pipeline: "records", RootDir: rootDir, Entries: entries, Repair: true})
+	require.NoError(t, err)
+	require.False(t, reports[0].Missing[0].Repaired)
+	require.Contains(t, reports[0].Missing[0].Error, "no other storage has the object")
+	require.NoFileExists(t, filepath.Join(destDir, "rec", "b.rcd"))
+}
@@ -0,0 +1,183 @@
+package fsx
+
+import (
+	"fmt"
+	"github.com/gobwas/glob"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDirCacheTTL is the default time a directory listing is reused.
+const DefaultDirCacheTTL = time.Second
+
+// modTimeGranularity is the coarsest granularity of directory modification times that the cache allows for, e.g. one
+// second on ext3 or NFS.
+const modTimeGranularity = time.Second
+
+// globMetaChars are the characters that make a path segment a glob pattern rather than a fixed name.
+const globMetaChars = `*?[{\`
+
+// listing is a cached directory listing.
+type listing struct {
+	modTime  time.Time // modification time of the directory when it was listed
+	listedAt time.Time
+	entries  []os.DirEntry
+}
+
+// DirCache caches directory listings for a short time so that the files of many markers of the same directory are
+// matched against a single listing.
+//
+// A listing is reused while it is younger than the TTL and the modification time of the directory is unchanged, and
+// only if that modification time was older than the listing by more than the modification time granularity: a file
+// created or removed within the same tick as the listing would leave the modification time unchanged. Directories
+// modified just before they were listed are therefore listed again, so that files created or removed since the listing
+// are not missed on file systems with a granularity of up to a second. Reusing a listing costs one stat of the
+// directory.
+type DirCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	listings map[string]*listing
+	lists    int // number of directory listings read from disk
+}
+
+// List returns the entries of the directory, or nil if the directory does not exist.
+func (c *DirCache) List(dir string) ([]os.DirEntry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	c.expireLocked(now)
+	l, ok := c.listings[dir]
+	c.mu.Unlock()
+
+	if ok && l.modTime.Equal(info.ModTime()) && l.listedAt.Sub(l.modTime) > modTimeGranularity {
+		return l.entries, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.listings[dir] = &listing{modTime: info.ModTime(), listedAt: now, entries: entries}
+	c.lists++
+	c.mu.Unlock()
+
+	return entries, nil
+}
+
+// expireLocked forgets the listings older than the TTL.
+func (c *DirCache) expireLocked(now time.Time) {
+	for dir, l := range c.listings {
+		if now.Sub(l.listedAt) >= c.ttl {
+			delete(c.listings, dir)
+		}
+	}
+}
+
+// MatchGlob returns the regular files matching the glob patterns.
+//
+// Unlike MatchFilePatterns, it does not walk a whole directory tree: only the fixed directory prefix of each pattern
+// is listed, and its subdirectories are only listed as deep as the pattern has path segments, or entirely if the
+// pattern contains **. A * does not match the path separator.
+func (c *DirCache) MatchGlob(patterns []string) ([]string, error) {
+	found := map[string]struct{}{}
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, filepath.Separator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile glob pattern '%s': %w", pattern, err)
+		}
+
+		dir, rest := SplitGlobPrefix(pattern)
+		depth := strings.Count(rest, string(filepath.Separator)) + 1
+		if strings.Contains(rest, "**") {
+			depth = -1
+		}
+
+		if err := c.match(dir, depth, g, found); err != nil {
+			return nil, fmt.Errorf("error listing directory '%s': %w", dir, err)
+		}
+	}
+
+	matches := make([]string, 0, len(found))
+	for match := range found {
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// match adds the regular files of the directory matching the glob, descending into subdirectories while depth is
+// greater than 1, or without limit if depth is negative.
+func (c *DirCache) match(dir string, depth int, g glob.Glob, found map[string]struct{}) error {
+	entries, err := c.List(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if depth > 1 || depth < 0 {
+				if err := c.match(path, depth-1, g, found); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if entry.Type().IsRegular() && g.Match(path) {
+			found[path] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// SplitGlobPrefix splits a glob pattern into the directory made of its leading path segments without glob syntax and
+// the rest of the pattern, e.g. /data/sidecar/*_##.gz into /data/sidecar and *_##.gz.
+func SplitGlobPrefix(pattern string) (string, string) {
+	segments := strings.Split(pattern, string(filepath.Separator))
+
+	fixed := 0
+	for fixed < len(segments)-1 && !strings.ContainsAny(segments[fixed], globMetaChars) {
+		fixed++
+	}
+
+	dir := strings.Join(segments[:fixed], string(filepath.Separator))
+	if dir == "" && strings.HasPrefix(pattern, string(filepath.Separator)) {
+		dir = string(filepath.Separator)
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	return dir, strings.Join(segments[fixed:], string(filepath.Separator))
+}
+
+// NewDirCache creates a directory listing cache; listings are reused for at most ttl. Default is DefaultDirCacheTTL.
+func NewDirCache(ttl time.Duration) *DirCache {
+	if ttl <= 0 {
+		ttl = DefaultDirCacheTTL
+	}
+
+	return &DirCache{
+		ttl:      ttl,
+		listings: make(map[string]*listing),
+	}
+}
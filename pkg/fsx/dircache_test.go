@@ -0,0 +1,135 @@
+package fsx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitGlobPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dir     string
+		rest    string
+	}{
+		{pattern: "/data/record0.0.3/2024-01-01T00_00_00.000000000Z.rcd.gz", dir: "/data/record0.0.3", rest: "2024-01-01T00_00_00.000000000Z.rcd.gz"},
+		{pattern: "/data/sidecar/marker_[0-9]*.gz", dir: "/data/sidecar", rest: "marker_[0-9]*.gz"},
+		{pattern: "/data/*/marker.gz", dir: "/data", rest: "*/marker.gz"},
+		{pattern: "/data/**/*.rcd_sig", dir: "/data", rest: "**/*.rcd_sig"},
+		{pattern: "/*.gz", dir: "/", rest: "*.gz"},
+		{pattern: "*.gz", dir: ".", rest: "*.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			dir, rest := SplitGlobPrefix(tt.pattern)
+			assert.Equal(t, tt.dir, dir)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
+
+func TestDirCache_MatchGlob(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, f := range []string{
+		"foo.rcd_sig",
+		"foo.rcd.gz",
+		"sidecar/foo_01.rcd.gz",
+		"sidecar/nested/foo_02.rcd.gz",
+	} {
+		fullPath := filepath.Join(tempDir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte("test"), 0644))
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "single directory",
+			patterns: []string{filepath.Join(tempDir, "foo*")},
+			want:     []string{filepath.Join(tempDir, "foo.rcd_sig"), filepath.Join(tempDir, "foo.rcd.gz")},
+		},
+		{
+			name:     "star does not match subdirectories",
+			patterns: []string{filepath.Join(tempDir, "sidecar/foo_*.rcd.gz")},
+			want:     []string{filepath.Join(tempDir, "sidecar/foo_01.rcd.gz")},
+		},
+		{
+			name:     "double star matches subdirectories",
+			patterns: []string{filepath.Join(tempDir, "sidecar/**.rcd.gz")},
+			want: []string{
+				filepath.Join(tempDir, "sidecar/foo_01.rcd.gz"),
+				filepath.Join(tempDir, "sidecar/nested/foo_02.rcd.gz"),
+			},
+		},
+		{
+			name:     "wildcard directory",
+			patterns: []string{filepath.Join(tempDir, "*/foo_01.rcd.gz")},
+			want:     []string{filepath.Join(tempDir, "sidecar/foo_01.rcd.gz")},
+		},
+		{
+			name:     "missing directory",
+			patterns: []string{filepath.Join(tempDir, "missing/*.gz")},
+			want:     []string{},
+		},
+	}
+
+	cache := NewDirCache(time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := cache.MatchGlob(tt.patterns)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, matches)
+		})
+	}
+}
+
+func TestDirCache_List(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "foo.rcd_sig"), []byte("test"), 0644))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(tempDir, past, past))
+
+	cache := NewDirCache(time.Minute)
+	for i := 0; i < 3; i++ {
+		entries, err := cache.List(tempDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	}
+	require.Equal(t, 1, cache.lists, "unchanged directory is listed once")
+
+	// a new file changes the modification time of the directory, so the listing is refreshed
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "foo.rcd.gz"), []byte("test"), 0644))
+	entries, err := cache.List(tempDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 2, cache.lists)
+
+	// a file created within the same modification time tick as the listing is not missed
+	recent := time.Now()
+	require.NoError(t, os.Chtimes(tempDir, recent, recent))
+	_, err = cache.List(tempDir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "foo_01.rcd.gz"), []byte("test"), 0644))
+	require.NoError(t, os.Chtimes(tempDir, recent, recent))
+	entries, err = cache.List(tempDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, 4, cache.lists)
+
+	// listings expire after the TTL
+	cache = NewDirCache(time.Nanosecond)
+	for i := 0; i < 2; i++ {
+		_, err := cache.List(tempDir)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, 2, cache.lists)
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -65,6 +66,32 @@ func Move(src string, dst string, perm os.FileMode) error {
 	return nil
 }
 
+// WriteFileAtomic writes the data to a temporary file next to the file, flushes it to disk and renames it to the file,
+// so that an interruption never leaves a truncated file. The temporary file is removed on failure.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	tmp := filePath + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("couldn't open temporary file: %w", err)
+	}
+
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("couldn't write %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
 func SplitFilePath(filePath string) (dir, fileNameWithoutExt, ext string) {
 	dir, file := path.Split(filePath)
 	ext = path.Ext(file)
@@ -72,6 +99,25 @@ func SplitFilePath(filePath string) (dir, fileNameWithoutExt, ext string) {
 	return dir, fileNameWithoutExt, ext
 }
 
+// IsWithin returns true if the path is the root directory or inside it. Paths are compared lexically after being made
+// absolute; symlinks are not resolved.
+func IsWithin(root string, path string) bool {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func CombineFilePath(dir string, fileName string, ext string) string {
 	return path.Join(dir, fmt.Sprintf("%s%s", fileName, ext))
 }
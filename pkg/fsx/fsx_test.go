@@ -77,6 +77,35 @@ func TestMove(t *testing.T) {
 	assert.Equal(t, "test content", string(content))
 }
 
+func TestWriteFileAtomic(t *testing.T) {
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "state.json")
+
+	// Test writing a new file and replacing it
+	assert.NoError(t, WriteFileAtomic(file, []byte("first"), 0600))
+	assert.NoError(t, WriteFileAtomic(file, []byte("second"), 0600))
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Verify the temporary file is not left behind
+	_, exists := PathExists(file + ".tmp")
+	assert.False(t, exists)
+
+	// Test a failed rename keeps no temporary file
+	dir := filepath.Join(tempDir, "dir")
+	assert.NoError(t, os.Mkdir(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "child"), nil, 0644))
+	assert.Error(t, WriteFileAtomic(dir, []byte("data"), 0644))
+	_, exists = PathExists(dir + ".tmp")
+	assert.False(t, exists)
+}
+
 func TestFileMD5(t *testing.T) {
 	tempDir := t.TempDir()
 	defer func() {
@@ -112,3 +141,12 @@ func TestFileSha256(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72", hash)
 }
+
+func TestIsWithin(t *testing.T) {
+	assert.True(t, IsWithin("/data/streams", "/data/streams"))
+	assert.True(t, IsWithin("/data/streams", "/data/streams/record0.0.3/file.rcd"))
+	assert.True(t, IsWithin("/data/streams/", "/data/streams/../streams/file.rcd"))
+	assert.False(t, IsWithin("/data/streams", "/data/streams-old/file.rcd"))
+	assert.False(t, IsWithin("/data/streams", "/data/streams/../file.rcd"))
+	assert.False(t, IsWithin("/data/streams", "/data"))
+}
@@ -0,0 +1,12 @@
+package fsx
+
+import "errors"
+
+// ErrWritersNotSupported is returned by OpenWriters on systems where the open files of processes cannot be listed.
+var ErrWritersNotSupported = errors.New("listing files open for writing is not supported on this system")
+
+// FileID identifies a file independently of its path, by device and inode number.
+type FileID struct {
+	Dev uint64
+	Ino uint64
+}
@@ -0,0 +1,87 @@
+package fsx
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// procDir is the mount point of the proc file system.
+var procDir = "/proc"
+
+// FileIDOf returns the ID of the file described by the file info, or false if the file system does not provide one.
+func FileIDOf(info os.FileInfo) (FileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, false
+	}
+	return FileID{Dev: uint64(st.Dev), Ino: st.Ino}, true
+}
+
+// OpenWriters returns the IDs of the regular files that a process has open for writing, read from the file
+// descriptors of /proc/<pid>/fd. Processes whose file descriptors cannot be read, e.g. processes of other users
+// without sufficient privileges, are skipped.
+func OpenWriters() (map[FileID]struct{}, error) {
+	procs, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	writers := make(map[FileID]struct{})
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join(procDir, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // the process exited or is not accessible
+		}
+
+		for _, fd := range fds {
+			info, err := os.Stat(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			if !openForWriting(filepath.Join(procDir, proc.Name(), "fdinfo", fd.Name())) {
+				continue
+			}
+
+			if id, ok := FileIDOf(info); ok {
+				writers[id] = struct{}{}
+			}
+		}
+	}
+
+	return writers, nil
+}
+
+// openForWriting returns true if the access mode in the flags of the fdinfo file is write-only or read-write.
+func openForWriting(fdInfo string) bool {
+	f, err := os.Open(fdInfo)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "flags:")
+		if !ok {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimSpace(value), 8, 64)
+		if err != nil {
+			return false
+		}
+		return flags&syscall.O_ACCMODE != syscall.O_RDONLY
+	}
+
+	return false
+}
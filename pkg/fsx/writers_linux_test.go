@@ -0,0 +1,38 @@
+package fsx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenWriters(t *testing.T) {
+	tempDir := t.TempDir()
+	written := filepath.Join(tempDir, "written.log")
+	read := filepath.Join(tempDir, "read.log")
+	require.NoError(t, os.WriteFile(written, []byte("data"), 0644))
+	require.NoError(t, os.WriteFile(read, []byte("data"), 0644))
+
+	w, err := os.OpenFile(written, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer w.Close()
+	r, err := os.Open(read)
+	require.NoError(t, err)
+	defer r.Close()
+
+	writers, err := OpenWriters()
+	require.NoError(t, err)
+
+	id := func(path string) FileID {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		id, ok := FileIDOf(info)
+		require.True(t, ok)
+		return id
+	}
+	assert.Contains(t, writers, id(written))
+	assert.NotContains(t, writers, id(read))
+}
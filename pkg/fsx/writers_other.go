@@ -0,0 +1,15 @@
+//go:build !linux
+
+package fsx
+
+import "os"
+
+// FileIDOf returns false since file IDs are only used to match the files open for writing.
+func FileIDOf(info os.FileInfo) (FileID, bool) {
+	return FileID{}, false
+}
+
+// OpenWriters returns ErrWritersNotSupported since the open files of processes are only listed on Linux.
+func OpenWriters() (map[FileID]struct{}, error) {
+	return nil, ErrWritersNotSupported
+}
@@ -0,0 +1,116 @@
+package hedera
+
+import (
+	"encoding/hex"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"strings"
+)
+
+// Field numbers of the NodeAddressBook protobuf message and its nested messages.
+const (
+	fieldAddressBookNodeAddress = 1 // NodeAddressBook.nodeAddress
+	fieldNodeAddressMemo        = 3 // NodeAddress.memo, the node account ID in old address books
+	fieldNodeAddressRSAPubKey   = 4 // NodeAddress.RSA_PubKey, hex encoded DER public key
+	fieldNodeAddressAccountId   = 6 // NodeAddress.nodeAccountId
+	fieldAccountIdShard         = 1
+	fieldAccountIdRealm         = 2
+	fieldAccountIdNum           = 3
+)
+
+// NodeAddress is the entry of a node in an address book.
+//
+// Fields:
+//   - AccountId: The node account ID (e.g. 0.0.3), taken from the memo in old address books; empty if not set.
+//   - RSAPublicKey: The DER encoded RSA public key of the node; nil if not set.
+type NodeAddress struct {
+	AccountId    string
+	RSAPublicKey []byte
+}
+
+// ReadAddressBook reads the node addresses of a serialized NodeAddressBook protobuf message, such as the content of
+// file 0.0.102.
+func ReadAddressBook(data []byte) ([]NodeAddress, error) {
+	var addresses []NodeAddress
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != fieldAddressBookNodeAddress || typ != protowire.BytesType {
+			return nil
+		}
+
+		address, err := readNodeAddress(value)
+		if err != nil {
+			return err
+		}
+
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// readNodeAddress reads the node account ID and RSA public key of a NodeAddress message.
+func readNodeAddress(data []byte) (NodeAddress, error) {
+	var memo string
+	var address NodeAddress
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case fieldNodeAddressMemo:
+			memo = string(value)
+		case fieldNodeAddressAccountId:
+			id, err := readAccountId(value)
+			if err != nil {
+				return fmt.Errorf("invalid node account ID: %w", err)
+			}
+			address.AccountId = id
+		case fieldNodeAddressRSAPubKey:
+			der, err := hex.DecodeString(strings.TrimPrefix(string(value), "0x"))
+			if err != nil {
+				return fmt.Errorf("invalid RSA public key encoding: %w", err)
+			}
+			address.RSAPublicKey = der
+		}
+		return nil
+	})
+	if err != nil {
+		return NodeAddress{}, err
+	}
+
+	if address.AccountId == "" {
+		address.AccountId = ParseNodeAccountId(memo)
+	}
+	return address, nil
+}
+
+// readAccountId returns the account ID of an AccountID message as shard.realm.num.
+func readAccountId(data []byte) (string, error) {
+	var id [3]uint64
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+
+		v, _ := protowire.ConsumeVarint(value)
+		switch num {
+		case fieldAccountIdShard:
+			id[0] = v
+		case fieldAccountIdRealm:
+			id[1] = v
+		case fieldAccountIdNum:
+			id[2] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d.%d.%d", id[0], id[1], id[2]), nil
+}
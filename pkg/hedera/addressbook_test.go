@@ -0,0 +1,48 @@
+package hedera
+
+import (
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"google.golang.org/protobuf/encoding/protowire"
+	"testing"
+)
+
+func TestReadAddressBook(t *testing.T) {
+	der := []byte("der encoded key")
+	data := hederatest.AddressBook(
+		hederatest.NodeAddress(0, 0, 3, der, true),
+		hederatest.NodeAddress(1, 2, 4, der, false),
+		hederatest.NodeAddress(0, 0, 5, nil, false),
+		hederatest.NodeAddressRSAPubKey("0x"+"6b6579"),
+	)
+
+	addresses, err := ReadAddressBook(data)
+	require.NoError(t, err)
+	require.Equal(t, []NodeAddress{
+		{AccountId: "0.0.3", RSAPublicKey: der},
+		{AccountId: "1.2.4", RSAPublicKey: der},
+		{AccountId: "0.0.5"},
+		{RSAPublicKey: []byte("key")},
+	}, addresses)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		errMsg string
+	}{
+		{name: "invalid key encoding", data: hederatest.AddressBook(hederatest.NodeAddressRSAPubKey("zz")), errMsg: "invalid RSA public key encoding"},
+		{name: "truncated message", data: data[:len(data)-1], errMsg: "invalid protobuf field"},
+		{
+			name:   "truncated account ID",
+			data:   hederatest.AddressBook(protowire.AppendBytes(protowire.AppendTag(nil, fieldNodeAddressAccountId, protowire.BytesType), []byte{0x08})),
+			errMsg: "invalid node account ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAddressBook(tt.data)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package hederatest builds stream, signature and address book files for tests.
+//
+// The files are encoded independently of the hedera package, so that its readers are tested against the format rather
+// than against themselves.
+package hederatest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// HashLength is the length of a SHA-384 hash.
+	HashLength = 48
+
+	hashObjectClassId           = 0xf422da83a251741e
+	hashObjectClassVersion      = 1
+	digestTypeSHA384            = 0x58ff811b
+	signatureObjectClassId      = 0x13dc4b399b245c69
+	signatureObjectClassVersion = 1
+	signatureTypeRSA            = 1
+	objectStreamVersion         = 1
+
+	// field numbers of the NodeAddressBook, NodeAddress and AccountID protobuf messages
+	fieldAddressBookNodeAddress = 1
+	fieldNodeAddressMemo        = 3
+	fieldNodeAddressRSAPubKey   = 4
+	fieldNodeAddressNodeId      = 5
+	fieldNodeAddressAccountId   = 6
+)
+
+// Hash returns a hash whose bytes are all b.
+func Hash(b byte) []byte {
+	return bytes.Repeat([]byte{b}, HashLength)
+}
+
+// AppendInt32 appends big endian 32-bit integers to the buffer.
+func AppendInt32(buf []byte, v ...int32) []byte {
+	for _, i := range v {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(i))
+	}
+	return buf
+}
+
+// AppendHashObject appends a serialized SHA-384 hash object to the buffer.
+func AppendHashObject(buf []byte, hash []byte) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, hashObjectClassId)
+	buf = AppendInt32(buf, hashObjectClassVersion, digestTypeSHA384, int32(len(hash)))
+	return append(buf, hash...)
+}
+
+// AppendSignatureObject appends a serialized RSA signature object, with a valid checksum, to the buffer.
+func AppendSignatureObject(buf []byte, sig []byte) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, signatureObjectClassId)
+	buf = AppendInt32(buf, signatureObjectClassVersion, signatureTypeRSA, int32(len(sig)), 101-int32(len(sig)))
+	return append(buf, sig...)
+}
+
+// RecordFileV5 returns a version 5 record file of HAPI version 0.27.1 with the start and end running hashes. The file
+// ends after the start running hash if end is nil.
+func RecordFileV5(start []byte, end []byte) []byte {
+	buf := AppendHashObject(AppendInt32(nil, 5, 0, 27, 1, objectStreamVersion), start)
+	if end == nil {
+		return buf
+	}
+	buf = append(buf, []byte("record stream objects")...)
+	return AppendHashObject(buf, end)
+}
+
+// SignatureFile returns a version 5 or 6 signature file with the hashes and signatures of a stream file and of its
+// metadata.
+func SignatureFile(version byte, fileHash []byte, fileSig []byte, metaHash []byte, metaSig []byte) []byte {
+	buf := AppendInt32([]byte{version}, objectStreamVersion)
+	buf = AppendSignatureObject(AppendHashObject(buf, fileHash), fileSig)
+	return AppendSignatureObject(AppendHashObject(buf, metaHash), metaSig)
+}
+
+// NodeAddress returns a NodeAddress message with the hex encoded DER public key, and the account ID shard.realm.num in
+// nodeAccountId, or in the memo if legacy is set. A nil key is left out.
+func NodeAddress(shard, realm, num uint64, der []byte, legacy bool) []byte {
+	var buf []byte
+	if der != nil {
+		buf = protowire.AppendTag(buf, fieldNodeAddressRSAPubKey, protowire.BytesType)
+		buf = protowire.AppendString(buf, hex.EncodeToString(der))
+	}
+	buf = protowire.AppendTag(buf, fieldNodeAddressNodeId, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, num)
+	if legacy {
+		buf = protowire.AppendTag(buf, fieldNodeAddressMemo, protowire.BytesType)
+		return protowire.AppendString(buf, fmt.Sprintf("%d.%d.%d", shard, realm, num))
+	}
+
+	var id []byte
+	for i, v := range []uint64{shard, realm, num} {
+		id = protowire.AppendTag(id, protowire.Number(i+1), protowire.VarintType)
+		id = protowire.AppendVarint(id, v)
+	}
+	buf = protowire.AppendTag(buf, fieldNodeAddressAccountId, protowire.BytesType)
+	return protowire.AppendBytes(buf, id)
+}
+
+// NodeAddressRSAPubKey returns a NodeAddress message with only the RSA_PubKey field, holding the value as is.
+func NodeAddressRSAPubKey(value string) []byte {
+	buf := protowire.AppendTag(nil, fieldNodeAddressRSAPubKey, protowire.BytesType)
+	return protowire.AppendString(buf, value)
+}
+
+// AddressBook returns a NodeAddressBook message with the NodeAddress messages.
+func AddressBook(addresses ...[]byte) []byte {
+	var buf []byte
+	for _, a := range addresses {
+		buf = protowire.AppendTag(buf, fieldAddressBookNodeAddress, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, a)
+	}
+	return buf
+}
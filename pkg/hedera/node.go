@@ -0,0 +1,16 @@
+package hedera
+
+import "regexp"
+
+// nodeAccountIdRegex matches a node account ID such as 0.0.3, e.g. in record0.0.3 or node0.0.3.pem.
+var nodeAccountIdRegex = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// ParseNodeAccountId returns the last node account ID found in the path, e.g. 0.0.3 for
+// /opt/hgcapp/recordStreams/record0.0.3, or an empty string if there is none.
+func ParseNodeAccountId(path string) string {
+	matches := nodeAccountIdRegex.FindAllString(path, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
@@ -0,0 +1,14 @@
+package hedera
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNodeAccountId(t *testing.T) {
+	assert.Equal(t, "0.0.3", ParseNodeAccountId("/opt/hgcapp/recordStreams/record0.0.3"))
+	assert.Equal(t, "0.0.4", ParseNodeAccountId("/data/record0.0.3/backup/record0.0.4"))
+	assert.Equal(t, "1.2.30", ParseNodeAccountId("node1.2.30.pem"))
+	assert.Equal(t, "", ParseNodeAccountId("/opt/hgcapp/recordStreams"))
+}
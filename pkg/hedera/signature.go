@@ -0,0 +1,150 @@
+package hedera
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Versions of the signature file format.
+const (
+	SignatureFileVersion2 = 2 // signature files of version 2 record files have no version byte
+	SignatureFileVersion5 = 5
+	SignatureFileVersion6 = 6
+)
+
+const (
+	signatureObjectClassId      = 0x13dc4b399b245c69 // class ID of a serialized signature object
+	signatureObjectClassVersion = 1
+	signatureTypeRSA            = 1
+	typeSignature               = 3 // type byte before the signature in version 2 signature files
+	typeFileHash                = 4 // type byte before the file hash in version 2 signature files
+)
+
+// SignatureFile is a parsed record or event signature file (.rcd_sig, .evts_sig).
+//
+// Fields:
+//   - Version: The version of the signature file format.
+//   - FileHash: The SHA-384 hash of the uncompressed stream file.
+//   - FileSignature: The SHA384withRSA signature of the node over the file hash.
+//   - MetadataHash: The hash of the stream file metadata; not set for version 2 signature files.
+//   - MetadataSignature: The signature of the node over the metadata hash; not set for version 2 signature files.
+type SignatureFile struct {
+	Version           int
+	FileHash          []byte
+	FileSignature     []byte
+	MetadataHash      []byte
+	MetadataSignature []byte
+}
+
+// ReadSignatureFile reads a signature file of version 2, 5 or 6.
+func ReadSignatureFile(r io.Reader) (*SignatureFile, error) {
+	var first [1]byte
+	if _, err := io.ReadFull(r, first[:]); err != nil {
+		return nil, fmt.Errorf("failed to read signature file version: %w", err)
+	}
+
+	switch first[0] {
+	case typeFileHash:
+		return readSignatureFileV2(r)
+	case SignatureFileVersion5, SignatureFileVersion6:
+		return readSignatureFileV5(r, int(first[0]))
+	default:
+		return nil, fmt.Errorf("unsupported signature file version %d", first[0])
+	}
+}
+
+func readSignatureFileV2(r io.Reader) (*SignatureFile, error) {
+	sf := &SignatureFile{Version: SignatureFileVersion2, FileHash: make([]byte, HashLength)}
+	if _, err := io.ReadFull(r, sf.FileHash); err != nil {
+		return nil, fmt.Errorf("failed to read file hash: %w", err)
+	}
+
+	var marker [1]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil {
+		return nil, fmt.Errorf("failed to read file signature: %w", err)
+	}
+	if marker[0] != typeSignature {
+		return nil, fmt.Errorf("unexpected signature marker %d", marker[0])
+	}
+
+	length, err := readInt32(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file signature: %w", err)
+	}
+	if sf.FileSignature, err = readBytes(r, length); err != nil {
+		return nil, fmt.Errorf("failed to read file signature: %w", err)
+	}
+
+	return sf, nil
+}
+
+func readSignatureFileV5(r io.Reader, version int) (*SignatureFile, error) {
+	streamVersion, err := readInt32(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object stream version: %w", err)
+	}
+	if streamVersion != objectStreamVersion {
+		return nil, fmt.Errorf("unsupported object stream version %d", streamVersion)
+	}
+
+	sf := &SignatureFile{Version: version}
+	if sf.FileHash, err = ReadHashObject(r); err != nil {
+		return nil, fmt.Errorf("failed to read file hash: %w", err)
+	}
+	if sf.FileSignature, err = readSignatureObject(r); err != nil {
+		return nil, fmt.Errorf("failed to read file signature: %w", err)
+	}
+	if sf.MetadataHash, err = ReadHashObject(r); err != nil {
+		return nil, fmt.Errorf("failed to read metadata hash: %w", err)
+	}
+	if sf.MetadataSignature, err = readSignatureObject(r); err != nil {
+		return nil, fmt.Errorf("failed to read metadata signature: %w", err)
+	}
+
+	return sf, nil
+}
+
+// readSignatureObject reads a serialized signature object: class ID, class version, signature type, length, checksum
+// and signature.
+func readSignatureObject(r io.Reader) ([]byte, error) {
+	var fields struct {
+		ClassId       uint64
+		ClassVersion  int32
+		SignatureType int32
+		Length        int32
+		Checksum      int32
+	}
+	if err := binary.Read(r, binary.BigEndian, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields.ClassId != signatureObjectClassId {
+		return nil, fmt.Errorf("unexpected signature class ID %#x", fields.ClassId)
+	}
+	if fields.ClassVersion != signatureObjectClassVersion {
+		return nil, fmt.Errorf("unsupported signature class version %d", fields.ClassVersion)
+	}
+	if fields.SignatureType != signatureTypeRSA {
+		return nil, fmt.Errorf("unsupported signature type %d", fields.SignatureType)
+	}
+	if fields.Checksum != 101-fields.Length {
+		return nil, fmt.Errorf("invalid signature checksum %d for length %d", fields.Checksum, fields.Length)
+	}
+
+	return readBytes(r, fields.Length)
+}
+
+// readBytes reads a length-prefixed byte array, rejecting lengths that cannot be a signature.
+func readBytes(r io.Reader, length int32) ([]byte, error) {
+	if length <= 0 || length > 1024 {
+		return nil, fmt.Errorf("invalid length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
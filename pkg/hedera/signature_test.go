@@ -0,0 +1,74 @@
+package hedera
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"testing"
+)
+
+func TestReadSignatureFile(t *testing.T) {
+	fileSig := bytes.Repeat([]byte{0xaa}, 384)
+	metaSig := bytes.Repeat([]byte{0xbb}, 384)
+
+	v5 := hederatest.SignatureFile(SignatureFileVersion5, hederatest.Hash(1), fileSig, hederatest.Hash(2), metaSig)
+	v6 := hederatest.SignatureFile(SignatureFileVersion6, hederatest.Hash(1), fileSig, hederatest.Hash(2), metaSig)
+
+	v2 := append([]byte{typeFileHash}, hederatest.Hash(3)...)
+	v2 = hederatest.AppendInt32(append(v2, typeSignature), int32(len(fileSig)))
+	v2 = append(v2, fileSig...)
+
+	badChecksum := hederatest.AppendInt32([]byte{SignatureFileVersion5}, objectStreamVersion)
+	badChecksum = hederatest.AppendHashObject(badChecksum, hederatest.Hash(1))
+	badChecksum = binary.BigEndian.AppendUint64(badChecksum, signatureObjectClassId)
+	badChecksum = hederatest.AppendInt32(badChecksum, signatureObjectClassVersion, signatureTypeRSA, int32(len(fileSig)), 0)
+	badChecksum = append(badChecksum, fileSig...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *SignatureFile
+		errMsg   string
+	}{
+		{
+			name: "version 2",
+			data: v2,
+			expected: &SignatureFile{
+				Version: SignatureFileVersion2, FileHash: hederatest.Hash(3), FileSignature: fileSig,
+			},
+		},
+		{
+			name: "version 5",
+			data: v5,
+			expected: &SignatureFile{
+				Version: SignatureFileVersion5, FileHash: hederatest.Hash(1), FileSignature: fileSig,
+				MetadataHash: hederatest.Hash(2), MetadataSignature: metaSig,
+			},
+		},
+		{
+			name: "version 6",
+			data: v6,
+			expected: &SignatureFile{
+				Version: SignatureFileVersion6, FileHash: hederatest.Hash(1), FileSignature: fileSig,
+				MetadataHash: hederatest.Hash(2), MetadataSignature: metaSig,
+			},
+		},
+		{name: "unsupported version", data: []byte{9}, errMsg: "unsupported signature file version 9"},
+		{name: "empty", data: nil, errMsg: "failed to read signature file version"},
+		{name: "truncated", data: v5[:len(v5)-1], errMsg: "failed to read metadata signature"},
+		{name: "invalid checksum", data: badChecksum, errMsg: "invalid signature checksum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sf, err := ReadSignatureFile(bytes.NewReader(tt.data))
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sf)
+		})
+	}
+}
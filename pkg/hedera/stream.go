@@ -0,0 +1,428 @@
+package hedera
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"io"
+)
+
+// Versions of the record and event stream file formats.
+const (
+	RecordStreamVersion2 = 2
+	RecordStreamVersion5 = 5
+	RecordStreamVersion6 = 6
+	EventStreamVersion5  = 5
+)
+
+// HashLength is the length of the SHA-384 running hashes of stream files.
+const HashLength = 48
+
+// recordStreamV2HeaderLength is the length of the header of version 2 record files: version, HAPI version, previous
+// file hash marker and previous file hash.
+const recordStreamV2HeaderLength = 4 + 4 + 1 + HashLength
+
+// hashObjectLength is the length of a serialized hash object: class ID, class version, digest type, length and hash.
+const hashObjectLength = 8 + 4 + 4 + 4 + HashLength
+
+const (
+	hashObjectClassId      = 0xf422da83a251741e // class ID of a serialized SHA-384 hash object
+	hashObjectClassVersion = 1
+	digestTypeSHA384       = 0x58ff811b
+	objectStreamVersion    = 1
+	prevFileHashMarker     = 1 // type byte before the previous file hash in version 2 record files
+
+	// field numbers of the RecordStreamFile, SemanticVersion and HashObject protobuf messages
+	fieldHapiProtoVersion       = 1
+	fieldStartObjectRunningHash = 2
+	fieldEndObjectRunningHash   = 4
+	fieldVersionMajor           = 1
+	fieldVersionMinor           = 2
+	fieldVersionPatch           = 3
+	fieldHashAlgorithm          = 1
+	fieldHashLength             = 2
+	fieldHash                   = 3
+	hashAlgorithmSHA384         = 1
+)
+
+// SemanticVersion is the HAPI version of the node that wrote a record stream file.
+type SemanticVersion struct {
+	Major int32
+	Minor int32
+	Patch int32
+}
+
+// String returns the version as major.minor.patch.
+func (v SemanticVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// StreamHeader is the header of a record or event stream file.
+//
+// Fields:
+//   - Version: The version of the file format.
+//   - HapiVersion: The HAPI version of the node that wrote the file; only the major version is set for version 2
+//     record files, and it is not set for event files.
+//   - StartRunningHash: The running hash of the previous file; the previous file hash for version 2 record files.
+type StreamHeader struct {
+	Version          int32
+	HapiVersion      SemanticVersion
+	StartRunningHash []byte
+}
+
+// ReadRecordStreamHeader reads the header of an uncompressed record stream file (.rcd) of version 2, 5 or 6.
+func ReadRecordStreamHeader(r io.Reader) (*StreamHeader, error) {
+	version, err := readInt32(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record file version: %w", err)
+	}
+
+	header := &StreamHeader{Version: version}
+	switch version {
+	case RecordStreamVersion2:
+		if header.HapiVersion.Major, err = readInt32(r); err != nil {
+			return nil, fmt.Errorf("failed to read HAPI version: %w", err)
+		}
+
+		var marker [1]byte
+		if _, err = io.ReadFull(r, marker[:]); err != nil {
+			return nil, fmt.Errorf("failed to read previous file hash: %w", err)
+		}
+		if marker[0] != prevFileHashMarker {
+			return nil, fmt.Errorf("unexpected previous file hash marker %d", marker[0])
+		}
+
+		header.StartRunningHash = make([]byte, HashLength)
+		if _, err = io.ReadFull(r, header.StartRunningHash); err != nil {
+			return nil, fmt.Errorf("failed to read previous file hash: %w", err)
+		}
+	case RecordStreamVersion5:
+		for _, v := range []*int32{&header.HapiVersion.Major, &header.HapiVersion.Minor, &header.HapiVersion.Patch} {
+			if *v, err = readInt32(r); err != nil {
+				return nil, fmt.Errorf("failed to read HAPI version: %w", err)
+			}
+		}
+
+		if header.StartRunningHash, err = readObjectStreamStart(r); err != nil {
+			return nil, err
+		}
+	case RecordStreamVersion6:
+		if err = readRecordStreamFile(r, header); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record file version %d", version)
+	}
+
+	return header, nil
+}
+
+// ReadEventStreamHeader reads the header of an uncompressed event stream file (.evts) of version 5.
+func ReadEventStreamHeader(r io.Reader) (*StreamHeader, error) {
+	version, err := readInt32(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event file version: %w", err)
+	}
+
+	if version != EventStreamVersion5 {
+		return nil, fmt.Errorf("unsupported event file version %d", version)
+	}
+
+	header := &StreamHeader{Version: version}
+	if header.StartRunningHash, err = readObjectStreamStart(r); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// ReadRecordStreamRunningHashes reads the header and the end running hash of an uncompressed record stream file of
+// version 2, 5 or 6. Version 2 files have no running hash; their end hash is the file hash (see RecordFileHashV2),
+// which is the previous file hash of the next file.
+func ReadRecordStreamRunningHashes(r io.Reader) (*StreamHeader, []byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read record file: %w", err)
+	}
+
+	br := bytes.NewReader(data)
+	header, err := ReadRecordStreamHeader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var end []byte
+	switch header.Version {
+	case RecordStreamVersion2:
+		end, err = RecordFileHashV2(data)
+	case RecordStreamVersion5:
+		end, err = readEndHashObject(data, len(data)-br.Len())
+	case RecordStreamVersion6:
+		err = walkFields(data[4:], func(num protowire.Number, typ protowire.Type, value []byte) error {
+			if num != fieldEndObjectRunningHash || typ != protowire.BytesType {
+				return nil
+			}
+
+			hash, err := readProtoHashObject(value)
+			if err != nil {
+				return fmt.Errorf("invalid end running hash: %w", err)
+			}
+			end = hash
+			return nil
+		})
+		if err == nil && end == nil {
+			err = errors.New("record stream file has no end running hash")
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, end, nil
+}
+
+// RecordFileHashV2 returns the file hash of an uncompressed record stream file of version 2, as computed by the nodes
+// and the mirror node: the SHA-384 hash of the header followed by the SHA-384 hash of the rest of the file. It is the
+// hash signed by the nodes and the previous file hash of the next file.
+func RecordFileHashV2(data []byte) ([]byte, error) {
+	if len(data) < recordStreamV2HeaderLength {
+		return nil, errors.New("record file is shorter than a version 2 header")
+	}
+	if version := int32(binary.BigEndian.Uint32(data)); version != RecordStreamVersion2 {
+		return nil, fmt.Errorf("unexpected record file version %d, expected %d", version, RecordStreamVersion2)
+	}
+
+	body := sha512.Sum384(data[recordStreamV2HeaderLength:])
+	h := sha512.New384()
+	h.Write(data[:recordStreamV2HeaderLength])
+	h.Write(body[:])
+	return h.Sum(nil), nil
+}
+
+// RecordFileHash returns the file hash of an uncompressed record stream file, which is the hash signed by the nodes:
+// the file hash of version 2 files (see RecordFileHashV2), or the SHA-384 hash of the whole file for later versions.
+func RecordFileHash(data []byte) ([]byte, error) {
+	if len(data) >= 4 && int32(binary.BigEndian.Uint32(data)) == RecordStreamVersion2 {
+		return RecordFileHashV2(data)
+	}
+
+	sum := sha512.Sum384(data)
+	return sum[:], nil
+}
+
+// ReadEventStreamRunningHashes reads the header and the end running hash of an uncompressed event stream file of
+// version 5.
+func ReadEventStreamRunningHashes(r io.Reader) (*StreamHeader, []byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read event file: %w", err)
+	}
+
+	br := bytes.NewReader(data)
+	header, err := ReadEventStreamHeader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end, err := readEndHashObject(data, len(data)-br.Len())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, end, nil
+}
+
+// readEndHashObject reads the end running hash serialized at the end of a version 5 stream file, after the header of
+// the given length.
+func readEndHashObject(data []byte, headerLength int) ([]byte, error) {
+	if len(data)-headerLength < hashObjectLength {
+		return nil, errors.New("stream file has no end running hash")
+	}
+
+	hash, err := ReadHashObject(bytes.NewReader(data[len(data)-hashObjectLength:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read end running hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// readObjectStreamStart reads the object stream version and the serialized start running hash of version 5 files.
+func readObjectStreamStart(r io.Reader) ([]byte, error) {
+	version, err := readInt32(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object stream version: %w", err)
+	}
+	if version != objectStreamVersion {
+		return nil, fmt.Errorf("unsupported object stream version %d", version)
+	}
+
+	hash, err := ReadHashObject(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read start running hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// ReadHashObject reads a serialized SHA-384 hash object as found in version 5 stream files: class ID, class version,
+// digest type, length and hash.
+func ReadHashObject(r io.Reader) ([]byte, error) {
+	var fields struct {
+		ClassId      uint64
+		ClassVersion int32
+		DigestType   int32
+		Length       int32
+	}
+	if err := binary.Read(r, binary.BigEndian, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields.ClassId != hashObjectClassId {
+		return nil, fmt.Errorf("unexpected hash class ID %#x", fields.ClassId)
+	}
+	if fields.ClassVersion != hashObjectClassVersion {
+		return nil, fmt.Errorf("unsupported hash class version %d", fields.ClassVersion)
+	}
+	if fields.DigestType != digestTypeSHA384 {
+		return nil, fmt.Errorf("unsupported hash digest type %#x", fields.DigestType)
+	}
+	if fields.Length != HashLength {
+		return nil, fmt.Errorf("unexpected hash length %d", fields.Length)
+	}
+
+	hash := make([]byte, HashLength)
+	if _, err := io.ReadFull(r, hash); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+// readRecordStreamFile reads the HAPI version and start running hash of the RecordStreamFile protobuf message of
+// version 6 record files.
+func readRecordStreamFile(r io.Reader, header *StreamHeader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read record stream file: %w", err)
+	}
+
+	var hasVersion bool
+	err = walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case num == fieldHapiProtoVersion && typ == protowire.BytesType:
+			hasVersion = true
+			return readSemanticVersion(value, &header.HapiVersion)
+		case num == fieldStartObjectRunningHash && typ == protowire.BytesType:
+			hash, err := readProtoHashObject(value)
+			if err != nil {
+				return fmt.Errorf("invalid start running hash: %w", err)
+			}
+			header.StartRunningHash = hash
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !hasVersion {
+		return errors.New("record stream file has no HAPI version")
+	}
+	if header.StartRunningHash == nil {
+		return errors.New("record stream file has no start running hash")
+	}
+
+	return nil
+}
+
+func readSemanticVersion(data []byte, v *SemanticVersion) error {
+	return walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+
+		n, _ := protowire.ConsumeVarint(value)
+		switch num {
+		case fieldVersionMajor:
+			v.Major = int32(n)
+		case fieldVersionMinor:
+			v.Minor = int32(n)
+		case fieldVersionPatch:
+			v.Patch = int32(n)
+		}
+		return nil
+	})
+}
+
+func readProtoHashObject(data []byte) ([]byte, error) {
+	var algorithm, length uint64
+	var hash []byte
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case num == fieldHashAlgorithm && typ == protowire.VarintType:
+			algorithm, _ = protowire.ConsumeVarint(value)
+		case num == fieldHashLength && typ == protowire.VarintType:
+			length, _ = protowire.ConsumeVarint(value)
+		case num == fieldHash && typ == protowire.BytesType:
+			hash = bytes.Clone(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm != hashAlgorithmSHA384 {
+		return nil, fmt.Errorf("unsupported hash algorithm %d", algorithm)
+	}
+	if length != HashLength || len(hash) != HashLength {
+		return nil, fmt.Errorf("unexpected hash length %d", len(hash))
+	}
+
+	return hash, nil
+}
+
+// walkFields calls fn with the number, type and value of each field of a protobuf message. The value of varint fields
+// is the encoded varint, and the value of length-delimited fields is their content.
+func walkFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf field tag: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return fmt.Errorf("invalid protobuf field %d: %w", num, protowire.ParseError(m))
+			}
+			value, n = v, m
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return fmt.Errorf("invalid protobuf field %d: %w", num, protowire.ParseError(n))
+			}
+			value = data[:n]
+		}
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+
+	return nil
+}
+
+func readInt32(r io.Reader) (int32, error) {
+	var v int32
+	err := binary.Read(r, binary.BigEndian, &v)
+	return v, err
+}
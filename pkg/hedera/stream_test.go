@@ -0,0 +1,183 @@
+package hedera
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"github.com/stretchr/testify/require"
+	"golang.hedera.com/solo-cheetah/pkg/hedera/hederatest"
+	"google.golang.org/protobuf/encoding/protowire"
+	"testing"
+)
+
+func recordFileV6(version []byte, hash []byte) []byte {
+	buf := hederatest.AppendInt32(nil, RecordStreamVersion6)
+	if version != nil {
+		buf = protowire.AppendTag(buf, fieldHapiProtoVersion, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, version)
+	}
+	if hash != nil {
+		var h []byte
+		h = protowire.AppendTag(h, fieldHashAlgorithm, protowire.VarintType)
+		h = protowire.AppendVarint(h, hashAlgorithmSHA384)
+		h = protowire.AppendTag(h, fieldHashLength, protowire.VarintType)
+		h = protowire.AppendVarint(h, uint64(len(hash)))
+		h = protowire.AppendTag(h, fieldHash, protowire.BytesType)
+		h = protowire.AppendBytes(h, hash)
+		buf = protowire.AppendTag(buf, fieldStartObjectRunningHash, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, h)
+	}
+	// record stream items are skipped
+	buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+	return protowire.AppendBytes(buf, []byte("item"))
+}
+
+func semanticVersion(major, minor, patch uint64) []byte {
+	var v []byte
+	v = protowire.AppendTag(v, fieldVersionMajor, protowire.VarintType)
+	v = protowire.AppendVarint(v, major)
+	v = protowire.AppendTag(v, fieldVersionMinor, protowire.VarintType)
+	v = protowire.AppendVarint(v, minor)
+	v = protowire.AppendTag(v, fieldVersionPatch, protowire.VarintType)
+	return protowire.AppendVarint(v, patch)
+}
+
+func TestReadRecordStreamHeader(t *testing.T) {
+	v5 := hederatest.RecordFileV5(hederatest.Hash(5), nil)
+	v2 := append(append(hederatest.AppendInt32(nil, RecordStreamVersion2, 3), prevFileHashMarker), hederatest.Hash(2)...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *StreamHeader
+		errMsg   string
+	}{
+		{name: "version 2", data: v2, expected: &StreamHeader{Version: 2, HapiVersion: SemanticVersion{Major: 3}, StartRunningHash: hederatest.Hash(2)}},
+		{name: "version 5", data: v5, expected: &StreamHeader{Version: 5, HapiVersion: SemanticVersion{Minor: 27, Patch: 1}, StartRunningHash: hederatest.Hash(5)}},
+		{
+			name:     "version 6",
+			data:     recordFileV6(semanticVersion(0, 47, 2), hederatest.Hash(6)),
+			expected: &StreamHeader{Version: 6, HapiVersion: SemanticVersion{Minor: 47, Patch: 2}, StartRunningHash: hederatest.Hash(6)},
+		},
+		{name: "empty", data: nil, errMsg: "failed to read record file version"},
+		{name: "unsupported version", data: hederatest.AppendInt32(nil, 7), errMsg: "unsupported record file version 7"},
+		{name: "truncated version 5", data: v5[:len(v5)-10], errMsg: "failed to read start running hash"},
+		{name: "truncated version 2", data: v2[:20], errMsg: "failed to read previous file hash"},
+		{name: "invalid version 5 hash", data: hederatest.AppendHashObject(hederatest.AppendInt32(nil, RecordStreamVersion5, 0, 27, 1, objectStreamVersion), hederatest.Hash(5)[:32]), errMsg: "unexpected hash length 32"},
+		{name: "version 6 without hash", data: recordFileV6(semanticVersion(0, 47, 2), nil), errMsg: "no start running hash"},
+		{name: "version 6 without version", data: recordFileV6(nil, hederatest.Hash(6)), errMsg: "no HAPI version"},
+		{name: "truncated version 6", data: recordFileV6(semanticVersion(0, 47, 2), hederatest.Hash(6))[:30], errMsg: "invalid protobuf field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := ReadRecordStreamHeader(bytes.NewReader(tt.data))
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, header)
+		})
+	}
+}
+
+func TestReadEventStreamHeader(t *testing.T) {
+	data := hederatest.AppendHashObject(hederatest.AppendInt32(nil, EventStreamVersion5, objectStreamVersion), hederatest.Hash(1))
+	header, err := ReadEventStreamHeader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, &StreamHeader{Version: 5, StartRunningHash: hederatest.Hash(1)}, header)
+
+	_, err = ReadEventStreamHeader(bytes.NewReader(hederatest.AppendInt32(nil, RecordStreamVersion6)))
+	require.ErrorContains(t, err, "unsupported event file version 6")
+
+	_, err = ReadEventStreamHeader(bytes.NewReader(hederatest.AppendInt32(nil, EventStreamVersion5, 2)))
+	require.ErrorContains(t, err, "unsupported object stream version 2")
+}
+
+func TestReadRecordStreamRunningHashes(t *testing.T) {
+	v5 := hederatest.RecordFileV5(hederatest.Hash(5), hederatest.Hash(55))
+
+	v6 := recordFileV6(semanticVersion(0, 47, 2), hederatest.Hash(6))
+	var h []byte
+	h = protowire.AppendTag(h, fieldHashAlgorithm, protowire.VarintType)
+	h = protowire.AppendVarint(h, hashAlgorithmSHA384)
+	h = protowire.AppendTag(h, fieldHashLength, protowire.VarintType)
+	h = protowire.AppendVarint(h, HashLength)
+	h = protowire.AppendTag(h, fieldHash, protowire.BytesType)
+	h = protowire.AppendBytes(h, hederatest.Hash(66))
+	v6 = protowire.AppendTag(v6, fieldEndObjectRunningHash, protowire.BytesType)
+	v6 = protowire.AppendBytes(v6, h)
+
+	v2 := append(append(hederatest.AppendInt32(nil, RecordStreamVersion2, 3), prevFileHashMarker), hederatest.Hash(2)...)
+	body := sha512.Sum384([]byte("transactions"))
+	v2Hash := sha512.Sum384(append(append([]byte(nil), v2...), body[:]...))
+	v2 = append(v2, []byte("transactions")...)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		start  []byte
+		end    []byte
+		errMsg string
+	}{
+		{name: "version 2", data: v2, start: hederatest.Hash(2), end: v2Hash[:]},
+		{name: "version 5", data: v5, start: hederatest.Hash(5), end: hederatest.Hash(55)},
+		{name: "version 6", data: v6, start: hederatest.Hash(6), end: hederatest.Hash(66)},
+		{name: "version 5 without end hash", data: v5[:88], errMsg: "no end running hash"},
+		{name: "truncated version 5", data: v5[:len(v5)-1], errMsg: "failed to read end running hash"},
+		{name: "version 6 without end hash", data: recordFileV6(semanticVersion(0, 47, 2), hederatest.Hash(6)), errMsg: "no end running hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, end, err := ReadRecordStreamRunningHashes(bytes.NewReader(tt.data))
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.start, header.StartRunningHash)
+			require.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestReadEventStreamRunningHashes(t *testing.T) {
+	data := hederatest.AppendHashObject(hederatest.AppendInt32(nil, EventStreamVersion5, objectStreamVersion), hederatest.Hash(1))
+	data = hederatest.AppendHashObject(append(data, []byte("events")...), hederatest.Hash(2))
+
+	header, end, err := ReadEventStreamRunningHashes(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, hederatest.Hash(1), header.StartRunningHash)
+	require.Equal(t, hederatest.Hash(2), end)
+}
+
+func TestRecordFileHashV2(t *testing.T) {
+	header := append(append(hederatest.AppendInt32(nil, RecordStreamVersion2, 3), prevFileHashMarker), hederatest.Hash(2)...)
+	data := append(append([]byte(nil), header...), []byte("transactions")...)
+
+	// the header is hashed with the hash of the body rather than with the body itself
+	hash, err := RecordFileHashV2(data)
+	require.NoError(t, err)
+	body := sha512.Sum384([]byte("transactions"))
+	expected := sha512.Sum384(append(append([]byte(nil), header...), body[:]...))
+	require.Equal(t, expected[:], hash)
+	whole := sha512.Sum384(data)
+	require.NotEqual(t, whole[:], hash)
+
+	_, err = RecordFileHashV2(header[:20])
+	require.ErrorContains(t, err, "shorter than a version 2 header")
+
+	v5 := append(hederatest.AppendInt32(nil, RecordStreamVersion5), header[4:]...)
+	_, err = RecordFileHashV2(v5)
+	require.ErrorContains(t, err, "unexpected record file version 5")
+
+	// later versions are hashed whole
+	hash, err = RecordFileHash(data)
+	require.NoError(t, err)
+	require.Equal(t, expected[:], hash)
+	hash, err = RecordFileHash(v5)
+	require.NoError(t, err)
+	v5Hash := sha512.Sum384(v5)
+	require.Equal(t, v5Hash[:], hash)
+}
@@ -0,0 +1,41 @@
+package hedera
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// consensusTimestampRegex matches the consensus timestamp that Hedera stream file names start with, e.g.
+// 2025-05-20T10_15_30.123456789Z.rcd.gz or 2025-05-20T10_15_30.123456789Z_01.rcd.gz for sidecar files.
+// Colons are replaced by underscores in file names, and older files have microseconds instead of nanoseconds.
+var consensusTimestampRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}_\d{2}_\d{2}(\.\d{1,9})?Z)`)
+
+// sidecarRegex matches the names of sidecar record files, which are numbered after the consensus timestamp of their
+// record file, e.g. 2025-05-20T10_15_30.123456789Z_01.rcd.gz.
+var sidecarRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}_\d{2}_\d{2}(\.\d{1,9})?Z_\d{2}\.rcd`)
+
+// IsSidecarFile returns true if the file name is the name of a sidecar record file. The directory of the path is ignored.
+func IsSidecarFile(path string) bool {
+	return sidecarRegex.MatchString(filepath.Base(path))
+}
+
+// ParseConsensusTimestamp parses the consensus timestamp from the name of a record, event or sidecar stream file.
+// The directory of the path is ignored. It returns an error if the file name does not start with a timestamp, such as
+// block stream files which are named after the block number.
+func ParseConsensusTimestamp(path string) (time.Time, error) {
+	name := filepath.Base(path)
+	match := consensusTimestampRegex.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("file name %s does not start with a consensus timestamp", name)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, strings.ReplaceAll(match[1], "_", ":"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid consensus timestamp in file name %s: %w", name, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,45 @@
+package hedera
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseConsensusTimestamp(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"2025-05-20T10_15_30.123456789Z.rcd.gz", time.Date(2025, 5, 20, 10, 15, 30, 123456789, time.UTC), false},
+		{"/data/recordStreams/record0.0.3/2025-05-20T10_15_30.123456789Z.rcd_sig", time.Date(2025, 5, 20, 10, 15, 30, 123456789, time.UTC), false},
+		{"sidecar/2025-05-20T10_15_30.000000002Z_01.rcd.gz", time.Date(2025, 5, 20, 10, 15, 30, 2, time.UTC), false},
+		{"2019-09-13T21_53_51.396440Z.rcd", time.Date(2019, 9, 13, 21, 53, 51, 396440000, time.UTC), false},
+		{"2025-05-20T10_15_30Z.evts_sig", time.Date(2025, 5, 20, 10, 15, 30, 0, time.UTC), false},
+		{"000000000000000000000000000000000001.blk.gz", time.Time{}, true},
+		{"2025-13-20T10_15_30.123Z.rcd", time.Time{}, true},
+		{"test.rcd", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseConsensusTimestamp(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "expected %s, got %s", tt.expected, got)
+		})
+	}
+}
+
+func TestIsSidecarFile(t *testing.T) {
+	assert.True(t, IsSidecarFile("/streams/sidecar/2025-05-20T10_15_30.123456789Z_01.rcd.gz"))
+	assert.True(t, IsSidecarFile("2025-05-20T10_15_30.123456Z_12.rcd"))
+	assert.False(t, IsSidecarFile("/streams/2025-05-20T10_15_30.123456789Z.rcd.gz"))
+	assert.False(t, IsSidecarFile("/streams/2025-05-20T10_15_30.123456789Z.rcd_sig"))
+	assert.False(t, IsSidecarFile("/streams/sidecar_01.rcd.gz"))
+}
@@ -0,0 +1,144 @@
+package tmplx
+
+import (
+	"bytes"
+	"fmt"
+	"golang.hedera.com/solo-cheetah/pkg/hedera"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Variables of the file template context.
+const (
+	VarParentDir = "parentDir" // name of the parent directory of the file, e.g. record0.0.3
+	VarNodeId    = "nodeId"    // node account ID parsed from the path, e.g. 0.0.3; empty if there is none
+	VarNodeNum   = "nodeNum"   // account number of the node account ID, e.g. 3; empty if there is none
+	VarTimestamp = "timestamp" // consensus timestamp of the file name as time.Time; zero if there is none
+	VarYear      = "year"      // year of the consensus timestamp, e.g. 2024; empty if there is none
+	VarMonth     = "month"     // two-digit month of the consensus timestamp, e.g. 01
+	VarDay       = "day"       // two-digit day of the consensus timestamp, e.g. 01
+	VarHour      = "hour"      // two-digit hour of the consensus timestamp, e.g. 00
+	VarMinute    = "minute"    // two-digit minute of the consensus timestamp, e.g. 00
+	VarSecond    = "second"    // two-digit second of the consensus timestamp, e.g. 02
+	VarNanos     = "nanos"     // nine-digit nanoseconds of the consensus timestamp, e.g. 000000000
+)
+
+// Variables of the marker template context, in addition to the file variables.
+const (
+	VarMarkerName = "markerName" // name of the marker without the extension, e.g. 2024-01-01T00_00_00.000000000Z
+	VarMarkerExt  = "markerExt"  // extension of the marker, e.g. .rcd_sig
+	VarMarkerFile = "markerFile" // file name of the marker, e.g. 2024-01-01T00_00_00.000000000Z.rcd_sig
+	VarRelDir     = "relDir"     // directory of the marker relative to the root directory, e.g. record0.0.3
+)
+
+// FileVars returns the template variables derived from the path of a file: its parent directory, the node account ID
+// of its directory and the consensus timestamp of the file name.
+func FileVars(path string) map[string]any {
+	vars := map[string]any{
+		VarParentDir: filepath.Base(filepath.Dir(path)),
+		VarNodeId:    "",
+		VarNodeNum:   "",
+		VarTimestamp: time.Time{},
+		VarYear:      "",
+		VarMonth:     "",
+		VarDay:       "",
+		VarHour:      "",
+		VarMinute:    "",
+		VarSecond:    "",
+		VarNanos:     "",
+	}
+
+	if node := hedera.ParseNodeAccountId(filepath.Dir(path)); node != "" {
+		vars[VarNodeId] = node
+		vars[VarNodeNum] = node[strings.LastIndex(node, ".")+1:]
+	}
+
+	if ts, err := hedera.ParseConsensusTimestamp(path); err == nil {
+		ts = ts.UTC()
+		vars[VarTimestamp] = ts
+		vars[VarYear] = fmt.Sprintf("%04d", ts.Year())
+		vars[VarMonth] = fmt.Sprintf("%02d", int(ts.Month()))
+		vars[VarDay] = fmt.Sprintf("%02d", ts.Day())
+		vars[VarHour] = fmt.Sprintf("%02d", ts.Hour())
+		vars[VarMinute] = fmt.Sprintf("%02d", ts.Minute())
+		vars[VarSecond] = fmt.Sprintf("%02d", ts.Second())
+		vars[VarNanos] = fmt.Sprintf("%09d", ts.Nanosecond())
+	}
+
+	return vars
+}
+
+// MarkerVars returns the template variables of a marker file: the file variables of the marker, its name, extension
+// and its directory relative to the root directory. The marker extension, e.g. .rcd.gz, is stripped from the name when
+// the marker ends with it, otherwise the last extension of the file is. The relative directory is "." if root is empty
+// or not a parent of the marker.
+func MarkerVars(marker string, ext string, root string) map[string]any {
+	vars := FileVars(marker)
+
+	file := filepath.Base(marker)
+	if ext == "" || !strings.HasSuffix(file, ext) || file == ext {
+		ext = filepath.Ext(file)
+	}
+	vars[VarMarkerName] = strings.TrimSuffix(file, ext)
+	vars[VarMarkerExt] = ext
+	vars[VarMarkerFile] = file
+
+	vars[VarRelDir] = "."
+	if root != "" {
+		if rel, err := filepath.Rel(root, filepath.Dir(marker)); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			vars[VarRelDir] = rel
+		}
+	}
+
+	return vars
+}
+
+// Funcs returns the helper functions available in templates.
+//
+//   - env NAME: the value of the environment variable.
+//   - lower, upper: the string in lower or upper case.
+//   - trimPrefix PREFIX S, trimSuffix SUFFIX S: the string without the prefix or suffix.
+//   - replace OLD NEW S: the string with all OLD replaced by NEW.
+//   - base PATH, dir PATH: the last element or the directory of a path.
+//   - quoteMeta S: the string with regular expression metacharacters escaped.
+//   - date LAYOUT TIME: the time formatted with the Go layout, e.g. {{date "2006/01/02" .timestamp}}.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"env":        os.Getenv,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"base":       filepath.Base,
+		"dir":        filepath.Dir,
+		"quoteMeta":  regexp.QuoteMeta,
+		"date":       func(layout string, t time.Time) string { return t.Format(layout) },
+	}
+}
+
+// Parse parses a template with the helper functions. Referencing an unknown variable is an error when the template is
+// executed.
+func Parse(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Option("missingkey=error").Parse(text)
+}
+
+// Render parses and executes a template with the variables.
+func Render(text string, vars map[string]any) (string, error) {
+	tmpl, err := Parse("template", text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template '%s': %w", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to execute template '%s': %w", text, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,104 @@
+package tmplx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestFileVars(t *testing.T) {
+	vars := FileVars("/opt/hgcapp/recordStreams/record0.0.3/2024-01-02T03_04_05.000000006Z.rcd.gz")
+
+	assert.Equal(t, "record0.0.3", vars[VarParentDir])
+	assert.Equal(t, "0.0.3", vars[VarNodeId])
+	assert.Equal(t, "3", vars[VarNodeNum])
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), vars[VarTimestamp])
+	assert.Equal(t, "2024", vars[VarYear])
+	assert.Equal(t, "01", vars[VarMonth])
+	assert.Equal(t, "02", vars[VarDay])
+	assert.Equal(t, "03", vars[VarHour])
+	assert.Equal(t, "04", vars[VarMinute])
+	assert.Equal(t, "05", vars[VarSecond])
+	assert.Equal(t, "000000006", vars[VarNanos])
+}
+
+func TestFileVarsWithoutNodeAndTimestamp(t *testing.T) {
+	vars := FileVars("/data/blocks/000000000000000000000000000000000001.blk.gz")
+
+	assert.Equal(t, "blocks", vars[VarParentDir])
+	assert.Equal(t, "", vars[VarNodeId])
+	assert.Equal(t, "", vars[VarNodeNum])
+	assert.Equal(t, time.Time{}, vars[VarTimestamp])
+	assert.Equal(t, "", vars[VarYear])
+	assert.Equal(t, "", vars[VarNanos])
+}
+
+func TestMarkerVars(t *testing.T) {
+	tests := []struct {
+		name           string
+		marker         string
+		ext            string
+		root           string
+		expectedName   string
+		expectedExt    string
+		expectedRelDir string
+	}{
+		{"nested", "/streams/record0.0.3/2024-01-01T00_00_00.000000000Z.rcd_sig", "", "/streams", "2024-01-01T00_00_00.000000000Z", ".rcd_sig", "record0.0.3"},
+		{"root", "/streams/record0.0.3/2024-01-01T00_00_00.000000000Z.rcd_sig", ".rcd_sig", "/streams/record0.0.3", "2024-01-01T00_00_00.000000000Z", ".rcd_sig", "."},
+		{"no root", "/streams/record0.0.3/block.mf", "", "", "block", ".mf", "."},
+		{"marker extension", "/streams/record0.0.3/2024-01-01T00_00_00.000000000Z.rcd.gz", ".rcd.gz", "/streams", "2024-01-01T00_00_00.000000000Z", ".rcd.gz", "record0.0.3"},
+		{"other extension", "/streams/record0.0.3/block.tar.gz", ".rcd.gz", "", "block.tar", ".gz", "."},
+		{"outside root", "/other/record0.0.3/block.mf", "", "/streams", "block", ".mf", "."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := MarkerVars(tt.marker, tt.ext, tt.root)
+			assert.Equal(t, tt.expectedName, vars[VarMarkerName])
+			assert.Equal(t, tt.expectedExt, vars[VarMarkerExt])
+			assert.Equal(t, tt.expectedName+tt.expectedExt, vars[VarMarkerFile])
+			assert.Equal(t, tt.expectedRelDir, vars[VarRelDir])
+			assert.Equal(t, "0.0.3", vars[VarNodeId])
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Setenv("CHEETAH_TMPLX_TEST", "mainnet")
+	vars := MarkerVars("/streams/record0.0.3/2024-01-02T03_04_05.000000006Z.rcd_sig", "", "/streams")
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{{.markerName}}.rcd.gz", "2024-01-02T03_04_05.000000006Z.rcd.gz"},
+		{"node{{.nodeNum}}/{{.year}}/{{.month}}/{{.day}}", "node3/2024/01/02"},
+		{`{{env "CHEETAH_TMPLX_TEST"}}/{{.relDir}}`, "mainnet/record0.0.3"},
+		{"{{upper .markerExt}}", ".RCD_SIG"},
+		{`{{trimSuffix "_sig" .markerExt}}`, ".rcd"},
+		{`{{trimPrefix "record" .parentDir}}`, "0.0.3"},
+		{`{{replace "." "_" .nodeId}}`, "0_0_3"},
+		{`{{quoteMeta .markerExt}}`, `\.rcd_sig`},
+		{`{{date "2006/01/02/15" .timestamp}}`, "2024/01/02/03"},
+		{`{{base "a/b/c.gz"}} {{dir "a/b/c.gz"}}`, "c.gz a/b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Render(tt.template, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	vars := MarkerVars("/streams/record0.0.3/block.mf", "", "")
+
+	_, err := Render("{{.markerName", vars)
+	assert.ErrorContains(t, err, "failed to parse template")
+
+	_, err = Render("{{.unknown}}", vars)
+	assert.ErrorContains(t, err, "failed to execute template")
+}